@@ -0,0 +1,25 @@
+package cmdfx
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/fx"
+)
+
+// Exec runs a one-shot application provided fx options, e.g. a CLI command.
+//
+// Unlike [Run], it doesn't wait for any signal. The application is stopped right after it is started,
+// so the work should be done within fx.Invoke.
+func Exec(ctx context.Context, options ...fx.Option) error {
+	app, err := runContainer(ctx, false, options...)
+	if err != nil {
+		return fmt.Errorf("exec application: %w", err)
+	}
+
+	if err := stopContainer(ctx, app); err != nil {
+		return fmt.Errorf("exec application: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/raf555/kbbi-api/internal/dictionary"
+	"github.com/raf555/kbbi-api/internal/encoding"
+	"github.com/raf555/kbbi-api/internal/plaintext"
+	salomeconfig "github.com/raf555/salome/config/v1"
+	"go.uber.org/fx"
+)
+
+var assetCommand = cliCommand{
+	name:        "asset",
+	description: "Manage the dictionary assets",
+	subcommands: []cliCommand{
+		assetPackCommand,
+		assetInspectCommand,
+		assetDecryptCommand,
+		assetRekeyCommand,
+		assetKeygenCommand,
+		assetLintCommand,
+		assetCompileCommand,
+		{
+			name:        "stats",
+			usage:       "asset stats [flags]",
+			description: "Show the stats of the loaded dictionary assets",
+			flags: func(c *cli, fs *flag.FlagSet) {
+				c.outputFlags(fs, "text", "json")
+			},
+			run: withDictionary(func(c *cli, dict *dictionary.Dictionary, _ []string) error {
+				return c.print(&assetStats{dict.Stats()})
+			}),
+		},
+	},
+}
+
+// assetConfig is the config of the asset tooling. Unlike [dictionary.Configuration], the key is not required,
+// since it can be given from the flags.
+type assetConfig struct {
+	Key   encoding.HexString `env:"ASSETS_ENCRYPTION_KEY"`
+	KeyID string             `env:"ASSETS_ENCRYPTION_KEY_ID"`
+	// Keys are the other keys which can decrypt the assets, they are ignored if the key is given from the flags.
+	Keys dictionary.Keyring `env:"ASSETS_ENCRYPTION_KEYS"`
+	// PublicKeys verify the signature of the assets, the signature is only reported if it is not set.
+	PublicKeys dictionary.PublicKeys `env:"ASSETS_SIGNATURE_PUBLIC_KEYS"`
+	// SigningKey is the Ed25519 private key which signs the packed assets, they are not signed if it is not set.
+	SigningKey   encoding.HexString `env:"ASSETS_SIGNING_KEY"`
+	SigningKeyID string             `env:"ASSETS_SIGNING_KEY_ID"`
+	// IV is only used for the legacy assets without envelope.
+	IV        encoding.HexString `env:"ASSETS_ENCRYPTION_IV"`
+	Directory string             `env:"ASSETS_DIRECTORY, default=./assets/"`
+	// CompiledDictionary is the path of the compiled dictionary written by `asset compile`.
+	CompiledDictionary string `env:"ASSETS_COMPILED_DICTIONARY"`
+}
+
+func assetKeyFlags(fs *flag.FlagSet) {
+	fs.String("key", "", "hex encoded encryption key, overrides ASSETS_ENCRYPTION_KEY and ASSETS_ENCRYPTION_KEYS")
+	fs.String("iv", "", "hex encoded encryption IV of the legacy assets without envelope, overrides ASSETS_ENCRYPTION_IV")
+}
+
+// loadAssetConfig loads the asset config, the key and IV from [assetKeyFlags] take precedence.
+// Only the key is required, since the IV is only needed for the legacy assets.
+func (c *cli) loadAssetConfig(ctx context.Context, cmdName string) (assetConfig, error) {
+	var cfg assetConfig
+	err := c.exec(ctx, fx.Invoke(func(provider salomeconfig.Provider) (err error) {
+		cfg, err = salomeconfig.LoadConfigTo[assetConfig](provider)
+		return err
+	}))
+	if err != nil {
+		return assetConfig{}, fmt.Errorf("%s: load config: %w", cmdName, err)
+	}
+
+	for name, target := range map[string]*encoding.HexString{"key": &cfg.Key, "iv": &cfg.IV} {
+		if value := c.flags.Lookup(name).Value.String(); value != "" {
+			if err := target.UnmarshalText([]byte(value)); err != nil {
+				return assetConfig{}, usageErrorf("%s: invalid --%s: %w", cmdName, name, err)
+			}
+		}
+	}
+	if c.flags.Lookup("key").Value.String() != "" {
+		cfg.KeyID, cfg.Keys = "", nil
+	}
+
+	if len(cfg.keyring()) == 0 {
+		return assetConfig{}, usageErrorf("%s: missing encryption key, set --key or the config", cmdName)
+	}
+
+	return cfg, nil
+}
+
+// keyring is the keys which can decrypt the assets, the current key first.
+func (c assetConfig) keyring() dictionary.Keyring {
+	return dictionary.Configuration{
+		AssetsEncryptionKey:   c.Key,
+		AssetsEncryptionKeyID: c.KeyID,
+		AssetsEncryptionKeys:  c.Keys,
+	}.Keyring()
+}
+
+type assetStats struct {
+	dictionary.Stats
+}
+
+func (s *assetStats) RenderText(w io.Writer, _ plaintext.Options) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintf(tw, "edition\t%s\n", s.Edition)
+	_, _ = fmt.Fprintf(tw, "lemmas\t%d\n", s.LemmaCount)
+	_, _ = fmt.Fprintf(tw, "entries\t%d\n", s.EntryCount)
+	return tw.Flush()
+}
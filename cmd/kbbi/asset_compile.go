@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"github.com/raf555/kbbi-api/internal/dictionary"
+	"github.com/raf555/kbbi-api/internal/plaintext"
+	salomeconfig "github.com/raf555/salome/config/v1"
+	"go.uber.org/fx"
+)
+
+var assetCompileCommand = cliCommand{
+	name:  "compile",
+	usage: "asset compile --plaintext [flags]",
+	description: "Compile the loaded dictionary into the memory-mappable format, e.g. kbbi asset compile --plaintext --output dict.bin\n" +
+		"The server opens it instead of reading the dictionary asset if ASSETS_COMPILED_DICTIONARY is set, " +
+		"which skips decrypting and indexing the asset on startup. The compiled dictionary is not encrypted, " +
+		"so it must be explicitly allowed with --plaintext. Don't redistribute it.\n" +
+		"The server maps the file, so only replace it by renaming a new file over it, as this command does. " +
+		"Overwriting it in place, e.g. with cp, crashes the running server.",
+	flags: func(c *cli, fs *flag.FlagSet) {
+		c.outputFlags(fs, "text", "json")
+		fs.Bool("plaintext", false, "allow writing the unencrypted compiled dictionary")
+		fs.String("output", "", "path of the compiled dictionary (default ASSETS_COMPILED_DICTIONARY, or dict.bin in the assets directory)")
+	},
+	run: runAssetCompile,
+}
+
+func runAssetCompile(ctx context.Context, c *cli, args []string) error {
+	if c.flags.Lookup("plaintext").Value.String() != "true" {
+		c.flags.Usage()
+		return usageErrorf("asset compile: --plaintext is required to write the unencrypted dictionary")
+	}
+
+	output := c.flags.Lookup("output").Value.String()
+	if output == "" {
+		var cfg assetConfig
+		err := c.exec(ctx, fx.Invoke(func(provider salomeconfig.Provider) (err error) {
+			cfg, err = salomeconfig.LoadConfigTo[assetConfig](provider)
+			return err
+		}))
+		if err != nil {
+			return fmt.Errorf("asset compile: load config: %w", err)
+		}
+
+		output = cfg.CompiledDictionary
+		if output == "" {
+			output = filepath.Join(cfg.Directory, "dict.bin")
+		}
+	}
+
+	// the dictionary is compiled from the asset, not from the compiled dictionary which may be outdated.
+	c.overrides = map[string]string{"ASSETS_COMPILED_DICTIONARY": ""}
+
+	return withDictionary(func(c *cli, dict *dictionary.Dictionary, _ []string) error {
+		var info dictionary.CompiledInfo
+		// the file is replaced by renaming, since the running server may have the old one mapped.
+		err := writeFileAtomicFunc(output, func(w io.Writer) (err error) {
+			info, err = dict.Compile(w)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("asset compile: %w", err)
+		}
+
+		stat, err := os.Stat(output)
+		if err != nil {
+			return fmt.Errorf("asset compile: %w", err)
+		}
+
+		return c.print(&compiledDictionary{Path: output, Size: stat.Size(), CompiledInfo: info})
+	})(ctx, c, args)
+}
+
+type compiledDictionary struct {
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+	dictionary.CompiledInfo
+}
+
+func (d *compiledDictionary) RenderText(w io.Writer, _ plaintext.Options) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintf(tw, "path\t%s\n", d.Path)
+	_, _ = fmt.Fprintf(tw, "size\t%d\n", d.Size)
+	_, _ = fmt.Fprintf(tw, "edition\t%s\n", d.Stats.Edition)
+	_, _ = fmt.Fprintf(tw, "lemmas\t%d\n", d.Stats.LemmaCount)
+	_, _ = fmt.Fprintf(tw, "content sha256\t%s\n", d.ContentSHA256)
+	_, _ = fmt.Fprintf(tw, "compiled at\t%s\n", d.CompiledAt.Format(time.RFC3339))
+	return tw.Flush()
+}
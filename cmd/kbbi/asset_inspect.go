@@ -0,0 +1,287 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"github.com/mattn/go-isatty"
+	"github.com/raf555/kbbi-api/internal/dictionary"
+	"github.com/raf555/kbbi-api/internal/plaintext"
+)
+
+var assetInspectCommand = cliCommand{
+	name:  "inspect",
+	usage: "asset inspect [flags] [asset...]",
+	description: "Check whether the assets can be decrypted and decoded with the configured key, e.g. kbbi asset inspect dict.db\n" +
+		"The signature is also verified if ASSETS_SIGNATURE_PUBLIC_KEYS is set.\n" +
+		"The dict.db and wotd.db in the assets directory are inspected if no asset is given.",
+	args: anyArgs,
+	flags: func(c *cli, fs *flag.FlagSet) {
+		c.outputFlags(fs, "text", "json")
+		assetKeyFlags(fs)
+	},
+	run: runAssetInspect,
+}
+
+var assetDecryptCommand = cliCommand{
+	name:  "decrypt",
+	usage: "asset decrypt --plaintext [flags] <asset>",
+	description: "Decrypt the asset into its JSON content for debugging, e.g. kbbi asset decrypt --plaintext dict.db --output dict.json\n" +
+		"The content is the dictionary data in plain, so it must be explicitly allowed with --plaintext. Don't redistribute it.",
+	args: 1,
+	flags: func(c *cli, fs *flag.FlagSet) {
+		fs.Bool("plaintext", false, "allow writing the decrypted content")
+		fs.String("output", "", "write to the file instead of stdout")
+		assetKeyFlags(fs)
+	},
+	run: runAssetDecrypt,
+}
+
+type assetInspection struct {
+	Path string `json:"path"`
+	dictionary.AssetInspection
+
+	// Manifest is the result of the comparison with the manifest written by `asset pack`, if any.
+	Manifest  string               `json:"manifest,omitempty"`
+	Signature *signatureInspection `json:"signature"`
+}
+
+type signatureInspection struct {
+	// Source is how the asset is signed, embedded, detached, or none.
+	Source string `json:"source"`
+	// VerifiedBy identifies the public key which verifies the signature. It is empty if it is not verified.
+	VerifiedBy string `json:"verifiedBy,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// OK reports whether the asset can be read by the server, including its signature.
+func (i assetInspection) OK() bool {
+	return i.AssetInspection.OK() && i.Signature.Error == ""
+}
+
+type assetInspections struct {
+	Assets []assetInspection `json:"assets"`
+}
+
+func runAssetInspect(ctx context.Context, c *cli, args []string) error {
+	cfg, err := c.loadAssetConfig(ctx, "asset inspect")
+	if err != nil {
+		return err
+	}
+
+	paths := args
+	if len(paths) == 0 {
+		for _, kind := range []dictionary.AssetKind{dictionary.AssetKindDictionary, dictionary.AssetKindWOTD} {
+			paths = append(paths, filepath.Join(cfg.Directory, kind.Filename()))
+		}
+	}
+
+	res := &assetInspections{}
+	for _, path := range paths {
+		ciphertext, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("asset inspect: %w", err)
+		}
+
+		inspection := assetInspection{
+			Path:            path,
+			AssetInspection: dictionary.InspectAsset(ciphertext, cfg.keyring(), cfg.IV),
+		}
+		inspection.Manifest = compareManifest(path, inspection.AssetInspection)
+		inspection.Signature = inspectSignature(path, ciphertext, cfg.PublicKeys)
+
+		res.Assets = append(res.Assets, inspection)
+	}
+
+	if err := c.print(res); err != nil {
+		return err
+	}
+
+	for _, inspection := range res.Assets {
+		if !inspection.OK() {
+			return errSilent
+		}
+	}
+
+	return nil
+}
+
+// inspectSignature finds the signature of the asset, and verifies it if the public keys are set.
+func inspectSignature(path string, asset []byte, keys dictionary.PublicKeys) *signatureInspection {
+	res := &signatureInspection{Source: "none"}
+
+	_, embedded, err := dictionary.SplitSignature(asset)
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+
+	detached, detachedErr := os.ReadFile(path + dictionary.SignatureSuffix)
+	switch {
+	case embedded != nil:
+		res.Source, detached = signatureEmbedded, nil
+	case detachedErr == nil:
+		res.Source = signatureDetached
+	}
+
+	if len(keys) == 0 {
+		return res
+	}
+
+	if _, key, err := dictionary.VerifyAsset(asset, detached, keys); err != nil {
+		res.Error = err.Error()
+	} else {
+		res.VerifiedBy = key.String()
+	}
+
+	return res
+}
+
+// compareManifest compares the inspection with the manifest of the asset, if any.
+func compareManifest(path string, inspection dictionary.AssetInspection) string {
+	b, err := os.ReadFile(manifestPath(path))
+	if errors.Is(err, os.ErrNotExist) {
+		return ""
+	}
+	if err != nil {
+		return "unreadable: " + err.Error()
+	}
+
+	var manifest dictionary.AssetManifest
+	if err := json.Unmarshal(b, &manifest); err != nil {
+		return "unreadable: " + err.Error()
+	}
+
+	switch {
+	case manifest.SHA256 != inspection.SHA256:
+		return "mismatch, the asset is not the one in the manifest"
+	case inspection.ContentSHA256 != "" && manifest.ContentSHA256 != inspection.ContentSHA256:
+		return "mismatch, the content is not the one in the manifest"
+	default:
+		return "match"
+	}
+}
+
+func (r *assetInspections) RenderText(w io.Writer, _ plaintext.Options) error {
+	for i, a := range r.Assets {
+		if i > 0 {
+			_, _ = fmt.Fprintln(w)
+		}
+
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintf(tw, "asset\t%s\n", a.Path)
+		_, _ = fmt.Fprintf(tw, "size\t%d bytes\n", a.Size)
+		_, _ = fmt.Fprintf(tw, "sha256\t%s\n", a.SHA256)
+		if e := a.Envelope; e != nil {
+			_, _ = fmt.Fprintf(tw, "envelope\tv%d, %s, %s\n", a.EnvelopeVersion, e.ContentType, e.ContentEncoding)
+			if e.KeyID != "" {
+				_, _ = fmt.Fprintf(tw, "key id\t%s\n", e.KeyID)
+			}
+			if e.Edition != "" {
+				_, _ = fmt.Fprintf(tw, "packed edition\t%s\n", e.Edition)
+			}
+			_, _ = fmt.Fprintf(tw, "created\t%s\n", e.CreatedAt.Format(time.RFC3339))
+		} else if a.Size > 0 {
+			_, _ = fmt.Fprintf(tw, "envelope\tnone (legacy)\n")
+		}
+		_, _ = fmt.Fprintf(tw, "decrypt\t%s\n", stageStatus(a.Decrypted, a.Error))
+		if a.Decrypted {
+			_, _ = fmt.Fprintf(tw, "decrypted with\t%s\n", keyLabel(a.KeyID, a.KeyFingerprint))
+		}
+		if a.Decrypted {
+			_, _ = fmt.Fprintf(tw, "gunzip\t%s\n", stageStatus(a.Gunzipped, a.Error))
+		}
+		if a.Gunzipped {
+			_, _ = fmt.Fprintf(tw, "json\t%s\n", stageStatus(a.JSONParsed, a.Error))
+			_, _ = fmt.Fprintf(tw, "content\t%d bytes\n", a.ContentSize)
+			_, _ = fmt.Fprintf(tw, "content sha256\t%s\n", a.ContentSHA256)
+		}
+		if a.JSONParsed {
+			_, _ = fmt.Fprintf(tw, "kind\t%s\n", a.Kind)
+		}
+		if a.Stats != nil {
+			_, _ = fmt.Fprintf(tw, "edition\t%s\n", a.Stats.Edition)
+			_, _ = fmt.Fprintf(tw, "stats\t%d lemmas, %d entries\n", a.Stats.LemmaCount, a.Stats.EntryCount)
+			_, _ = fmt.Fprintf(tw, "actual\t%d lemmas, %d entries\n", a.LemmaCount, a.EntryCount)
+		}
+		if a.Kind == dictionary.AssetKindWOTD {
+			_, _ = fmt.Fprintf(tw, "indexes\t%d\n", a.Count)
+		}
+		if a.Manifest != "" {
+			_, _ = fmt.Fprintf(tw, "manifest\t%s\n", a.Manifest)
+		}
+		switch sig := a.Signature; {
+		case sig.Error != "":
+			_, _ = fmt.Fprintf(tw, "signature\t%s, failed: %s\n", sig.Source, sig.Error)
+		case sig.VerifiedBy != "":
+			_, _ = fmt.Fprintf(tw, "signature\t%s, verified by %s\n", sig.Source, sig.VerifiedBy)
+		case sig.Source != "none":
+			_, _ = fmt.Fprintf(tw, "signature\t%s, not verified without the public keys\n", sig.Source)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// keyLabel identifies the key like [dictionary.Key.String].
+func keyLabel(id, fingerprint string) string {
+	if id == "" {
+		return fingerprint + " (fingerprint)"
+	}
+	return fmt.Sprintf("%s (fingerprint %s)", id, fingerprint)
+}
+
+func stageStatus(ok bool, err string) string {
+	if ok {
+		return "ok"
+	}
+	return "failed: " + err
+}
+
+func runAssetDecrypt(ctx context.Context, c *cli, args []string) error {
+	if c.flags.Lookup("plaintext").Value.String() != "true" {
+		c.flags.Usage()
+		return usageErrorf("asset decrypt: --plaintext is required to write the decrypted content")
+	}
+
+	output := c.flags.Lookup("output").Value.String()
+	if output == "" && isatty.IsTerminal(os.Stdout.Fd()) {
+		return usageErrorf("asset decrypt: refusing to write the content to the terminal, set --output or redirect stdout")
+	}
+
+	cfg, err := c.loadAssetConfig(ctx, "asset decrypt")
+	if err != nil {
+		return err
+	}
+
+	ciphertext, err := os.ReadFile(args[0])
+	if err != nil {
+		return fmt.Errorf("asset decrypt: %w", err)
+	}
+
+	content, err := dictionary.OpenAsset(ciphertext, cfg.keyring(), cfg.IV)
+	if err != nil {
+		return fmt.Errorf("asset decrypt: %s: %w", args[0], err)
+	}
+
+	if output == "" {
+		_, err = c.stdout.Write(content)
+	} else {
+		err = os.WriteFile(output, content, 0o600)
+	}
+	if err != nil {
+		return fmt.Errorf("asset decrypt: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/raf555/kbbi-api/internal/dictionary"
+	"github.com/raf555/kbbi-api/internal/plaintext"
+)
+
+var assetLintCommand = cliCommand{
+	name:  "lint",
+	usage: "asset lint [flags]",
+	description: "Check the loaded dictionary and word of the day for data problems, e.g. kbbi asset lint --min-severity warning\n" +
+		"Exits with non-zero code if there is any issue at least as severe as --fail-on. " +
+		"Set ASSETS_VALIDATION to warn or strict to run the same checks on startup. " +
+		"The compiled dictionary is also checked against its checksum if ASSETS_COMPILED_DICTIONARY is set.",
+	flags: func(c *cli, fs *flag.FlagSet) {
+		c.outputFlags(fs, "text", "json")
+		fs.String("min-severity", string(dictionary.SeverityInfo), "only show the issues at least as severe as this, one of info, warning, error")
+		fs.String("fail-on", string(dictionary.SeverityError), "fail if there is an issue at least as severe as this, one of info, warning, error, never")
+	},
+	run: func(ctx context.Context, c *cli, args []string) error {
+		minSeverity, ok := dictionary.ParseSeverity(c.flags.Lookup("min-severity").Value.String())
+		if !ok {
+			return usageErrorf("asset lint: invalid --min-severity %q", minSeverity)
+		}
+
+		failOn, ok := dictionary.ParseSeverity(c.flags.Lookup("fail-on").Value.String())
+		if !ok && failOn != "never" {
+			return usageErrorf("asset lint: invalid --fail-on %q", failOn)
+		}
+
+		// the issues are reported by the command instead of failing to load the dictionary.
+		c.overrides = map[string]string{"ASSETS_VALIDATION": string(dictionary.ValidationOff)}
+
+		return withDictionary(func(c *cli, dict *dictionary.Dictionary, _ []string) error {
+			res := &lintReport{Issues: []dictionary.LintIssue{}, Summary: map[dictionary.Severity]int{}}
+			failed := false
+			for _, issue := range dict.Lint() {
+				res.Summary[issue.Severity]++
+				if failOn != "never" && issue.Severity.AtLeast(failOn) {
+					failed = true
+				}
+				if issue.Severity.AtLeast(minSeverity) {
+					res.Issues = append(res.Issues, issue)
+				}
+			}
+
+			if err := c.print(res); err != nil {
+				return err
+			}
+
+			if failed {
+				return errSilent
+			}
+			return nil
+		})(ctx, c, args)
+	},
+}
+
+type lintReport struct {
+	Issues []dictionary.LintIssue `json:"issues"`
+	// Summary is the number of all issues by severity, including the ones which are not shown.
+	Summary map[dictionary.Severity]int `json:"summary"`
+}
+
+func (r *lintReport) RenderText(w io.Writer, _ plaintext.Options) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, issue := range r.Issues {
+		lemma := issue.Lemma
+		if lemma == "" {
+			lemma = "-"
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", issue.Severity, issue.Check, lemma, issue.Message)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	_, err := fmt.Fprintf(w, "%d error(s), %d warning(s), %d info\n",
+		r.Summary[dictionary.SeverityError], r.Summary[dictionary.SeverityWarning], r.Summary[dictionary.SeverityInfo])
+	return err
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/raf555/kbbi-api/internal/dictionary"
+	"github.com/raf555/kbbi-api/internal/plaintext"
+)
+
+var assetPackCommand = cliCommand{
+	name:  "pack",
+	usage: "asset pack [flags] <source.json>",
+	description: "Validate, compress and encrypt the asset source, e.g. kbbi asset pack assets/sample/dict.json\n" +
+		"The asset is written along with its manifest, in an envelope with its own random nonce. " +
+		"The asset is encrypted with the current key, and signed if the signing key is set. The keys are read from the config if the flags are not set.",
+	args: 1,
+	flags: func(c *cli, fs *flag.FlagSet) {
+		c.outputFlags(fs, "text", "json")
+		fs.String("kind", "", "kind of the asset, dict or wotd (default from the source file name)")
+		fs.String("output", "", "path of the asset (default <kind>.db in the source directory)")
+		fs.String("edition", "", "edition of the dictionary (default from the source stats)")
+		assetKeyFlags(fs)
+		fs.String("key-id", "", "ID of the encryption key written in the envelope, overrides ASSETS_ENCRYPTION_KEY_ID")
+		assetSigningFlags(fs)
+	},
+	run: runAssetPack,
+}
+
+func runAssetPack(ctx context.Context, c *cli, args []string) error {
+	source := args[0]
+
+	kind := dictionary.AssetKind(c.flags.Lookup("kind").Value.String())
+	switch kind {
+	case "":
+		kind = dictionary.AssetKindDictionary
+		if strings.HasPrefix(filepath.Base(source), string(dictionary.AssetKindWOTD)) {
+			kind = dictionary.AssetKindWOTD
+		}
+	case dictionary.AssetKindDictionary, dictionary.AssetKindWOTD:
+	default:
+		return usageErrorf("asset pack: unsupported kind %q", kind)
+	}
+
+	output := c.flags.Lookup("output").Value.String()
+	if output == "" {
+		output = filepath.Join(filepath.Dir(source), kind.Filename())
+	}
+
+	cfg, err := c.loadAssetConfig(ctx, "asset pack")
+	if err != nil {
+		return err
+	}
+
+	// the asset is encrypted with the current key, which is the first in the keyring.
+	key := cfg.keyring()[0]
+	if keyID := c.flags.Lookup("key-id").Value.String(); keyID != "" {
+		key.ID = keyID
+	}
+
+	signingKey, sign, err := c.signingKey(cfg, "asset pack")
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(source)
+	if err != nil {
+		return fmt.Errorf("asset pack: %w", err)
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	manifest := dictionary.AssetManifest{
+		File:      filepath.Base(output),
+		Kind:      kind,
+		CreatedAt: time.Now().UTC().Truncate(time.Second),
+	}
+
+	var data any
+	switch kind {
+	case dictionary.AssetKindDictionary:
+		assetData, err := dictionary.ParseAssetData(f, c.flags.Lookup("edition").Value.String())
+		if err != nil {
+			return fmt.Errorf("asset pack: %s: %w", source, err)
+		}
+		data, manifest.Stats = assetData, &assetData.Stats
+	case dictionary.AssetKindWOTD:
+		indexes, err := dictionary.ParseWOTDData(f, 0)
+		if err != nil {
+			return fmt.Errorf("asset pack: %s: %w", source, err)
+		}
+		data, manifest.Count = indexes, len(indexes)
+	}
+
+	header := dictionary.EnvelopeHeader{
+		KeyID:       key.ID,
+		ContentType: kind.ContentType(),
+		CreatedAt:   manifest.CreatedAt,
+	}
+	if manifest.Stats != nil {
+		header.Edition = manifest.Stats.Edition
+	}
+	manifest.KeyID = key.ID
+
+	var buf bytes.Buffer
+	if manifest.ContentSHA256, err = dictionary.WriteAsset(&buf, data, key.Key, header); err != nil {
+		return fmt.Errorf("asset pack: %w", err)
+	}
+
+	if err := verifyAsset(buf.Bytes(), manifest.ContentSHA256, key); err != nil {
+		return fmt.Errorf("asset pack: verify: %w", err)
+	}
+
+	asset := buf.Bytes()
+	if sign {
+		embed := c.flags.Lookup("embed-signature").Value.String() == "true"
+		if asset, manifest.Signature, err = signAsset(output, asset, signingKey, embed); err != nil {
+			return fmt.Errorf("asset pack: %w", err)
+		}
+	}
+
+	sum := sha256.Sum256(asset)
+	manifest.Size, manifest.SHA256 = int64(len(asset)), hex.EncodeToString(sum[:])
+
+	if err := writeFileAtomic(output, asset); err != nil {
+		return fmt.Errorf("asset pack: %w", err)
+	}
+
+	manifestJSON, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("asset pack: %w", err)
+	}
+	if err := writeFileAtomic(manifestPath(output), append(manifestJSON, '\n')); err != nil {
+		return fmt.Errorf("asset pack: %w", err)
+	}
+
+	return c.print(&packedAsset{Path: output, Manifest: manifestPath(output), AssetManifest: manifest})
+}
+
+// verifyAsset makes sure the packed asset can be read back by [dictionary.ReadAsset] into the same content.
+func verifyAsset(asset []byte, contentSHA256 string, key dictionary.Key) error {
+	dir, err := os.MkdirTemp("", "kbbi-asset-")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+
+	const filename = "asset.db"
+	if err := os.WriteFile(filepath.Join(dir, filename), asset, 0o600); err != nil {
+		return err
+	}
+
+	var content json.RawMessage
+	if err := dictionary.ReadAsset(filename, dir, dictionary.Keyring{key}, nil).To(&content); err != nil {
+		return err
+	}
+
+	// json.Encoder appends a newline, which is not part of the decoded value.
+	sum := sha256.Sum256(append(content, '\n'))
+	if hex.EncodeToString(sum[:]) != contentSHA256 {
+		return fmt.Errorf("content mismatch")
+	}
+
+	return nil
+}
+
+// manifestPath is the path of the manifest of the asset, e.g. dict.manifest.json for dict.db.
+func manifestPath(asset string) string {
+	return strings.TrimSuffix(asset, filepath.Ext(asset)) + ".manifest.json"
+}
+
+type packedAsset struct {
+	Path     string `json:"path"`
+	Manifest string `json:"manifest"`
+	dictionary.AssetManifest
+}
+
+func (a *packedAsset) RenderText(w io.Writer, _ plaintext.Options) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintf(tw, "asset\t%s\n", a.Path)
+	_, _ = fmt.Fprintf(tw, "manifest\t%s\n", a.Manifest)
+	_, _ = fmt.Fprintf(tw, "kind\t%s\n", a.Kind)
+	if a.KeyID != "" {
+		_, _ = fmt.Fprintf(tw, "key id\t%s\n", a.KeyID)
+	}
+	if a.Signature != "" {
+		_, _ = fmt.Fprintf(tw, "signature\t%s\n", a.Signature)
+	}
+	if a.Stats != nil {
+		_, _ = fmt.Fprintf(tw, "edition\t%s\n", a.Stats.Edition)
+		_, _ = fmt.Fprintf(tw, "lemmas\t%d\n", a.Stats.LemmaCount)
+		_, _ = fmt.Fprintf(tw, "entries\t%d\n", a.Stats.EntryCount)
+	} else {
+		_, _ = fmt.Fprintf(tw, "indexes\t%d\n", a.Count)
+	}
+	_, _ = fmt.Fprintf(tw, "size\t%d bytes\n", a.Size)
+	_, _ = fmt.Fprintf(tw, "sha256\t%s\n", a.SHA256)
+	_, _ = fmt.Fprintf(tw, "content sha256\t%s\n", a.ContentSHA256)
+	return tw.Flush()
+}
@@ -0,0 +1,246 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/raf555/kbbi-api/internal/dictionary"
+	"github.com/raf555/kbbi-api/internal/encoding"
+	"github.com/raf555/kbbi-api/internal/plaintext"
+)
+
+var assetRekeyCommand = cliCommand{
+	name:  "rekey",
+	usage: "asset rekey [flags] <asset...>",
+	description: "Re-encrypt the assets with a new key, e.g. kbbi asset rekey --new-key-id 2026-10 --new-key <hex> assets/dict.db\n" +
+		"The assets are decrypted with the configured keys and overwritten, along with their manifest if any. " +
+		"The new key is the current key in the config if --new-key is not set.",
+	args: anyArgs,
+	flags: func(c *cli, fs *flag.FlagSet) {
+		c.outputFlags(fs, "text", "json")
+		fs.String("new-key", "", "hex encoded key to encrypt the assets with (default the current key)")
+		fs.String("new-key-id", "", "ID of the new key written in the envelope")
+		fs.String("output", "", "write to the file instead of overwriting the asset, only for a single asset")
+		assetKeyFlags(fs)
+		assetSigningFlags(fs)
+	},
+	run: runAssetRekey,
+}
+
+func runAssetRekey(ctx context.Context, c *cli, args []string) error {
+	if len(args) == 0 {
+		c.flags.Usage()
+		return usageErrorf("asset rekey: missing asset")
+	}
+
+	output := c.flags.Lookup("output").Value.String()
+	if output != "" && len(args) > 1 {
+		return usageErrorf("asset rekey: --output is only allowed for a single asset")
+	}
+
+	cfg, err := c.loadAssetConfig(ctx, "asset rekey")
+	if err != nil {
+		return err
+	}
+
+	newKey := cfg.keyring()[0]
+	if value := c.flags.Lookup("new-key").Value.String(); value != "" {
+		var key encoding.HexString
+		if err := key.UnmarshalText([]byte(value)); err != nil {
+			return usageErrorf("asset rekey: invalid --new-key: %w", err)
+		}
+		newKey = dictionary.Key{Key: key}
+	}
+	if keyID := c.flags.Lookup("new-key-id").Value.String(); keyID != "" {
+		newKey.ID = keyID
+	}
+
+	signingKey, sign, err := c.signingKey(cfg, "asset rekey")
+	if err != nil {
+		return err
+	}
+	signer := assetSigner{key: signingKey, sign: sign, embed: c.flags.Lookup("embed-signature").Value.String() == "true"}
+
+	res := &rekeyedAssets{NewKey: newKey.String()}
+	for _, path := range args {
+		rekeyed, err := rekeyAsset(path, output, cfg, newKey, signer)
+		if err != nil {
+			return fmt.Errorf("asset rekey: %s: %w", path, err)
+		}
+		res.Assets = append(res.Assets, rekeyed)
+	}
+
+	return c.print(res)
+}
+
+// assetSigner signs the rekeyed asset, since its signature doesn't match anymore.
+type assetSigner struct {
+	key         dictionary.Key
+	sign, embed bool
+}
+
+func rekeyAsset(path, output string, cfg assetConfig, newKey dictionary.Key, signer assetSigner) (rekeyedAsset, error) {
+	asset, err := os.ReadFile(path)
+	if err != nil {
+		return rekeyedAsset{}, err
+	}
+
+	_, embedded, err := dictionary.SplitSignature(asset)
+	if err != nil {
+		return rekeyedAsset{}, err
+	}
+	_, err = os.Stat(path + dictionary.SignatureSuffix)
+	detached := err == nil
+	if (embedded != nil || detached) && !signer.sign {
+		return rekeyedAsset{}, fmt.Errorf("the asset is signed, set the signing key to sign the rekeyed asset")
+	}
+
+	rekeyed, oldKey, err := dictionary.RekeyAsset(asset, cfg.keyring(), cfg.IV, newKey)
+	if err != nil {
+		return rekeyedAsset{}, err
+	}
+
+	// the content must stay the same, only the encryption changes.
+	before, err := dictionary.OpenAsset(asset, cfg.keyring(), cfg.IV)
+	if err != nil {
+		return rekeyedAsset{}, err
+	}
+	after, err := dictionary.OpenAsset(rekeyed, dictionary.Keyring{newKey}, nil)
+	if err != nil {
+		return rekeyedAsset{}, fmt.Errorf("verify: %w", err)
+	}
+	if !bytes.Equal(before, after) {
+		return rekeyedAsset{}, fmt.Errorf("verify: content mismatch")
+	}
+
+	if output == "" {
+		output = path
+	}
+
+	res := rekeyedAsset{Path: output, OldKey: oldKey.String()}
+	if signer.sign {
+		// the signature is kept embedded if it was.
+		if rekeyed, res.Signature, err = signAsset(output, rekeyed, signer.key, signer.embed || embedded != nil); err != nil {
+			return rekeyedAsset{}, err
+		}
+	}
+
+	if err := writeFileAtomic(output, rekeyed); err != nil {
+		return rekeyedAsset{}, err
+	}
+
+	sum := sha256.Sum256(rekeyed)
+	res.SHA256 = hex.EncodeToString(sum[:])
+
+	res.Manifest, err = rekeyManifest(path, output, res, int64(len(rekeyed)), newKey.ID)
+	if err != nil {
+		return rekeyedAsset{}, fmt.Errorf("manifest: %w", err)
+	}
+
+	return res, nil
+}
+
+// rekeyManifest writes the manifest of the rekeyed asset from the manifest of the original asset, if any.
+// It returns the path of the written manifest.
+func rekeyManifest(path, output string, rekeyed rekeyedAsset, size int64, keyID string) (string, error) {
+	b, err := os.ReadFile(manifestPath(path))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	var manifest dictionary.AssetManifest
+	if err := json.Unmarshal(b, &manifest); err != nil {
+		return "", err
+	}
+	manifest.File, manifest.SHA256, manifest.Size, manifest.KeyID = filepath.Base(output), rekeyed.SHA256, size, keyID
+	manifest.Signature = rekeyed.Signature
+
+	manifestJSON, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	if err := writeFileAtomic(manifestPath(output), append(manifestJSON, '\n')); err != nil {
+		return "", err
+	}
+
+	return manifestPath(output), nil
+}
+
+// writeFileAtomic writes the file through a temporary file, so the server never reads a partially written asset.
+func writeFileAtomic(path string, b []byte) error {
+	return writeFileAtomicFunc(path, func(w io.Writer) error {
+		_, err := w.Write(b)
+		return err
+	})
+}
+
+// writeFileAtomicFunc is writeFileAtomic with the content written by write, so it doesn't have to be held in memory.
+func writeFileAtomicFunc(path string, write func(w io.Writer) error) error {
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = os.Remove(f.Name())
+	}()
+
+	if err := write(f); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Chmod(0o644); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), path)
+}
+
+type rekeyedAsset struct {
+	Path string `json:"path"`
+	// OldKey identifies the key which decrypted the asset.
+	OldKey    string `json:"oldKey"`
+	SHA256    string `json:"sha256"`
+	Signature string `json:"signature,omitempty"`
+	Manifest  string `json:"manifest,omitempty"`
+}
+
+type rekeyedAssets struct {
+	NewKey string         `json:"newKey"`
+	Assets []rekeyedAsset `json:"assets"`
+}
+
+func (r *rekeyedAssets) RenderText(w io.Writer, _ plaintext.Options) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintf(tw, "new key\t%s\n", r.NewKey)
+	for _, a := range r.Assets {
+		_, _ = fmt.Fprintln(tw)
+		_, _ = fmt.Fprintf(tw, "asset\t%s\n", a.Path)
+		_, _ = fmt.Fprintf(tw, "old key\t%s\n", a.OldKey)
+		_, _ = fmt.Fprintf(tw, "sha256\t%s\n", a.SHA256)
+		if a.Signature != "" {
+			_, _ = fmt.Fprintf(tw, "signature\t%s\n", a.Signature)
+		}
+		if a.Manifest != "" {
+			_, _ = fmt.Fprintf(tw, "manifest\t%s\n", a.Manifest)
+		}
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/raf555/kbbi-api/internal/dictionary"
+	"github.com/raf555/kbbi-api/internal/encoding"
+	"github.com/raf555/kbbi-api/internal/plaintext"
+)
+
+const (
+	signatureEmbedded = "embedded"
+	signatureDetached = "detached"
+)
+
+func assetSigningFlags(fs *flag.FlagSet) {
+	fs.String("signing-key", "", "hex encoded Ed25519 private key to sign the asset, overrides ASSETS_SIGNING_KEY")
+	fs.String("signing-key-id", "", "ID of the signing key written in the signature, overrides ASSETS_SIGNING_KEY_ID")
+	fs.Bool("embed-signature", false, "embed the signature in the asset instead of writing it to <asset>"+dictionary.SignatureSuffix)
+}
+
+// signingKey is the key from [assetSigningFlags] or the config. ok is false if the asset is not signed.
+func (c *cli) signingKey(cfg assetConfig, cmdName string) (key dictionary.Key, ok bool, err error) {
+	key = dictionary.Key{ID: cfg.SigningKeyID, Key: cfg.SigningKey}
+	if value := c.flags.Lookup("signing-key").Value.String(); value != "" {
+		var privateKey encoding.HexString
+		if err := privateKey.UnmarshalText([]byte(value)); err != nil {
+			return dictionary.Key{}, false, usageErrorf("%s: invalid --signing-key: %w", cmdName, err)
+		}
+		key = dictionary.Key{Key: privateKey}
+	}
+	if keyID := c.flags.Lookup("signing-key-id").Value.String(); keyID != "" {
+		key.ID = keyID
+	}
+
+	return key, len(key.Key) > 0, nil
+}
+
+// signAsset signs the asset which is written to path. It returns the asset to write, which has the signature if it is
+// embedded, and how it is signed. The detached signature is written next to path.
+func signAsset(path string, asset []byte, key dictionary.Key, embed bool) ([]byte, string, error) {
+	sig, err := dictionary.SignAsset(asset, key)
+	if err != nil {
+		return nil, "", fmt.Errorf("sign: %w", err)
+	}
+
+	if embed {
+		signed, err := dictionary.EmbedSignature(asset, sig)
+		if err != nil {
+			return nil, "", fmt.Errorf("sign: %w", err)
+		}
+		// the stale detached signature would be used if the embedded one is missing.
+		if err := os.Remove(path + dictionary.SignatureSuffix); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, "", fmt.Errorf("sign: %w", err)
+		}
+		return signed, signatureEmbedded, nil
+	}
+
+	sigJSON, err := json.MarshalIndent(sig, "", "  ")
+	if err != nil {
+		return nil, "", fmt.Errorf("sign: %w", err)
+	}
+	if err := writeFileAtomic(path+dictionary.SignatureSuffix, append(sigJSON, '\n')); err != nil {
+		return nil, "", fmt.Errorf("sign: %w", err)
+	}
+
+	return asset, signatureDetached, nil
+}
+
+var assetKeygenCommand = cliCommand{
+	name:  "keygen",
+	usage: "asset keygen [flags]",
+	description: "Generate a random key, e.g. kbbi asset keygen --type signing\n" +
+		"The encryption key is for ASSETS_ENCRYPTION_KEY. The signing key is for ASSETS_SIGNING_KEY, " +
+		"and its public key is for ASSETS_SIGNATURE_PUBLIC_KEYS.",
+	flags: func(c *cli, fs *flag.FlagSet) {
+		c.outputFlags(fs, "text", "json")
+		fs.String("type", "encryption", "type of the key, encryption or signing")
+	},
+	run: func(_ context.Context, c *cli, _ []string) error {
+		var key generatedKey
+		switch keyType := c.flags.Lookup("type").Value.String(); keyType {
+		case "encryption":
+			b := make([]byte, 32)
+			if _, err := rand.Read(b); err != nil {
+				return fmt.Errorf("asset keygen: %w", err)
+			}
+			key.Key = hex.EncodeToString(b)
+		case "signing":
+			publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+			if err != nil {
+				return fmt.Errorf("asset keygen: %w", err)
+			}
+			key.Key, key.PublicKey = hex.EncodeToString(privateKey.Seed()), hex.EncodeToString(publicKey)
+		default:
+			return usageErrorf("asset keygen: unsupported type %q", keyType)
+		}
+
+		return c.print(&key)
+	},
+}
+
+type generatedKey struct {
+	Key string `json:"key"`
+	// PublicKey is only present for the signing key.
+	PublicKey string `json:"publicKey,omitempty"`
+}
+
+func (k *generatedKey) RenderText(w io.Writer, _ plaintext.Options) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintf(tw, "key\t%s\n", k.Key)
+	if k.PublicKey != "" {
+		_, _ = fmt.Fprintf(tw, "public key\t%s\n", k.PublicKey)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,366 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/mattn/go-isatty"
+	"github.com/raf555/kbbi-api/cmd/cmdfx"
+	"github.com/raf555/kbbi-api/internal/config"
+	"github.com/raf555/kbbi-api/internal/config/configfx"
+	"github.com/raf555/kbbi-api/internal/dictionary/dictionaryfx"
+	"github.com/raf555/kbbi-api/internal/logger"
+	"github.com/raf555/kbbi-api/internal/plaintext"
+	"go.uber.org/dig"
+	"go.uber.org/fx"
+)
+
+const (
+	exitOK      = 0
+	exitFailure = 1
+	// exitUsage is used when the command is invoked incorrectly, e.g. unknown command or flag.
+	exitUsage = 2
+)
+
+// anyArgs is used as [cliCommand.args] when the command accepts any number of positional arguments.
+const anyArgs = -1
+
+// errSilent is returned by the command when the error message is already printed,
+// so the command only needs to exit with non-zero code.
+var errSilent = errors.New("silent error")
+
+// usageError is returned when the command is invoked incorrectly.
+type usageError struct {
+	err error
+}
+
+func (e *usageError) Error() string {
+	return e.err.Error()
+}
+
+func (e *usageError) Unwrap() error {
+	return e.err
+}
+
+func usageErrorf(format string, args ...any) error {
+	return &usageError{fmt.Errorf(format, args...)}
+}
+
+// cliCommand is a subcommand of the kbbi binary. Each command composes its own fx modules through [cmdfx].
+type cliCommand struct {
+	name string
+	// usage is the synopsis of the command without the binary name, e.g. `lookup [flags] <lemma>`.
+	usage       string
+	description string
+	// args is the number of the expected positional arguments, or [anyArgs].
+	args int
+	// flags registers the command specific flags.
+	flags func(c *cli, fs *flag.FlagSet)
+	run   func(ctx context.Context, c *cli, args []string) error
+
+	// subcommands makes the command a group, e.g. `asset stats`. A group doesn't have its own run.
+	subcommands []cliCommand
+}
+
+// summary is the first line of the description, shown in the command list.
+func (cmd cliCommand) summary() string {
+	summary, _, _ := strings.Cut(cmd.description, "\n")
+	return summary
+}
+
+// cliCommands is set in init since the help refers to it.
+var cliCommands []cliCommand
+
+func init() {
+	cliCommands = slices.Concat(
+		[]cliCommand{serveCommand},
+		dictionaryCommands,
+		[]cliCommand{exportCommand, assetCommand, configCommand},
+	)
+}
+
+type cli struct {
+	flags  *flag.FlagSet
+	stdout io.Writer
+	stderr io.Writer
+
+	// common flags, see [newCLI].
+	envFile   string
+	assetsDir string
+	// overrides is the command specific config, which takes precedence over the config and the common flags.
+	overrides map[string]string
+
+	// output flags, see [cli.outputFlags].
+	format   string
+	formats  []string
+	textOpts plaintext.Options
+	verbose  bool
+
+	// lookup flags, see [dictionaryCommands].
+	entryNo int
+	limit   uint
+}
+
+// runCLI runs the command named args[0] with the rest of args, and returns the exit code.
+func runCLI(ctx context.Context, args []string) int {
+	err := dispatch(ctx, cliCommands, "", args)
+
+	var usageErr *usageError
+	switch {
+	case err == nil, errors.Is(err, flag.ErrHelp):
+		return exitOK
+	case errors.As(err, &usageErr):
+		_, _ = fmt.Fprintf(os.Stderr, "kbbi: %s\n", err)
+		return exitUsage
+	case errors.Is(err, errSilent):
+		return exitFailure
+	default:
+		_, _ = fmt.Fprintf(os.Stderr, "kbbi: %s\n", err)
+		return exitFailure
+	}
+}
+
+// dispatch finds the command named args[0] in cmds and runs it. parent is the name of the parent group, if any.
+func dispatch(ctx context.Context, cmds []cliCommand, parent string, args []string) error {
+	if len(args) == 0 {
+		printCommands(os.Stderr, parent, cmds)
+		return usageErrorf("%s: missing command", parent)
+	}
+
+	if isHelp(args[0]) {
+		printCommands(os.Stdout, parent, cmds)
+		return flag.ErrHelp
+	}
+
+	idx := slices.IndexFunc(cmds, func(cmd cliCommand) bool { return cmd.name == args[0] })
+	if idx < 0 {
+		printCommands(os.Stderr, parent, cmds)
+		return usageErrorf("unknown command %q", strings.TrimSpace(parent+" "+args[0]))
+	}
+
+	cmd := cmds[idx]
+	if len(cmd.subcommands) > 0 {
+		return dispatch(ctx, cmd.subcommands, strings.TrimSpace(parent+" "+cmd.name), args[1:])
+	}
+
+	return newCLI(cmd).run(ctx, cmd, args[1:])
+}
+
+func isHelp(arg string) bool {
+	return arg == "help" || arg == "-h" || arg == "-help" || arg == "--help"
+}
+
+func printCommands(w io.Writer, parent string, cmds []cliCommand) {
+	name := strings.TrimSpace("kbbi " + parent)
+
+	_, _ = fmt.Fprintf(w, "usage: %s <command> [flags] [args]\n\ncommands:\n", name)
+	for _, cmd := range cmds {
+		_, _ = fmt.Fprintf(w, "  %-8s  %s\n", cmd.name, cmd.summary())
+	}
+	_, _ = fmt.Fprintf(w, "\nRun '%s <command> -h' to show the flags of the command.\n", name)
+}
+
+func newCLI(cmd cliCommand) *cli {
+	c := &cli{
+		flags:  flag.NewFlagSet(cmd.name, flag.ContinueOnError),
+		stdout: os.Stdout,
+		stderr: os.Stderr,
+	}
+
+	c.flags.StringVar(&c.envFile, "env-file", "", "dotenv file to load the config from (default .env)")
+	c.flags.StringVar(&c.assetsDir, "assets-dir", "", "directory of the assets, overrides ASSETS_DIRECTORY and the download URLs")
+	if cmd.flags != nil {
+		cmd.flags(c, c.flags)
+	}
+
+	c.flags.Usage = func() {
+		_, _ = fmt.Fprintf(c.flags.Output(), "usage: kbbi %s\n\n%s\n\nflags:\n", cmd.usage, cmd.description)
+		c.flags.PrintDefaults()
+	}
+
+	return c
+}
+
+// outputFlags registers the flags of the commands which print a result. The first format is the default.
+func (c *cli) outputFlags(fs *flag.FlagSet, formats ...string) {
+	colorDefault := isatty.IsTerminal(os.Stdout.Fd()) && os.Getenv("NO_COLOR") == ""
+	widthDefault, _ := strconv.Atoi(os.Getenv("COLUMNS"))
+
+	c.formats = formats
+	fs.StringVar(&c.format, "format", formats[0], "output format, one of "+strings.Join(formats, ", "))
+	fs.BoolVar(&c.textOpts.Color, "color", colorDefault, "colorize the text output")
+	fs.IntVar(&c.textOpts.Width, "width", widthDefault, "maximum line width of the text output")
+	fs.BoolVar(&c.verbose, "verbose", false, "show the logs")
+}
+
+func (c *cli) run(ctx context.Context, cmd cliCommand, args []string) error {
+	args, err := c.parse(args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return &usageError{fmt.Errorf("%s: %w", cmd.name, err)}
+	}
+
+	if cmd.args != anyArgs && len(args) != cmd.args {
+		c.flags.Usage()
+		return usageErrorf("%s: expected %d argument(s), got %d", cmd.name, cmd.args, len(args))
+	}
+
+	if c.formats != nil && !slices.Contains(c.formats, c.format) {
+		return usageErrorf("%s: unsupported format %q", cmd.name, c.format)
+	}
+
+	if c.envFile != "" {
+		// the env file is optional by default, but not when it is given explicitly.
+		if _, err := os.Stat(c.envFile); err != nil {
+			return fmt.Errorf("%s: env file: %w", cmd.name, err)
+		}
+	}
+
+	return cmd.run(ctx, c, args)
+}
+
+// parse parses the flags which may be placed after the positional arguments, e.g. `search ap --limit 20`.
+// The arguments after `--` are all positional, e.g. `lookup -- -an`.
+func (c *cli) parse(args []string) ([]string, error) {
+	// the flag package already prints the error and usage.
+	c.flags.SetOutput(c.stderr)
+
+	var positional []string
+	for {
+		if err := c.flags.Parse(args); err != nil {
+			return nil, err
+		}
+
+		// the flag package stops after the terminator, which it doesn't keep in the rest of the arguments.
+		if rest := c.flags.Args(); c.terminated(args[:len(args)-len(rest)]) {
+			return append(positional, rest...), nil
+		}
+
+		if c.flags.NArg() == 0 {
+			return positional, nil
+		}
+
+		positional = append(positional, c.flags.Arg(0))
+		args = c.flags.Args()[1:]
+	}
+}
+
+// terminated reports whether the parsed args end with the terminator.
+// `--` is only the terminator where a flag is expected, not as the value of a flag such as `--format --`.
+func (c *cli) terminated(parsed []string) bool {
+	for i := 0; i < len(parsed); i++ {
+		if parsed[i] == "--" {
+			return true
+		}
+
+		// the args are already parsed, so the others are the flags, followed by their value if it isn't inline.
+		name, _, inline := strings.Cut(strings.TrimLeft(parsed[i], "-"), "=")
+		if f := c.flags.Lookup(name); !inline && f != nil && !isBoolFlag(f) {
+			i++
+		}
+	}
+	return false
+}
+
+func isBoolFlag(f *flag.Flag) bool {
+	bf, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return ok && bf.IsBoolFlag()
+}
+
+// source is the config source shared by all commands.
+func (c *cli) source() configfx.Source {
+	source := configfx.Source{EnvFile: c.envFile}
+	if c.assetsDir != "" {
+		// the assets are read from the directory instead of being downloaded.
+		source.Overrides = map[string]string{
+			"ASSETS_DIRECTORY":               c.assetsDir,
+			"ASSETS_DICTIONARY_DOWNLOAD_URL": "",
+			"ASSETS_WOTD_DOWNLOAD_URL":       "",
+		}
+	}
+	if len(c.overrides) > 0 {
+		source.Overrides = maps.Clone(source.Overrides)
+		if source.Overrides == nil {
+			source.Overrides = make(map[string]string, len(c.overrides))
+		}
+		maps.Copy(source.Overrides, c.overrides)
+	}
+	return source
+}
+
+// exec runs a one-shot application, the work should be done within fx.Invoke of the options.
+func (c *cli) exec(ctx context.Context, options ...fx.Option) error {
+	// logs are written to stderr to keep stdout clean for the result.
+	level := slog.LevelWarn
+	if c.verbose {
+		level = slog.LevelInfo
+	}
+	log := slog.New(slog.NewTextHandler(c.stderr, &slog.HandlerOptions{Level: level}))
+	ctx = logger.WithContext(ctx, log)
+
+	options = append([]fx.Option{
+		fx.Supply(c.source()),
+		// the CLI is not a service, so it doesn't need to be configured with service name.
+		fx.Replace(config.ServerConfig{ServiceName: "kbbi-cli"}),
+		fx.Decorate(func() *slog.Logger { return log }),
+	}, options...)
+
+	if err := cmdfx.Exec(ctx, options...); err != nil {
+		// the dependency graph is irrelevant for the CLI user.
+		return dig.RootCause(err)
+	}
+
+	return nil
+}
+
+// withDictionary loads the dictionary before running the command. T is either the repository or the dictionary itself.
+func withDictionary[T any](run func(c *cli, dict T, args []string) error) func(ctx context.Context, c *cli, args []string) error {
+	return func(ctx context.Context, c *cli, args []string) error {
+		var runErr error
+		err := c.exec(ctx,
+			dictionaryfx.Module,
+			dictionaryfx.Snapshot,
+			fx.Invoke(func(dict T) {
+				runErr = run(c, dict, args)
+			}),
+		)
+		if err != nil {
+			return fmt.Errorf("load dictionary: %w", err)
+		}
+
+		return runErr
+	}
+}
+
+type textRenderer interface {
+	RenderText(w io.Writer, opts plaintext.Options) error
+}
+
+func (c *cli) print(res textRenderer) error {
+	if c.format == "json" {
+		encoder := json.NewEncoder(c.stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(res)
+	}
+
+	return res.RenderText(c.stdout, c.textOpts)
+}
+
+func (c *cli) notFound(suggestions []string) error {
+	if err := plaintext.RenderError(c.stderr, "lemma not found", suggestions, c.textOpts); err != nil {
+		return err
+	}
+	return errSilent
+}
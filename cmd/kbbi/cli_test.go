@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"io"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunCLI(t *testing.T) {
+	missingEnvFile := filepath.Join(t.TempDir(), "missing.env")
+
+	tcs := []struct {
+		name     string
+		args     []string
+		expected int
+	}{
+		{name: "help", args: []string{"help"}, expected: exitOK},
+		{name: "command help", args: []string{"lookup", "-h"}, expected: exitOK},
+		{name: "group help", args: []string{"asset", "--help"}, expected: exitOK},
+		{name: "missing command", args: []string{}, expected: exitUsage},
+		{name: "missing subcommand", args: []string{"asset"}, expected: exitUsage},
+		{name: "unknown command", args: []string{"define"}, expected: exitUsage},
+		{name: "unknown subcommand", args: []string{"asset", "define"}, expected: exitUsage},
+		{name: "unknown flag", args: []string{"lookup", "--define", "apel"}, expected: exitUsage},
+		{name: "missing argument", args: []string{"lookup"}, expected: exitUsage},
+		{name: "too many arguments", args: []string{"lookup", "apel", "aku"}, expected: exitUsage},
+		{name: "unsupported format", args: []string{"lookup", "--format", "xml", "apel"}, expected: exitUsage},
+		{name: "compile without plaintext", args: []string{"asset", "compile", "--output", filepath.Join(t.TempDir(), "dict.bin")}, expected: exitUsage},
+		{name: "failure", args: []string{"lookup", "--env-file", missingEnvFile, "apel"}, expected: exitFailure},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, runCLI(t.Context(), tc.args))
+		})
+	}
+}
+
+func TestDispatch(t *testing.T) {
+	var ran []string
+	cmds := []cliCommand{
+		{
+			name: "run",
+			args: anyArgs,
+			run: func(_ context.Context, _ *cli, args []string) error {
+				ran = args
+				return nil
+			},
+		},
+		{
+			name: "group",
+			subcommands: []cliCommand{
+				{name: "sub", args: 0, run: func(context.Context, *cli, []string) error { return errSilent }},
+			},
+		},
+	}
+
+	tcs := []struct {
+		name string
+		args []string
+
+		expectedArgs  []string
+		expectedErr   error
+		expectedUsage bool
+	}{
+		{name: "command", args: []string{"run", "a", "b"}, expectedArgs: []string{"a", "b"}},
+		{name: "subcommand", args: []string{"group", "sub"}, expectedErr: errSilent},
+		{name: "help", args: []string{"-h"}, expectedErr: flag.ErrHelp},
+		{name: "unknown command", args: []string{"define"}, expectedUsage: true},
+		{name: "unknown subcommand", args: []string{"group", "define"}, expectedUsage: true},
+		{name: "missing subcommand", args: []string{"group"}, expectedUsage: true},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			ran = nil
+
+			err := dispatch(t.Context(), cmds, "", tc.args)
+			assert.Equal(t, tc.expectedArgs, ran)
+			if tc.expectedUsage {
+				var usageErr *usageError
+				assert.ErrorAs(t, err, &usageErr)
+				return
+			}
+			assert.ErrorIs(t, err, tc.expectedErr)
+		})
+	}
+}
+
+func TestCLI_Parse(t *testing.T) {
+	tcs := []struct {
+		name string
+		args []string
+
+		expectedArgs   []string
+		expectedX      bool
+		expectedLimit  int
+		expectedFormat string
+		expectedErr    bool
+	}{
+		{name: "no arguments", expectedLimit: 10},
+		{name: "flags before arguments", args: []string{"-x", "--limit", "20", "ap"}, expectedArgs: []string{"ap"}, expectedX: true, expectedLimit: 20},
+		{name: "flags after arguments", args: []string{"ap", "--limit", "20", "an", "-x"}, expectedArgs: []string{"ap", "an"}, expectedX: true, expectedLimit: 20},
+		{name: "terminator", args: []string{"--", "-x", "-y"}, expectedArgs: []string{"-x", "-y"}, expectedLimit: 10},
+		{name: "terminator after flags", args: []string{"-x", "--", "--limit", "20"}, expectedArgs: []string{"--limit", "20"}, expectedX: true, expectedLimit: 10},
+		{name: "terminator after arguments", args: []string{"ap", "--limit", "20", "--", "-x", "-y"}, expectedArgs: []string{"ap", "-x", "-y"}, expectedLimit: 20},
+		{name: "terminator at the end", args: []string{"ap", "--"}, expectedArgs: []string{"ap"}, expectedLimit: 10},
+		{name: "flag value of terminator", args: []string{"--format", "--", "ap", "-x"}, expectedArgs: []string{"ap"}, expectedX: true, expectedLimit: 10, expectedFormat: "--"},
+		{name: "flag value of terminator after arguments", args: []string{"ap", "--format", "--", "an", "--limit", "20"}, expectedArgs: []string{"ap", "an"}, expectedLimit: 20, expectedFormat: "--"},
+		{name: "terminator after flag value of terminator", args: []string{"--format", "--", "--", "-x"}, expectedArgs: []string{"-x"}, expectedLimit: 10, expectedFormat: "--"},
+		{name: "unknown flag", args: []string{"ap", "-y"}, expectedErr: true},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				x      bool
+				limit  int
+				format string
+			)
+			c := newCLI(cliCommand{
+				name: "test",
+				flags: func(_ *cli, fs *flag.FlagSet) {
+					fs.BoolVar(&x, "x", false, "")
+					fs.IntVar(&limit, "limit", 10, "")
+					fs.StringVar(&format, "format", "", "")
+				},
+			})
+			c.stderr = io.Discard
+
+			args, err := c.parse(tc.args)
+			if tc.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedArgs, args)
+			assert.Equal(t, tc.expectedX, x)
+			assert.Equal(t, tc.expectedLimit, limit)
+			assert.Equal(t, tc.expectedFormat, format)
+		})
+	}
+}
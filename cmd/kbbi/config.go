@@ -0,0 +1,214 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/raf555/kbbi-api/internal/config"
+	"github.com/raf555/kbbi-api/internal/dictionary"
+	"github.com/raf555/kbbi-api/internal/http/httpsrv"
+	"github.com/raf555/kbbi-api/internal/plaintext"
+	salomeconfig "github.com/raf555/salome/config/v1"
+	"go.uber.org/fx"
+)
+
+// redacted replaces the value of a secret config.
+const redacted = "[redacted]"
+
+// secretKeyParts are the parts of the config key which mark the config as a secret.
+var secretKeyParts = []string{"KEY", "SECRET", "TOKEN", "PASSWORD", "ENCRYPTION"}
+
+// configSections are the configs checked by `config check`.
+var configSections = []configSection{
+	newConfigSection[config.ServerConfig]("server"),
+	newConfigSection[dictionary.Configuration]("dictionary"),
+	newConfigSection[httpsrv.Config]("http"),
+}
+
+var configCommand = cliCommand{
+	name:        "config",
+	description: "Inspect the configuration",
+	subcommands: []cliCommand{
+		{
+			name:        "check",
+			usage:       "config check [flags]",
+			description: "Load and validate the configuration of every module, the secrets are redacted",
+			flags: func(c *cli, fs *flag.FlagSet) {
+				c.outputFlags(fs, "text", "json")
+			},
+			run: runConfigCheck,
+		},
+	},
+}
+
+type configSection struct {
+	name   string
+	fields []configField
+	load   func(provider salomeconfig.Provider) error
+}
+
+// configField is a config key read by the section.
+type configField struct {
+	key          string
+	defaultValue string
+}
+
+func newConfigSection[T any](name string) configSection {
+	return configSection{
+		name:   name,
+		fields: configFields(reflect.TypeFor[T](), ""),
+		load: func(provider salomeconfig.Provider) error {
+			_, err := salomeconfig.LoadConfigTo[T](provider)
+			return err
+		},
+	}
+}
+
+// configFields lists the config keys of the struct type t from its env tags.
+func configFields(t reflect.Type, prefix string) []configField {
+	var fields []configField
+	for i := range t.NumField() {
+		field := t.Field(i)
+		tag, ok := field.Tag.Lookup("env")
+		if !ok {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(tag, ",")
+		var f configField
+		for opt := range strings.SplitSeq(opts, ",") {
+			key, value, _ := strings.Cut(strings.TrimSpace(opt), "=")
+			switch key {
+			case "prefix":
+				if field.Type.Kind() == reflect.Struct {
+					fields = append(fields, configFields(field.Type, prefix+value)...)
+				}
+			case "default":
+				f.defaultValue = value
+			}
+		}
+
+		if name = strings.TrimSpace(name); name != "" {
+			f.key = prefix + name
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+type configReport struct {
+	Sections []configSectionReport `json:"sections"`
+}
+
+type configSectionReport struct {
+	Name   string        `json:"name"`
+	OK     bool          `json:"ok"`
+	Error  string        `json:"error,omitempty"`
+	Values []configValue `json:"values"`
+}
+
+type configValue struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+	// Default is true if the key is not set, so the value is the default one.
+	Default bool `json:"default,omitempty"`
+}
+
+func runConfigCheck(ctx context.Context, c *cli, _ []string) error {
+	var report configReport
+	err := c.exec(ctx, fx.Invoke(func(provider salomeconfig.Provider) error {
+		raw, err := provider.Config(ctx)
+		if err != nil {
+			return fmt.Errorf("provider.Config: %w", err)
+		}
+
+		for _, section := range configSections {
+			report.Sections = append(report.Sections, checkConfigSection(provider, raw, section))
+		}
+		return nil
+	}))
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+
+	if err := c.print(&report); err != nil {
+		return err
+	}
+
+	for _, section := range report.Sections {
+		if !section.OK {
+			return errSilent
+		}
+	}
+
+	return nil
+}
+
+func checkConfigSection(provider salomeconfig.Provider, raw map[string]string, section configSection) configSectionReport {
+	res := configSectionReport{Name: section.name, OK: true, Values: []configValue{}}
+
+	var secrets []string
+	for _, field := range section.fields {
+		value, ok := raw[field.key]
+		if !ok || value == "" {
+			res.Values = append(res.Values, configValue{Key: field.key, Value: field.defaultValue, Default: true})
+			continue
+		}
+
+		if isSecretKey(field.key) {
+			secrets = append(secrets, value)
+			value = redacted
+		}
+		res.Values = append(res.Values, configValue{Key: field.key, Value: value})
+	}
+
+	if err := section.load(provider); err != nil {
+		res.OK = false
+		// the error may contain the invalid value.
+		res.Error = err.Error()
+		for _, secret := range secrets {
+			res.Error = strings.ReplaceAll(res.Error, secret, redacted)
+		}
+	}
+
+	return res
+}
+
+func isSecretKey(key string) bool {
+	for _, part := range secretKeyParts {
+		if strings.Contains(key, part) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *configReport) RenderText(w io.Writer, _ plaintext.Options) error {
+	for i, section := range r.Sections {
+		if i > 0 {
+			_, _ = fmt.Fprintln(w)
+		}
+
+		status := "ok"
+		if !section.OK {
+			status = "error: " + section.Error
+		}
+		_, _ = fmt.Fprintf(w, "%s: %s\n", section.Name, status)
+
+		for _, value := range section.Values {
+			switch {
+			case !value.Default:
+				_, _ = fmt.Fprintf(w, "  %s=%s\n", value.Key, value.Value)
+			case value.Value != "":
+				_, _ = fmt.Fprintf(w, "  %s (not set, defaults to %s)\n", value.Key, value.Value)
+			default:
+				_, _ = fmt.Fprintf(w, "  %s (not set)\n", value.Key)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/mattn/go-isatty"
+	"github.com/raf555/kbbi-api/internal/dictionary"
+)
+
+var exportCommand = cliCommand{
+	name:  "export",
+	usage: "export [flags] [lemma...]",
+	description: "Export the entries of the given lemmas, e.g. kbbi export apel anak --output words.jsonl\n" +
+		"The lemmas are read from stdin, one per line, if none is given.",
+	args: anyArgs,
+	flags: func(c *cli, fs *flag.FlagSet) {
+		c.outputFlags(fs, "jsonl", "json", "text")
+		fs.String("output", "", "write to the file instead of stdout")
+	},
+	run: withDictionary(runExport),
+}
+
+func runExport(c *cli, dict dictionary.DictionaryRepo, args []string) error {
+	lemmas := args
+	if len(lemmas) == 0 {
+		if isatty.IsTerminal(os.Stdin.Fd()) {
+			c.flags.Usage()
+			return usageErrorf("export: no lemma is given")
+		}
+
+		var err error
+		if lemmas, err = readLemmas(os.Stdin); err != nil {
+			return fmt.Errorf("export: read lemmas: %w", err)
+		}
+	}
+
+	out := c.stdout
+	if output := c.flags.Lookup("output").Value.String(); output != "" {
+		f, err := os.Create(output)
+		if err != nil {
+			return fmt.Errorf("export: %w", err)
+		}
+		defer func() {
+			_ = f.Close()
+		}()
+
+		out = f
+		c.textOpts.Color = false
+	}
+
+	w := bufio.NewWriter(out)
+
+	var (
+		results  = make([]*dictionary.EntryResponse, 0, len(lemmas))
+		notFound int
+	)
+	for _, lemma := range lemmas {
+		entryNo := 0
+		if newLemma, no, ok := dictionary.FindEntryNoFromLemma(lemma); ok {
+			lemma, entryNo = newLemma, no
+		}
+
+		result, err := dict.Lookup(lemma, entryNo)
+		if err != nil {
+			if errors.Is(err, dictionary.ErrLemmaNotFound) || errors.Is(err, dictionary.ErrEntryNotFound) {
+				notFound++
+				_, _ = fmt.Fprintf(c.stderr, "kbbi: export: %s: %s\n", lemma, err)
+				continue
+			}
+			return fmt.Errorf("export: %s: %w", lemma, err)
+		}
+
+		res := newEntryResponse(result)
+		switch c.format {
+		case "json":
+			// written at once as an array.
+			results = append(results, res)
+		case "jsonl":
+			err = json.NewEncoder(w).Encode(res)
+		case "text":
+			if err = res.RenderText(w, c.textOpts); err == nil {
+				_, err = fmt.Fprintln(w)
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("export: %s: %w", lemma, err)
+		}
+	}
+
+	if c.format == "json" {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(results); err != nil {
+			return fmt.Errorf("export: %w", err)
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("export: %w", err)
+	}
+
+	if notFound > 0 {
+		return fmt.Errorf("export: %d of %d lemma(s) not found", notFound, len(lemmas))
+	}
+
+	return nil
+}
+
+// readLemmas reads the lemmas from r, one per line. Empty lines are skipped.
+func readLemmas(r io.Reader) ([]string, error) {
+	var lemmas []string
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if lemma := strings.TrimSpace(scanner.Text()); lemma != "" {
+			lemmas = append(lemmas, lemma)
+		}
+	}
+
+	return lemmas, scanner.Err()
+}
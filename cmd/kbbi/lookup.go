@@ -0,0 +1,111 @@
+package main
+
+import (
+	"errors"
+	"flag"
+
+	"github.com/raf555/kbbi-api/internal/dictionary"
+)
+
+// maxSuggestions is the maximum number of suggested lemmas shown when a lemma is not found.
+const maxSuggestions = 5
+
+// dictionaryCommands read the dictionary directly from the assets without running the server.
+var dictionaryCommands = []cliCommand{
+	{
+		name:        "lookup",
+		usage:       "lookup [flags] <lemma>",
+		description: "Show the information of the lemma, e.g. kbbi lookup apel",
+		args:        1,
+		flags: func(c *cli, fs *flag.FlagSet) {
+			c.outputFlags(fs, "text", "json")
+			fs.IntVar(&c.entryNo, "entry", 0, "lemma's entry number, starts from 1")
+		},
+		run: withDictionary(func(c *cli, dict dictionary.DictionaryRepo, args []string) error {
+			entryNo, lemma := c.entryNo, args[0]
+			if newLemma, no, ok := dictionary.FindEntryNoFromLemma(lemma); ok {
+				lemma, entryNo = newLemma, no
+			}
+
+			result, err := dict.Lookup(lemma, entryNo)
+			if err != nil {
+				if errors.Is(err, dictionary.ErrLemmaNotFound) {
+					return c.notFound(dict.Suggest(lemma, maxSuggestions))
+				}
+				return err
+			}
+
+			return c.print(newEntryResponse(result))
+		}),
+	},
+	{
+		name:        "search",
+		usage:       "search [flags] <prefix>",
+		description: "List the lemmas which start with the prefix, e.g. kbbi search ap --limit 20",
+		args:        1,
+		flags: func(c *cli, fs *flag.FlagSet) {
+			c.outputFlags(fs, "text", "json")
+			fs.UintVar(&c.limit, "limit", 10, "maximum number of lemmas to be shown")
+		},
+		run: withDictionary(func(c *cli, dict dictionary.DictionaryRepo, args []string) error {
+			lemmas, err := dict.Search(args[0], c.limit)
+			if err != nil {
+				return err
+			}
+			res := &dictionary.SearchResponse{Lemmas: make([]string, 0, len(lemmas))}
+			for _, lemma := range lemmas {
+				res.Lemmas = append(res.Lemmas, lemma.Lemma)
+			}
+
+			return c.print(res)
+		}),
+	},
+	{
+		name:        "random",
+		usage:       "random [flags]",
+		description: "Show a random lemma",
+		flags: func(c *cli, fs *flag.FlagSet) {
+			c.outputFlags(fs, "text", "json")
+		},
+		run: withDictionary(func(c *cli, dict dictionary.DictionaryRepo, _ []string) error {
+			lemma, err := dict.RandomLemma()
+			if err != nil {
+				return err
+			}
+			return c.print(&dictionary.EntryResponse{Lemma: lemma})
+		}),
+	},
+	{
+		name:        "wotd",
+		usage:       "wotd [flags]",
+		description: "Show the word of the day",
+		flags: func(c *cli, fs *flag.FlagSet) {
+			c.outputFlags(fs, "text", "json")
+		},
+		run: withDictionary(func(c *cli, dict dictionary.DictionaryRepo, _ []string) error {
+			lemma, err := dict.LemmaOfTheDay()
+			if err != nil {
+				return err
+			}
+			return c.print(&dictionary.EntryResponse{Lemma: lemma})
+		}),
+	},
+	{
+		name:        "repl",
+		usage:       "repl [flags]",
+		description: "Start an interactive shell, type :help in the shell to show its commands",
+		flags: func(c *cli, fs *flag.FlagSet) {
+			c.outputFlags(fs, "text", "json")
+		},
+		run: withDictionary(runREPL),
+	},
+}
+
+func newEntryResponse(result dictionary.LookupResult) *dictionary.EntryResponse {
+	return &dictionary.EntryResponse{
+		Lemma:         result.Lemma,
+		StandardForm:  result.StandardForm,
+		Variant:       result.Variant,
+		Reduplication: result.Reduplication,
+	}
+}
@@ -2,23 +2,17 @@ package main
 
 import (
 	"context"
-
-	"github.com/raf555/kbbi-api/cmd/cmdfx"
-	"github.com/raf555/kbbi-api/internal/dictionary/dictionaryfx"
-	"github.com/raf555/kbbi-api/internal/home/homefx"
-	httpfx "github.com/raf555/kbbi-api/internal/http/fx"
-	"github.com/raf555/kbbi-api/internal/swagger/swaggerfx"
+	"os"
+	"strings"
 )
 
 func main() {
-	err := cmdfx.Run(context.TODO(),
-		dictionaryfx.Module,
-		homefx.Module,
-		swaggerfx.Module,
-		httpfx.ServerInvoker,
-	)
+	args := os.Args[1:]
 
-	if err != nil {
-		panic("main: " + err.Error())
+	// serve is the default command, e.g. `kbbi` or `kbbi --env-file prod.env`.
+	if len(args) == 0 || (strings.HasPrefix(args[0], "-") && !isHelp(args[0])) {
+		args = append([]string{serveCommand.name}, args...)
 	}
+
+	os.Exit(runCLI(context.TODO(), args))
 }
@@ -0,0 +1,328 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/peterh/liner"
+	"github.com/raf555/kbbi-api/internal/dictionary"
+	"github.com/raf555/kbbi-api/internal/plaintext"
+	"github.com/raf555/kbbi-api/pkg/kbbi"
+)
+
+// maxCompletions is the maximum number of lemmas suggested by the tab completion.
+const maxCompletions = 20
+
+// repl is an interactive shell to explore the dictionary.
+// The dictionary is loaded once for the whole session.
+type repl struct {
+	cli  *cli
+	dict dictionary.DictionaryRepo
+
+	// current is the last shown lemma, used by the commands such as :entry and :family.
+	current kbbi.Lemma
+}
+
+type replCommand struct {
+	usage string
+	run   func(r *repl, arg string) error
+}
+
+var replCommands = map[string]replCommand{
+	":entry": {
+		usage: ":entry <n>\tshow the n-th entry of the current lemma",
+		run:   (*repl).entry,
+	},
+	":family": {
+		usage: ":family\tshow the base, derived, and compound words of the current lemma",
+		run:   (*repl).family,
+	},
+	":search": {
+		usage: ":search <prefix>\tlist the lemmas which start with the prefix",
+		run:   (*repl).search,
+	},
+	":random": {
+		usage: ":random\tshow a random lemma",
+		run: func(r *repl, _ string) error {
+			lemma, err := r.dict.RandomLemma()
+			if err != nil {
+				return err
+			}
+			return r.show(kbbi.Lemma{}, &dictionary.EntryResponse{Lemma: lemma})
+		},
+	},
+	":wotd": {
+		usage: ":wotd\tshow the word of the day",
+		run: func(r *repl, _ string) error {
+			lemma, err := r.dict.LemmaOfTheDay()
+			if err != nil {
+				return err
+			}
+			return r.show(kbbi.Lemma{}, &dictionary.EntryResponse{Lemma: lemma})
+		},
+	},
+	":quit": {
+		usage: ":quit\texit the shell, or press Ctrl+D",
+	},
+}
+
+func init() {
+	// registered here since :help refers to replCommands.
+	replCommands[":help"] = replCommand{
+		usage: ":help\tshow this help",
+		run:   (*repl).help,
+	}
+}
+
+func runREPL(cli *cli, dict dictionary.DictionaryRepo, _ []string) error {
+	r := &repl{cli: cli, dict: dict}
+
+	line := liner.NewLiner()
+	defer func() {
+		_ = line.Close()
+	}()
+
+	line.SetCtrlCAborts(true)
+	line.SetTabCompletionStyle(liner.TabPrints)
+	line.SetCompleter(r.complete)
+
+	historyPath := replHistoryPath()
+	if f, err := os.Open(historyPath); err == nil {
+		_, _ = line.ReadHistory(f)
+		_ = f.Close()
+	}
+	defer func() {
+		if err := os.MkdirAll(filepath.Dir(historyPath), 0o755); err != nil {
+			return
+		}
+		if f, err := os.Create(historyPath); err == nil {
+			_, _ = line.WriteHistory(f)
+			_ = f.Close()
+		}
+	}()
+
+	_, _ = fmt.Fprintln(cli.stdout, "Type a lemma to look it up, :help to show the commands, or Ctrl+D to exit.")
+
+	for {
+		input, err := line.Prompt("kbbi> ")
+		if errors.Is(err, liner.ErrPromptAborted) {
+			continue
+		}
+		if errors.Is(err, io.EOF) {
+			_, _ = fmt.Fprintln(cli.stdout)
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("line.Prompt: %w", err)
+		}
+
+		input = strings.TrimSpace(input)
+		if input == "" {
+			continue
+		}
+		line.AppendHistory(input)
+
+		if input == ":quit" || input == ":q" {
+			return nil
+		}
+
+		if err := r.eval(input); err != nil && !errors.Is(err, errSilent) {
+			_, _ = fmt.Fprintf(cli.stderr, "error: %s\n", err)
+		}
+	}
+}
+
+func (r *repl) eval(input string) error {
+	if !strings.HasPrefix(input, ":") {
+		return r.lookup(input, 0)
+	}
+
+	name, arg, _ := strings.Cut(input, " ")
+	cmd, ok := replCommands[name]
+	if !ok || cmd.run == nil {
+		return fmt.Errorf("unknown command %s, type :help to show the commands", name)
+	}
+
+	return cmd.run(r, strings.TrimSpace(arg))
+}
+
+func (r *repl) lookup(lemma string, entryNo int) error {
+	if newLemma, no, ok := dictionary.FindEntryNoFromLemma(lemma); ok {
+		lemma, entryNo = newLemma, no
+	}
+
+	result, err := r.dict.Lookup(lemma, entryNo)
+	if err != nil {
+		if errors.Is(err, dictionary.ErrLemmaNotFound) {
+			return r.cli.notFound(r.dict.Suggest(lemma, maxSuggestions))
+		}
+		return err
+	}
+
+	// :entry should refer to the whole lemma instead of the shown entry.
+	current := result.Lemma
+	if entryNo > 0 {
+		if current, err = r.dict.Lemma(result.Lemma.Lemma, 0); err != nil {
+			return err
+		}
+	}
+
+	return r.show(current, newEntryResponse(result))
+}
+
+// show prints res and sets current to the shown lemma. If current is empty, res.Lemma is used instead.
+func (r *repl) show(current kbbi.Lemma, res *dictionary.EntryResponse) error {
+	if current.Lemma == "" {
+		current = res.Lemma
+	}
+	r.current = current
+	return r.cli.print(res)
+}
+
+func (r *repl) entry(arg string) error {
+	if r.current.Lemma == "" {
+		return errors.New("no lemma is shown yet")
+	}
+
+	entryNo, err := strconv.Atoi(arg)
+	if err != nil || entryNo < 1 {
+		return fmt.Errorf("invalid entry number %q", arg)
+	}
+
+	return r.lookup(r.current.Lemma, entryNo)
+}
+
+func (r *repl) family(string) error {
+	if r.current.Lemma == "" {
+		return errors.New("no lemma is shown yet")
+	}
+
+	base := r.current
+	if baseWord := firstBaseWord(r.current); baseWord != "" {
+		if lemma, err := r.dict.Lemma(baseWord, 0); err == nil {
+			base = lemma
+		}
+	}
+
+	family := &wordFamily{Base: base.Lemma}
+	for _, entry := range base.Entries {
+		family.DerivedWords = appendUnique(family.DerivedWords, entry.DerivedWords...)
+		family.CompoundWords = appendUnique(family.CompoundWords, entry.CompoundWords...)
+	}
+
+	return r.cli.print(family)
+}
+
+func (r *repl) search(arg string) error {
+	if arg == "" {
+		return errors.New("prefix is required")
+	}
+
+	lemmas, err := r.dict.Search(arg, maxCompletions)
+	if err != nil {
+		return err
+	}
+
+	res := &dictionary.SearchResponse{}
+	for _, lemma := range lemmas {
+		res.Lemmas = append(res.Lemmas, lemma.Lemma)
+	}
+
+	return r.cli.print(res)
+}
+
+func (r *repl) help(string) error {
+	names := make([]string, 0, len(replCommands))
+	for name := range replCommands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	usages := []string{"<lemma>\tlook up the lemma, e.g. apel or apel (2)"}
+	for _, name := range names {
+		usages = append(usages, replCommands[name].usage)
+	}
+
+	tw := tabwriter.NewWriter(r.cli.stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, strings.Join(usages, "\n"))
+	return tw.Flush()
+}
+
+// complete completes the command names, or the lemmas from the dictionary search.
+func (r *repl) complete(line string) []string {
+	if strings.HasPrefix(line, ":") {
+		var candidates []string
+		for name := range replCommands {
+			if strings.HasPrefix(name, line) {
+				candidates = append(candidates, name)
+			}
+		}
+		sort.Strings(candidates)
+		return candidates
+	}
+
+	if strings.TrimSpace(line) == "" {
+		return nil
+	}
+
+	// the completion has no way to show the error, which is shown once the line is looked up anyway.
+	lemmas, _ := r.dict.Search(line, maxCompletions)
+
+	var candidates []string
+	for _, lemma := range lemmas {
+		candidates = append(candidates, lemma.Lemma)
+	}
+	return candidates
+}
+
+// wordFamily is the base word of a lemma along with its derived and compound words.
+type wordFamily struct {
+	Base          string   `json:"base"`
+	DerivedWords  []string `json:"derivedWords"`
+	CompoundWords []string `json:"compoundWords"`
+}
+
+func (f *wordFamily) RenderText(w io.Writer, opts plaintext.Options) error {
+	lines := []string{"Kata dasar: " + f.Base}
+	if len(f.DerivedWords) > 0 {
+		lines = append(lines, "Kata turunan: "+strings.Join(f.DerivedWords, "; "))
+	}
+	if len(f.CompoundWords) > 0 {
+		lines = append(lines, "Gabungan kata: "+strings.Join(f.CompoundWords, "; "))
+	}
+	return plaintext.RenderList(w, lines, opts)
+}
+
+func firstBaseWord(lemma kbbi.Lemma) string {
+	for _, entry := range lemma.Entries {
+		if entry.BaseWord != "" {
+			return entry.BaseWord
+		}
+	}
+	return ""
+}
+
+func appendUnique(words []string, newWords ...string) []string {
+	for _, word := range newWords {
+		if !slices.Contains(words, word) {
+			words = append(words, word)
+		}
+	}
+	return words
+}
+
+func replHistoryPath() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "kbbi", "repl_history")
+}
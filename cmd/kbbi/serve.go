@@ -0,0 +1,32 @@
+package main
+
+import (
+	"context"
+
+	"github.com/raf555/kbbi-api/cmd/cmdfx"
+	"github.com/raf555/kbbi-api/internal/dictionary/dictionaryfx"
+	"github.com/raf555/kbbi-api/internal/home/homefx"
+	httpfx "github.com/raf555/kbbi-api/internal/http/fx"
+	"github.com/raf555/kbbi-api/internal/swagger/swaggerfx"
+	"github.com/raf555/kbbi-api/internal/text/textfx"
+	"github.com/raf555/kbbi-api/internal/web/webfx"
+	"go.uber.org/fx"
+)
+
+var serveCommand = cliCommand{
+	name:        "serve",
+	usage:       "serve [flags]",
+	description: "Run the API server, this is the default command",
+	run: func(ctx context.Context, c *cli, _ []string) error {
+		return cmdfx.Run(ctx,
+			fx.Supply(c.source()),
+			dictionaryfx.Module,
+			dictionaryfx.ReloadInvoker,
+			homefx.Module,
+			textfx.Module,
+			webfx.Module,
+			swaggerfx.Module,
+			httpfx.ServerInvoker,
+		)
+	},
+}
@@ -18,6 +18,14 @@ type infisicalConfig struct {
 	Environment string `env:"INFISICAL_ENVIRONMENT" validate:"required_with=SiteUrl"`
 }
 
+type providerParams struct {
+	fx.In
+
+	InfisicalCfg infisicalConfig
+	LC           fx.Lifecycle
+	Source       Source `optional:"true"`
+}
+
 var Module = fx.Module("config",
 	fx.Provide(
 		fx.Annotate(
@@ -36,33 +44,17 @@ var Module = fx.Module("config",
 	),
 
 	fx.Provide(
-		func(infisicalCfg infisicalConfig, lc fx.Lifecycle) (salomeconfig.Provider, error) {
-			if infisicalCfg.SiteUrl != "" { // load from cloud if provided
-				infCfg, err := infisical.NewWithOptions(infisicalCfg.SiteUrl, infisical.SecretConfig{
-					ProjectSlug: infisicalCfg.ProjectSlug,
-					Environment: infisicalCfg.Environment,
-					ConfigPath:  "",
-				},
-					infisical.WithKubernetesAuth(infisicalCfg.IdentityID, ""),
-				)
-				if err != nil {
-					return nil, fmt.Errorf("infisical new: %w", err)
-				}
-
-				lc.Append(fx.StopHook(func() {
-					infCfg.Close()
-				}))
-
-				return infCfg, nil
+		func(params providerParams) (salomeconfig.Provider, error) {
+			provider, err := newProvider(params)
+			if err != nil {
+				return nil, err
 			}
 
-			// local env
-			defaultCfg, err := osdotenv.New(".env")
-			if err != nil {
-				return nil, fmt.Errorf("osdotenv.New: %w", err)
+			if len(params.Source.Overrides) > 0 {
+				return &overrideProvider{Provider: provider, overrides: params.Source.Overrides}, nil
 			}
 
-			return defaultCfg, nil
+			return provider, nil
 		},
 	),
 
@@ -84,3 +76,32 @@ var Module = fx.Module("config",
 
 	fx.Provide(salomeconfig.LoadConfigTo[config.ServerConfig]),
 )
+
+func newProvider(params providerParams) (salomeconfig.Provider, error) {
+	if infisicalCfg := params.InfisicalCfg; infisicalCfg.SiteUrl != "" { // load from cloud if provided
+		infCfg, err := infisical.NewWithOptions(infisicalCfg.SiteUrl, infisical.SecretConfig{
+			ProjectSlug: infisicalCfg.ProjectSlug,
+			Environment: infisicalCfg.Environment,
+			ConfigPath:  "",
+		},
+			infisical.WithKubernetesAuth(infisicalCfg.IdentityID, ""),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("infisical new: %w", err)
+		}
+
+		params.LC.Append(fx.StopHook(func() {
+			infCfg.Close()
+		}))
+
+		return infCfg, nil
+	}
+
+	// local env
+	defaultCfg, err := osdotenv.New(params.Source.envFile())
+	if err != nil {
+		return nil, fmt.Errorf("osdotenv.New: %w", err)
+	}
+
+	return defaultCfg, nil
+}
@@ -0,0 +1,57 @@
+package configfx
+
+import (
+	"context"
+	"maps"
+
+	salomeconfig "github.com/raf555/salome/config/v1"
+)
+
+// defaultEnvFile is the dotenv file read when the config is loaded locally.
+const defaultEnvFile = ".env"
+
+// Source optionally configures where the config is loaded from, e.g. from the command line flags.
+type Source struct {
+	// EnvFile is the dotenv file read when the config is loaded locally. Empty means .env.
+	EnvFile string
+	// Overrides takes precedence over the config loaded from any provider.
+	Overrides map[string]string
+}
+
+func (s Source) envFile() string {
+	if s.EnvFile == "" {
+		return defaultEnvFile
+	}
+	return s.EnvFile
+}
+
+// overrideProvider wraps a provider so that the overrides take precedence over its config.
+type overrideProvider struct {
+	salomeconfig.Provider
+
+	overrides map[string]string
+}
+
+func (p *overrideProvider) Config(ctx context.Context) (map[string]string, error) {
+	cfg, err := p.Provider.Config(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return p.apply(cfg), nil
+}
+
+func (p *overrideProvider) FetchConfig(ctx context.Context) (map[string]string, error) {
+	cfg, err := p.Provider.FetchConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return p.apply(cfg), nil
+}
+
+func (p *overrideProvider) apply(cfg map[string]string) map[string]string {
+	if cfg == nil {
+		cfg = make(map[string]string, len(p.overrides))
+	}
+	maps.Copy(cfg, p.overrides)
+	return cfg
+}
@@ -0,0 +1,84 @@
+package dictionary
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/raf555/kbbi-api/internal/http/httperr"
+	"github.com/raf555/kbbi-api/internal/http/httphandler"
+	"github.com/raf555/kbbi-api/pkg/kbbi"
+)
+
+var (
+	msgUnauthorized = httphandler.Messages{
+		httphandler.LocaleEnglish:    "invalid or missing token",
+		httphandler.LocaleIndonesian: "token tidak valid atau tidak ada",
+	}
+	msgReloadInProgress = httphandler.Messages{
+		httphandler.LocaleEnglish:    "the dictionary is already reloading",
+		httphandler.LocaleIndonesian: "kamus sedang dimuat ulang",
+	}
+	msgReloadFailed = httphandler.Messages{
+		httphandler.LocaleEnglish:    "failed to reload the dictionary, the current dictionary is kept",
+		httphandler.LocaleIndonesian: "gagal memuat ulang kamus, kamus yang sekarang tetap digunakan",
+	}
+)
+
+// AdminHTTPHandler serves the admin endpoints, which are only registered if [Configuration.ReloadToken] is set.
+// They are not documented in the swagger since they are not part of the public API.
+type AdminHTTPHandler struct {
+	reloader *Reloader
+	token    string
+}
+
+func NewAdminHTTPHandler(cfg Configuration, reloader *Reloader) *AdminHTTPHandler {
+	return &AdminHTTPHandler{
+		reloader: reloader,
+		token:    cfg.ReloadToken,
+	}
+}
+
+func (h *AdminHTTPHandler) MustRegisterRoutes(g *gin.Engine) {
+	if h.token == "" {
+		return
+	}
+
+	g.POST("/admin/reload",
+		httphandler.MakeHandler(
+			h.Reload,
+			httphandler.DefaultRequestBinder,
+		),
+	)
+}
+
+// Reload reloads the dictionary assets, and responds once the new dictionary is swapped in.
+func (h *AdminHTTPHandler) Reload(ctx context.Context, req *ReloadRequest) (*ReloadResult, error) {
+	token, ok := strings.CutPrefix(req.Authorization, "Bearer ")
+	if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(h.token)) != 1 {
+		return nil, httperr.WithCode(
+			httperr.New(http.StatusUnauthorized, msgUnauthorized.In(ctx)),
+			string(kbbi.ErrorCodeUnauthorized),
+		)
+	}
+
+	// the reload is not canceled if the client disconnects, so it is never left half-done.
+	// each download attempt already has its own timeout, see [Configuration.DownloadTimeout].
+	res, err := h.reloader.Reload(context.WithoutCancel(ctx), "admin endpoint")
+	if err != nil {
+		wrappedErr := fmt.Errorf("h.reloader.Reload: %w", err)
+		if errors.Is(err, ErrReloadInProgress) {
+			return nil, httperr.Wrap(wrappedErr, http.StatusConflict, msgReloadInProgress.In(ctx))
+		}
+		return nil, httperr.WithCode(
+			httperr.Wrap(wrappedErr, http.StatusInternalServerError, msgReloadFailed.In(ctx)),
+			string(kbbi.ErrorCodeReloadFailed),
+		)
+	}
+
+	return &res, nil
+}
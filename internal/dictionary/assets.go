@@ -4,15 +4,15 @@ import (
 	"bytes"
 	"compress/gzip"
 	"context"
-	"crypto/aes"
-	"crypto/cipher"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"net/http"
+	"log/slog"
 	"os"
 	"path"
-	"time"
 )
 
 type (
@@ -20,94 +20,395 @@ type (
 		filename, dir string
 		url           string
 
-		key, nonce []byte
+		keys Keyring
+		// nonce is only used for the legacy asset without envelope.
+		nonce []byte
+
+		// kind is the expected kind of the asset, its content type is checked by To if it is set.
+		kind AssetKind
+		// publicKeys verify the signature of the asset before it is decrypted, if it is set.
+		publicKeys PublicKeys
+		// signatureURL is the URL of the detached signature, defaults to the asset URL with [SignatureSuffix].
+		signatureURL string
+		// envelope is the header of the asset which is read by To, nil for the legacy asset.
+		envelope *EnvelopeHeader
+		// key is the key which decrypts the asset read by To.
+		key Key
+		// signedBy is the public key which verifies the asset read by To, nil if it is not verified.
+		signedBy *Key
+		// contentSHA256 is the hex encoded SHA-256 of the JSON content read by To.
+		contentSHA256 string
+		// assetSource is where the asset read by To is read from.
+		assetSource AssetSource
+
+		ctx context.Context
+		// downloader downloads the asset and its detached signature from the URL, a new one is used if it is nil.
+		downloader *downloader
 	}
 )
 
-func ReadAsset(filename, dir string, key, nonce []byte) *reader {
-	return &reader{filename, dir, "", key, nonce}
+func ReadAsset(filename, dir string, keys Keyring, nonce []byte) *reader {
+	return &reader{filename: filename, dir: dir, keys: keys, nonce: nonce, ctx: context.Background()}
+}
+
+func ReadAssetFromURL(url string, keys Keyring, nonce []byte) *reader {
+	return &reader{url: url, keys: keys, nonce: nonce, ctx: context.Background()}
+}
+
+// WithContext makes To stop downloading the asset once ctx is done.
+func (r *reader) WithContext(ctx context.Context) *reader {
+	r.ctx = ctx
+	return r
+}
+
+// Expect makes To reject the asset whose envelope has a different content type than the kind.
+func (r *reader) Expect(kind AssetKind) *reader {
+	r.kind = kind
+	return r
 }
 
-func ReadAssetFromURL(url string, key, nonce []byte) *reader {
-	return &reader{"", "", url, key, nonce}
+// Verify makes To verify the signature of the asset with the public keys before decrypting it.
+// The signature is either embedded in the asset, or detached next to it, see [VerifyAsset].
+func (r *reader) Verify(keys PublicKeys, signatureURL string) *reader {
+	r.publicKeys, r.signatureURL = keys, signatureURL
+	return r
 }
 
 func (r *reader) To(target any) error {
-	var (
-		ciphertext []byte
-		err        error
-	)
+	return r.stream(func(content io.Reader) error {
+		if err := json.NewDecoder(content).Decode(target); err != nil {
+			return fmt.Errorf("json.NewDecoder.Decode: %w", err)
+		}
+		return nil
+	})
+}
+
+// stream calls fn with the JSON content of the asset as it is decrypted and decompressed.
+// The content left unread by fn is drained afterward, since the last chunk and the gzip checksum are only checked
+// at the end. Whatever fn builds from the content must be discarded if stream fails.
+func (r *reader) stream(fn func(content io.Reader) error) error {
+	content, err := r.open(r.ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = content.Close()
+	}()
+
+	hash := sha256.New()
+	if err := fn(io.TeeReader(content, hash)); err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(hash, content); err != nil {
+		return fmt.Errorf("read: %w", err)
+	}
+	r.contentSHA256 = hex.EncodeToString(hash.Sum(nil))
+
+	return nil
+}
+
+// open verifies the signature of the asset if the public keys are set, and returns the stream of its JSON content.
+func (r *reader) open(ctx context.Context) (io.ReadCloser, error) {
+	src, err := r.source(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := r.openSource(src)
+	if err != nil {
+		_ = src.Close()
+		return nil, err
+	}
+
+	return content, nil
+}
 
-	if r.url != "" {
-		ciphertext, err = r.getCiphertextFromURL(context.TODO())
+func (r *reader) openSource(src io.ReadCloser) (io.ReadCloser, error) {
+	compressed, envelope, key, err := openEnvelopeStream(src, r.keys, r.nonce)
+	if err != nil {
+		return nil, fmt.Errorf("open: %w", err)
+	}
+	r.envelope, r.key = envelope, key
+
+	// checked before decoding, otherwise the mismatched asset fails with a confusing JSON error.
+	if envelope != nil && r.kind != "" && envelope.ContentType != r.kind.ContentType() {
+		return nil, fmt.Errorf("%w: content type %q, expected %q", ErrUnsupportedEnvelope, envelope.ContentType, r.kind.ContentType())
+	}
+
+	gz, err := gzip.NewReader(compressed)
+	if err != nil {
+		return nil, fmt.Errorf("gzip.NewReader: %w", err)
+	}
+
+	return &assetStream{Reader: gz, closers: []io.Closer{gz, src}}, nil
+}
+
+// source opens the encrypted asset, and verifies its signature if the public keys are set.
+func (r *reader) source(ctx context.Context) (io.ReadCloser, error) {
+	var src io.ReadCloser
+	if r.url == "" {
+		f, err := os.Open(path.Join(r.dir, r.filename))
+		if err != nil {
+			return nil, fmt.Errorf("get ciphertext: os.Open: %w", err)
+		}
+		src, r.assetSource = f, AssetSourceFile
 	} else {
-		ciphertext, err = r.getCiphertextFromFile()
+		body, source, err := r.download(ctx, r.url)
+		if err != nil {
+			return nil, fmt.Errorf("get ciphertext: %w", err)
+		}
+		src, r.assetSource = body, source
+	}
+
+	if len(r.publicKeys) == 0 {
+		return src, nil
 	}
+
+	defer func() {
+		_ = src.Close()
+	}()
+
+	verified, signedBy, err := r.verify(src)
 	if err != nil {
-		return fmt.Errorf("get ciphertext: %w", err)
+		return nil, fmt.Errorf("verify: %w", err)
+	}
+	r.signedBy = &signedBy
+
+	return verified, nil
+}
+
+// Envelope returns the header of the asset read by To. ok is false for the legacy asset without envelope.
+func (r *reader) Envelope() (header EnvelopeHeader, ok bool) {
+	if r.envelope == nil {
+		return EnvelopeHeader{}, false
 	}
+	return *r.envelope, true
+}
 
-	plaintext, err := r.decrypt(r.key, r.nonce, ciphertext)
+// logEnvelope logs the envelope of the asset read by To, and the key which decrypts it.
+func (r *reader) logEnvelope(logger *slog.Logger) {
+	logger = logger.With(
+		slog.String("kind", string(r.kind)),
+		slog.String("decrypted_with_key_id", r.key.ID),
+		slog.String("decrypted_with_key_fingerprint", KeyFingerprint(r.key.Key)),
+		slog.String("content_sha256", r.contentSHA256),
+		slog.String("source", string(r.assetSource)),
+	)
+	if r.signedBy != nil {
+		logger = logger.With(slog.String("signed_by_key", r.signedBy.String()))
+	}
+
+	header, ok := r.Envelope()
+	if !ok {
+		logger.Warn("reading legacy asset without envelope")
+		return
+	}
+
+	logger.Info("read asset envelope",
+		slog.Int("version", header.Version),
+		slog.String("key_id", header.KeyID),
+		slog.String("edition", header.Edition),
+		slog.Time("created_at", header.CreatedAt),
+	)
+}
+
+// OpenAsset decrypts and decompresses the asset into its JSON content.
+func OpenAsset(ciphertext []byte, keys Keyring, nonce []byte) ([]byte, error) {
+	compressed, _, _, err := openEnvelope(ciphertext, keys, nonce)
 	if err != nil {
-		return fmt.Errorf("r.decrypt: %w", err)
+		return nil, fmt.Errorf("open: %w", err)
 	}
 
-	reader := bytes.NewReader(plaintext)
-	gz, err := gzip.NewReader(reader)
+	return gunzip(compressed)
+}
+
+func gunzip(compressed []byte) ([]byte, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(compressed))
 	if err != nil {
-		return fmt.Errorf("gzip.NewReader: %w", err)
+		return nil, fmt.Errorf("gzip.NewReader: %w", err)
 	}
+	defer func() {
+		_ = gz.Close()
+	}()
 
-	if err = json.NewDecoder(gz).Decode(target); err != nil {
-		return fmt.Errorf("json.NewDecoder.Decode: %w", err)
+	content, err := io.ReadAll(gz)
+	if err != nil {
+		return nil, fmt.Errorf("gunzip: %w", err)
 	}
 
-	_ = gz.Close()
-	return nil
+	return content, nil
+}
+
+// WriteAsset encodes source as JSON, compresses it with gzip, and encrypts it with AES-GCM into w, wrapped in an envelope
+// with the header. The content is encrypted in chunks as it is encoded, and the nonce of the header is always generated.
+// It is the inverse of [ReadAsset]. It returns the hex encoded SHA-256 of the JSON content.
+func WriteAsset(w io.Writer, source any, key []byte, header EnvelopeHeader) (string, error) {
+	header.ContentEncoding = ContentEncodingGzip
+	ew, err := newEnvelopeWriter(w, header, key)
+	if err != nil {
+		return "", fmt.Errorf("newEnvelopeWriter: %w", err)
+	}
+
+	gz := gzip.NewWriter(ew)
+	hash := sha256.New()
+
+	if err := json.NewEncoder(io.MultiWriter(gz, hash)).Encode(source); err != nil {
+		return "", fmt.Errorf("json.NewEncoder.Encode: %w", err)
+	}
+
+	if err := gz.Close(); err != nil {
+		return "", fmt.Errorf("gz.Close: %w", err)
+	}
+
+	if err := ew.Close(); err != nil {
+		return "", fmt.Errorf("ew.Close: %w", err)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
 }
 
-func (r *reader) getCiphertextFromURL(ctx context.Context) ([]byte, error) {
-	ctx, cancel := context.WithTimeout(ctx, 30*time.Second) // hardcode for now
-	defer cancel()
+// verify verifies the signature of the asset, which is embedded or detached next to it, and returns the copy of
+// the asset which is verified. The copy is decoded instead of the asset itself, since the asset file may be overwritten
+// in place once it is verified, e.g. by cp while the dictionary is reloaded, and its new content is never verified.
+func (r *reader) verify(asset io.Reader) (io.ReadSeekCloser, Key, error) {
+	tmp, err := createTemp()
+	if err != nil {
+		return nil, Key{}, err
+	}
+
+	// the whole asset is read to verify it, so the copy has all of it.
+	_, signedBy, err := verifyStream(io.TeeReader(asset, tmp), r.detachedSignature, r.publicKeys)
+	if err != nil {
+		_ = tmp.Close()
+		return nil, Key{}, err
+	}
 
-	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, r.url, nil)
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		_ = tmp.Close()
+		return nil, Key{}, fmt.Errorf("seek: %w", err)
+	}
 
-	res, err := http.DefaultClient.Do(req)
+	return tmp, signedBy, nil
+}
+
+func (r *reader) detachedSignature() ([]byte, error) {
+	if r.url == "" {
+		b, err := readFile(path.Join(r.dir, r.filename+SignatureSuffix))
+		if err != nil {
+			return nil, fmt.Errorf("detached signature: %w", err)
+		}
+		return b, nil
+	}
+
+	signatureURL := r.signatureURL
+	if signatureURL == "" {
+		signatureURL = signatureURLOf(r.url)
+	}
+
+	body, _, err := r.download(r.ctx, signatureURL)
 	if err != nil {
-		return nil, fmt.Errorf("http do: %w", err)
+		return nil, fmt.Errorf("detached signature: %w", err)
 	}
 	defer func() {
-		_ = res.Body.Close()
+		_ = body.Close()
 	}()
 
-	ciphertext, err := io.ReadAll(res.Body)
+	b, err := io.ReadAll(body)
 	if err != nil {
-		return nil, fmt.Errorf("io readall: %w", err)
+		return nil, fmt.Errorf("detached signature: io readall: %w", err)
 	}
 
-	return ciphertext, nil
+	return b, nil
 }
 
-func (r *reader) getCiphertextFromFile() ([]byte, error) {
-	ciphertext, err := os.ReadFile(path.Join(r.dir, r.filename))
+func (r *reader) download(ctx context.Context, url string) (io.ReadCloser, AssetSource, error) {
+	if r.downloader == nil {
+		r.downloader = newDownloader(Configuration{}, slog.Default())
+	}
+	return r.downloader.download(ctx, url)
+}
+
+// spool copies r into a temporary file, which is removed when it is closed.
+func spool(r io.Reader) (io.ReadSeekCloser, error) {
+	tmp, err := createTemp()
 	if err != nil {
-		return nil, fmt.Errorf("os.ReadFile: %w", err)
+		return nil, err
+	}
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		_ = tmp.Close()
+		return nil, fmt.Errorf("io.Copy: %w", err)
+	}
+
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		_ = tmp.Close()
+		return nil, fmt.Errorf("seek: %w", err)
 	}
-	return ciphertext, nil
+
+	return tmp, nil
 }
 
-func (r *reader) decrypt(key, nonce, ciphertext []byte) ([]byte, error) {
-	block, err := aes.NewCipher(key)
+// createTemp creates a temporary file, which is removed when it is closed.
+func createTemp() (*tempFile, error) {
+	f, err := os.CreateTemp("", "kbbi-asset-*")
 	if err != nil {
-		return nil, fmt.Errorf("aes.NewCipher: %w", err)
+		return nil, fmt.Errorf("os.CreateTemp: %w", err)
 	}
+	return &tempFile{File: f}, nil
+}
+
+type tempFile struct {
+	*os.File
+}
+
+func (f *tempFile) Close() error {
+	err := f.File.Close()
+	_ = os.Remove(f.Name())
+	return err
+}
+
+// assetStream reads from Reader, and closes all closers in order when it is closed.
+type assetStream struct {
+	io.Reader
+	closers []io.Closer
+}
 
-	aesGCM, err := cipher.NewGCM(block)
+func (s *assetStream) Close() error {
+	var errs []error
+	for _, c := range s.closers {
+		errs = append(errs, c.Close())
+	}
+	return errors.Join(errs...)
+}
+
+type closerFunc func()
+
+func (f closerFunc) Close() error {
+	f()
+	return nil
+}
+
+func readFile(name string) ([]byte, error) {
+	b, err := os.ReadFile(name)
 	if err != nil {
-		return nil, fmt.Errorf("cipher.NewGCM: %w", err)
+		return nil, fmt.Errorf("os.ReadFile: %w", err)
+	}
+	return b, nil
+}
+
+func decrypt(key, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	aesGCM, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	// aesGCM.Open panics on the invalid nonce size.
+	if len(nonce) != aesGCM.NonceSize() {
+		return nil, fmt.Errorf("invalid nonce size %d, expected %d", len(nonce), aesGCM.NonceSize())
 	}
 
-	plaintext, err := aesGCM.Open(nil, nonce, ciphertext, nil)
+	plaintext, err := aesGCM.Open(nil, nonce, ciphertext, additionalData)
 	if err != nil {
 		return nil, fmt.Errorf("aesGCM.Open: %w", err)
 	}
@@ -0,0 +1,137 @@
+package dictionary
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// assetCache keeps the last known good version of each downloaded asset in a directory, along with its validators,
+// so the server still starts with the last assets it loaded if the download URL is unreachable.
+// Each URL has the encrypted asset in <hash>.asset and its metadata in <hash>.json, where hash is the SHA-256 of the URL.
+type assetCache struct {
+	dir string
+}
+
+// cacheEntry is the metadata of a cached asset.
+type cacheEntry struct {
+	URL          string    `json:"url"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"lastModified,omitempty"`
+	SHA256       string    `json:"sha256"`
+	DownloadedAt time.Time `json:"downloadedAt"`
+}
+
+func (e cacheEntry) validators() validators {
+	return validators{etag: e.ETag, lastModified: e.LastModified, sha256: e.SHA256}
+}
+
+func (c *assetCache) path(url, ext string) string {
+	sum := sha256.Sum256([]byte(url))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+ext)
+}
+
+// entry returns the metadata of the cached asset of the URL, ok is false if the URL is not cached.
+func (c *assetCache) entry(url string) (entry cacheEntry, ok bool, err error) {
+	b, err := os.ReadFile(c.path(url, ".json"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return cacheEntry{}, false, nil
+	}
+	if err != nil {
+		return cacheEntry{}, false, fmt.Errorf("os.ReadFile: %w", err)
+	}
+
+	if err := json.Unmarshal(b, &entry); err != nil {
+		return cacheEntry{}, false, fmt.Errorf("json.Unmarshal: %w", err)
+	}
+	if entry.URL != url {
+		return cacheEntry{}, false, fmt.Errorf("cached asset is of %q", entry.URL)
+	}
+
+	return entry, true, nil
+}
+
+// open opens the cached asset of the entry, after checking that it is the same file which is cached.
+func (c *assetCache) open(entry cacheEntry) (io.ReadSeekCloser, error) {
+	f, err := os.Open(c.path(entry.URL, ".asset"))
+	if err != nil {
+		return nil, fmt.Errorf("os.Open: %w", err)
+	}
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("io.Copy: %w", err)
+	}
+	if sum := hex.EncodeToString(hash.Sum(nil)); sum != entry.SHA256 {
+		_ = f.Close()
+		return nil, fmt.Errorf("cached asset has SHA-256 %s, expected %s", sum, entry.SHA256)
+	}
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("f.Seek: %w", err)
+	}
+
+	return f, nil
+}
+
+// create creates the temporary file in the cache directory, which is moved into the cache by store.
+func (c *assetCache) create() (*os.File, error) {
+	if err := os.MkdirAll(c.dir, 0o755); err != nil {
+		return nil, fmt.Errorf("os.MkdirAll: %w", err)
+	}
+
+	f, err := os.CreateTemp(c.dir, ".download-*")
+	if err != nil {
+		return nil, fmt.Errorf("os.CreateTemp: %w", err)
+	}
+	return f, nil
+}
+
+// store moves the downloaded asset at path into the cache as the asset of the URL.
+// The asset is replaced before its metadata, so the old metadata doesn't match the new asset if it fails in between.
+func (c *assetCache) store(url, path string, v validators) error {
+	if err := os.Rename(path, c.path(url, ".asset")); err != nil {
+		return fmt.Errorf("os.Rename: %w", err)
+	}
+
+	b, err := json.Marshal(cacheEntry{
+		URL:          url,
+		ETag:         v.etag,
+		LastModified: v.lastModified,
+		SHA256:       v.sha256,
+		DownloadedAt: time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("json.Marshal: %w", err)
+	}
+
+	f, err := os.CreateTemp(c.dir, ".entry-*")
+	if err != nil {
+		return fmt.Errorf("os.CreateTemp: %w", err)
+	}
+	defer func() {
+		_ = os.Remove(f.Name())
+	}()
+
+	if _, err := f.Write(b); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("f.Write: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("f.Close: %w", err)
+	}
+	if err := os.Rename(f.Name(), c.path(url, ".json")); err != nil {
+		return fmt.Errorf("os.Rename: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,173 @@
+package dictionary_test
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/raf555/kbbi-api/internal/dictionary"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewReloader_Cache(t *testing.T) {
+	key := bytes.Repeat([]byte{1}, 32)
+	assets := map[string][]byte{
+		"/wotd.db": sealTestAsset(t, key, dictionary.AssetKindWOTD, `[1]`),
+		"/dict.db": sealTestAsset(t, key, dictionary.AssetKindDictionary, `{"stats":{"edition":"VI"},"lemmas":[{"lemma":"apel"}]}`),
+	}
+	newEdition := sealTestAsset(t, key, dictionary.AssetKindDictionary, `{"stats":{"edition":"VII"},"lemmas":[{"lemma":"apel"}]}`)
+
+	var (
+		mu   sync.Mutex
+		mode string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch mode {
+		case "down":
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		case "corrupted":
+			if r.URL.Path == "/dict.db" {
+				w.Header().Set("ETag", `"corrupted"`)
+				_, _ = w.Write([]byte("corrupted"))
+				return
+			}
+		case "removed":
+			w.WriteHeader(http.StatusNotFound)
+			return
+		case "error page":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			_, _ = w.Write([]byte("<html>maintenance</html>"))
+			return
+		case "new edition":
+			if r.URL.Path == "/dict.db" {
+				w.Header().Set("ETag", `"v2"`)
+				_, _ = w.Write(newEdition)
+				return
+			}
+		}
+
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		_, _ = w.Write(assets[r.URL.Path])
+	}))
+	defer srv.Close()
+
+	cacheDir := t.TempDir()
+	cfg := dictionary.Configuration{
+		AssetsEncryptionKey: key,
+		Dictionary:          dictionary.AssetConfig{DownloadURL: srv.URL + "/dict.db"},
+		WOTD:                dictionary.AssetConfig{DownloadURL: srv.URL + "/wotd.db"},
+		CacheDirectory:      cacheDir,
+	}
+
+	// each step starts the server again with the cache left by the previous steps.
+	tcs := []struct {
+		name           string
+		mode           string
+		withoutCaching bool
+
+		expectedEdition string
+		expectedSources dictionary.AssetSources
+		expectedErr     string
+	}{
+		{
+			name:        "unreachable without cached assets",
+			mode:        "down",
+			expectedErr: "unexpected status code 503",
+		},
+		{
+			name:            "downloaded",
+			expectedEdition: "VI",
+			expectedSources: dictionary.AssetSources{Dictionary: dictionary.AssetSourceNetwork, WOTD: dictionary.AssetSourceNetwork},
+		},
+		{
+			name:            "not modified",
+			expectedEdition: "VI",
+			expectedSources: dictionary.AssetSources{Dictionary: dictionary.AssetSourceCache, WOTD: dictionary.AssetSourceCache},
+		},
+		{
+			name:            "unreachable",
+			mode:            "down",
+			expectedEdition: "VI",
+			expectedSources: dictionary.AssetSources{Dictionary: dictionary.AssetSourceCacheFallback, WOTD: dictionary.AssetSourceCacheFallback},
+		},
+		{
+			name:        "corrupted asset",
+			mode:        "corrupted",
+			expectedErr: "loadDictionary",
+		},
+		{
+			name:            "unreachable after corrupted asset",
+			mode:            "down",
+			expectedEdition: "VI",
+			expectedSources: dictionary.AssetSources{Dictionary: dictionary.AssetSourceCacheFallback, WOTD: dictionary.AssetSourceCacheFallback},
+		},
+		{
+			name:            "new edition",
+			mode:            "new edition",
+			expectedEdition: "VII",
+			expectedSources: dictionary.AssetSources{Dictionary: dictionary.AssetSourceNetwork, WOTD: dictionary.AssetSourceCache},
+		},
+		{
+			name:            "unreachable after new edition",
+			mode:            "down",
+			expectedEdition: "VII",
+			expectedSources: dictionary.AssetSources{Dictionary: dictionary.AssetSourceCacheFallback, WOTD: dictionary.AssetSourceCacheFallback},
+		},
+		{
+			name:        "removed",
+			mode:        "removed",
+			expectedErr: "unexpected status code 404",
+		},
+		{
+			name:        "error page",
+			mode:        "error page",
+			expectedErr: `unexpected content type "text/html"`,
+		},
+		{
+			name:           "unreachable without caching",
+			mode:           "down",
+			withoutCaching: true,
+			expectedErr:    "unexpected status code 503",
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			mu.Lock()
+			mode = tc.mode
+			mu.Unlock()
+
+			cfg := cfg
+			if tc.withoutCaching {
+				cfg.CacheDirectory = ""
+			}
+
+			reloader, err := dictionary.NewReloader(t.Context(), cfg, slog.New(slog.DiscardHandler))
+			if tc.expectedErr != "" {
+				assert.ErrorContains(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedEdition, reloader.Current().Stats().Edition)
+			assert.Equal(t, tc.expectedSources, reloader.Current().Sources())
+
+			// only the cached assets are left in the cache directory, without the temporary files.
+			entries, err := os.ReadDir(cacheDir)
+			require.NoError(t, err)
+			assert.Len(t, entries, 4)
+		})
+	}
+}
@@ -0,0 +1,214 @@
+package dictionary
+
+import (
+	"bufio"
+	"crypto/cipher"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// The chunked ciphertext is a sequence of AES-GCM messages, each of them is the chunk of the plaintext:
+//
+//	chunk 0 | chunk 1 | ... | last chunk
+//
+// All chunks have the same size except the last one, which may be smaller or empty. The nonce of each chunk is
+// the nonce prefix from the header, the chunk counter, and the last chunk flag (STREAM construction),
+// so the chunks can't be reordered, and the truncated ciphertext can't be decrypted.
+const (
+	chunkNoncePrefixSize = 7
+	// defaultChunkSize is the size of the plaintext of each chunk.
+	defaultChunkSize = 64 << 10
+	// maxChunkSize guards against allocating a corrupted chunk size.
+	maxChunkSize = 1 << 20
+	// cipherOverhead is the size of the AES-GCM tag appended to each chunk.
+	cipherOverhead = 16
+)
+
+// chunkNonce is the nonce of the chunk: nonce prefix | counter (4 bytes, big-endian) | last chunk flag (1 byte).
+func chunkNonce(dst, prefix []byte, counter uint32, last bool) []byte {
+	dst = append(dst[:0], prefix...)
+	dst = binary.BigEndian.AppendUint32(dst, counter)
+	if last {
+		return append(dst, 1)
+	}
+	return append(dst, 0)
+}
+
+type chunkWriter struct {
+	w    io.Writer
+	aead cipher.AEAD
+
+	prefix, aad []byte
+	chunkSize   int
+	counter     uint32
+
+	buf, sealed, nonce []byte
+	closed             bool
+}
+
+func newChunkWriter(w io.Writer, aead cipher.AEAD, prefix, aad []byte, chunkSize int) *chunkWriter {
+	return &chunkWriter{
+		w:         w,
+		aead:      aead,
+		prefix:    prefix,
+		aad:       aad,
+		chunkSize: chunkSize,
+		buf:       make([]byte, 0, chunkSize),
+		sealed:    make([]byte, 0, chunkSize+aead.Overhead()),
+	}
+}
+
+func (w *chunkWriter) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, errors.New("write to closed chunk writer")
+	}
+
+	n := len(p)
+	for len(p) > 0 {
+		// the full chunk is only sealed once there is more data, since the last chunk is sealed with the flag.
+		if len(w.buf) == w.chunkSize {
+			if err := w.seal(false); err != nil {
+				return 0, err
+			}
+		}
+
+		m := min(w.chunkSize-len(w.buf), len(p))
+		w.buf = append(w.buf, p[:m]...)
+		p = p[m:]
+	}
+
+	return n, nil
+}
+
+// Close seals the last chunk. It doesn't close the underlying writer.
+func (w *chunkWriter) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	return w.seal(true)
+}
+
+func (w *chunkWriter) seal(last bool) error {
+	if w.counter == math.MaxUint32 {
+		return errors.New("too many chunks")
+	}
+
+	w.nonce = chunkNonce(w.nonce, w.prefix, w.counter, last)
+	w.sealed = w.aead.Seal(w.sealed[:0], w.nonce, w.buf, w.aad)
+	if _, err := w.w.Write(w.sealed); err != nil {
+		return err
+	}
+
+	w.buf = w.buf[:0]
+	w.counter++
+	return nil
+}
+
+type chunkReader struct {
+	r    *bufio.Reader
+	aead cipher.AEAD
+	// key is the key which decrypts the chunks.
+	key Key
+
+	prefix, aad []byte
+	chunkSize   int
+	counter     uint32
+
+	in, out, plaintext, nonce []byte
+	done                      bool
+}
+
+// newChunkReader returns the reader which decrypts the chunks from r. The first chunk is decrypted with each of the keys,
+// and the one which decrypts it is used for the rest.
+func newChunkReader(r *bufio.Reader, keys Keyring, prefix, aad []byte, chunkSize int) (*chunkReader, error) {
+	cr := &chunkReader{
+		r:         r,
+		prefix:    prefix,
+		aad:       aad,
+		chunkSize: chunkSize,
+	}
+
+	ciphertext, last, err := cr.readChunk()
+	if err != nil {
+		return nil, err
+	}
+
+	cr.nonce = chunkNonce(cr.nonce, prefix, 0, last)
+	plaintext, key, err := keys.open(cr.nonce, ciphertext, aad)
+	if err != nil {
+		return nil, fmt.Errorf("chunk 0: %w", err)
+	}
+
+	if cr.aead, err = newGCM(key.Key); err != nil {
+		return nil, err
+	}
+	cr.key, cr.plaintext, cr.done, cr.counter = key, plaintext, last, 1
+
+	return cr, nil
+}
+
+// readChunk reads the next chunk. last reports whether it is the last chunk, i.e. there is no more data after it.
+func (cr *chunkReader) readChunk() (ciphertext []byte, last bool, err error) {
+	if cr.in == nil {
+		cr.in = make([]byte, cr.chunkSize+cipherOverhead)
+	}
+
+	n, err := io.ReadFull(cr.r, cr.in)
+	switch {
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		last = true
+	case errors.Is(err, io.EOF):
+		return nil, false, fmt.Errorf("chunk %d: %w", cr.counter, io.ErrUnexpectedEOF)
+	case err != nil:
+		return nil, false, fmt.Errorf("chunk %d: %w", cr.counter, err)
+	default:
+		if _, err := cr.r.Peek(1); errors.Is(err, io.EOF) {
+			last = true
+		} else if err != nil {
+			return nil, false, fmt.Errorf("chunk %d: %w", cr.counter, err)
+		}
+	}
+
+	return cr.in[:n], last, nil
+}
+
+func (cr *chunkReader) Read(p []byte) (int, error) {
+	for len(cr.plaintext) == 0 {
+		if cr.done {
+			return 0, io.EOF
+		}
+
+		if err := cr.next(); err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, cr.plaintext)
+	cr.plaintext = cr.plaintext[n:]
+	return n, nil
+}
+
+func (cr *chunkReader) next() error {
+	if cr.counter == math.MaxUint32 {
+		return errors.New("too many chunks")
+	}
+
+	ciphertext, last, err := cr.readChunk()
+	if err != nil {
+		return err
+	}
+
+	cr.nonce = chunkNonce(cr.nonce, cr.prefix, cr.counter, last)
+	cr.out, err = cr.aead.Open(cr.out[:0], cr.nonce, ciphertext, cr.aad)
+	if err != nil {
+		return fmt.Errorf("chunk %d: aesGCM.Open: %w", cr.counter, err)
+	}
+
+	cr.plaintext, cr.done = cr.out, last
+	cr.counter++
+	return nil
+}
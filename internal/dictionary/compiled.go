@@ -0,0 +1,650 @@
+package dictionary
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"runtime"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/raf555/kbbi-api/pkg/kbbi"
+)
+
+// The compiled dictionary is the dictionary with its indexes laid out flat, so it can be mapped into memory
+// and searched without decoding it first. The lemmas themselves are still JSON, which is decoded on every
+// lookup of the lemma, see [mappedIndex.lemma]:
+//
+//	magic (4 bytes) | version (4 bytes) | section count (4 bytes) | section table | checksum (32 bytes) | sections
+//
+// The section table has the offset and the length of each section in the order below. The checksum is the SHA-256
+// of the whole file without the checksum itself, which is checked by [Dictionary.VerifyCompiled]. All integers are
+// little-endian uint32. The strings are referred to by their offset and length in the strings section, and
+// the lemmas are referred to by their position in the lemmas section. The lemmas and the variants sections are
+// sorted by the normalized form, and the lookup sections are sorted by their key, so they are binary searched.
+// A file which is not sorted is refused, since the lookups would quietly miss.
+const (
+	compiledMagic      = "KBBC"
+	compiledVersion    = 3
+	compiledPrefixSize = len(compiledMagic) + 4 + 4
+	// compiledHeaderSize is the size of everything before the sections.
+	compiledHeaderSize = compiledPrefixSize + sectionCount*8 + sha256.Size
+)
+
+const (
+	// sectionInfo is the JSON of [CompiledInfo].
+	sectionInfo = iota
+	// sectionLemmas records are the name, the normalized form, the lemma with its entries (JSON in sectionData),
+	// and the offset and the count of its entry numbers in sectionEntryNos.
+	sectionLemmas
+	// sectionEntryNos records are the entry number and the index of the entry, grouped by lemma.
+	sectionEntryNos
+	// sectionExact records are the lemma name and the lemma.
+	sectionExact
+	// sectionNormalized records are the lemma name without diacritics and the lemma.
+	sectionNormalized
+	// sectionVariantKeys records are the variant key, the lemma, the entry index, the kind, and the form.
+	sectionVariantKeys
+	// sectionStandardForms records are the variant key of the non-standard form, the lemma, and the entry index.
+	sectionStandardForms
+	// sectionVariants records are the normalized form of the variant and the lemma, sorted by the normalized form.
+	sectionVariants
+	// sectionAssetOrder records are the lemma, in the order of the dictionary asset which the word of the day refers to.
+	sectionAssetOrder
+	sectionStrings
+	sectionData
+	sectionCount
+)
+
+// recordSizes is the number of uint32 fields of each record of the sections, where a string takes two fields.
+var recordSizes = [sectionCount]int{
+	sectionLemmas:        8,
+	sectionEntryNos:      2,
+	sectionExact:         3,
+	sectionNormalized:    3,
+	sectionVariantKeys:   7,
+	sectionStandardForms: 4,
+	sectionVariants:      3,
+	sectionAssetOrder:    1,
+}
+
+var variantKinds = []VariantKind{VariantKindEntry, VariantKindWord}
+
+var ErrInvalidCompiledDictionary = errors.New("dictionary: invalid compiled dictionary")
+
+// CompiledInfo is the metadata of the compiled dictionary.
+type CompiledInfo struct {
+	// Version is the format version of the compiled dictionary. It is set when it is opened.
+	Version            int   `json:"-"`
+	Stats              Stats `json:"stats"`
+	LongestLemmaLength int   `json:"longestLemmaLength"`
+	// ContentSHA256 is the hex encoded SHA-256 of the JSON content of the dictionary asset which it is compiled from,
+	// same as the content hash in the asset manifest.
+	ContentSHA256 string    `json:"contentSha256,omitempty"`
+	CompiledAt    time.Time `json:"compiledAt"`
+}
+
+// Compile writes the dictionary in the compiled format, which is opened by [OpenCompiledDictionary]
+// without decoding the whole dictionary. Only the dictionary read from the asset can be compiled,
+// since the asset is the source of truth.
+func (d *Dictionary) Compile(w io.Writer) (CompiledInfo, error) {
+	m, ok := d.index.(*memoryIndex)
+	if !ok {
+		return CompiledInfo{}, errors.New("the dictionary is already compiled")
+	}
+
+	info := CompiledInfo{
+		Version:            compiledVersion,
+		Stats:              d.stats,
+		LongestLemmaLength: m.longest,
+		ContentSHA256:      d.contentSHA256,
+		CompiledAt:         time.Now().UTC().Truncate(time.Second),
+	}
+
+	var (
+		c        compiler
+		entryNos uint32
+	)
+	for _, lemma := range m.lemmas {
+		data, err := json.Marshal(lemma.Lemma)
+		if err != nil {
+			return CompiledInfo{}, fmt.Errorf("json.Marshal: %w", err)
+		}
+
+		count := uint32(0)
+		for _, entryNo := range slices.Sorted(maps.Keys(lemma.entryNoMap)) {
+			for _, entryIdx := range lemma.entryNoMap[entryNo] {
+				c.record(sectionEntryNos, []uint32{uint32(entryNo), uint32(entryIdx)})
+				count++
+			}
+		}
+
+		c.record(sectionLemmas, c.str(lemma.Lemma.Lemma), c.str(lemma.NormalizedForm), c.data(data), []uint32{entryNos, count})
+		entryNos += count
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(m.inverseIndex)) {
+		c.record(sectionExact, c.str(key), []uint32{uint32(m.inverseIndex[key])})
+	}
+	for _, key := range slices.Sorted(maps.Keys(m.inverseNormalizedIndex)) {
+		c.record(sectionNormalized, c.str(key), []uint32{uint32(m.inverseNormalizedIndex[key])})
+	}
+	for _, key := range slices.Sorted(maps.Keys(m.variantIndex)) {
+		index := m.variantIndex[key]
+		kind := uint32(slices.Index(variantKinds, index.kind))
+		c.record(sectionVariantKeys, c.str(key), []uint32{uint32(index.idx), uint32(index.entryIdx), kind}, c.str(index.form))
+	}
+	for _, key := range slices.Sorted(maps.Keys(m.standardFormIndex)) {
+		index := m.standardFormIndex[key]
+		c.record(sectionStandardForms, c.str(key), []uint32{uint32(index.idx), uint32(index.entryIdx)})
+	}
+	for _, variant := range m.variants {
+		c.record(sectionVariants, c.str(variant.NormalizedForm), []uint32{uint32(variant.idx)})
+	}
+	for _, i := range m.assetOrder {
+		c.record(sectionAssetOrder, []uint32{uint32(i)})
+	}
+
+	infoJSON, err := json.Marshal(info)
+	if err != nil {
+		return CompiledInfo{}, fmt.Errorf("json.Marshal: %w", err)
+	}
+	c.sections[sectionInfo].Write(infoJSON)
+
+	if err := c.writeTo(w); err != nil {
+		return CompiledInfo{}, err
+	}
+
+	return info, nil
+}
+
+// compiler lays out the sections of the compiled dictionary.
+type compiler struct {
+	sections [sectionCount]bytes.Buffer
+	// strings dedupes the strings, since the name and the normalized form of most lemmas are the same.
+	strings map[string][]uint32
+}
+
+// str adds s to the strings section, and returns its reference.
+func (c *compiler) str(s string) []uint32 {
+	if ref, ok := c.strings[s]; ok {
+		return ref
+	}
+	if c.strings == nil {
+		c.strings = make(map[string][]uint32)
+	}
+
+	ref := []uint32{uint32(c.sections[sectionStrings].Len()), uint32(len(s))}
+	c.sections[sectionStrings].WriteString(s)
+	c.strings[s] = ref
+	return ref
+}
+
+// data adds b to the data section, and returns its reference.
+func (c *compiler) data(b []byte) []uint32 {
+	ref := []uint32{uint32(c.sections[sectionData].Len()), uint32(len(b))}
+	c.sections[sectionData].Write(b)
+	return ref
+}
+
+func (c *compiler) record(section int, fields ...[]uint32) {
+	for _, field := range fields {
+		for _, v := range field {
+			c.sections[section].Write(binary.LittleEndian.AppendUint32(nil, v))
+		}
+	}
+}
+
+func (c *compiler) writeTo(w io.Writer) error {
+	header := make([]byte, 0, compiledHeaderSize)
+	header = append(header, compiledMagic...)
+	header = binary.LittleEndian.AppendUint32(header, compiledVersion)
+	header = binary.LittleEndian.AppendUint32(header, sectionCount)
+
+	offset := uint64(compiledHeaderSize)
+	for i := range c.sections {
+		size := uint64(c.sections[i].Len())
+		if offset+size > math.MaxUint32 {
+			return errors.New("the compiled dictionary exceeds 4 GiB")
+		}
+
+		header = binary.LittleEndian.AppendUint32(header, uint32(offset))
+		header = binary.LittleEndian.AppendUint32(header, uint32(size))
+		offset += size
+	}
+
+	hash := sha256.New()
+	hash.Write(header)
+	for i := range c.sections {
+		hash.Write(c.sections[i].Bytes())
+	}
+	header = hash.Sum(header)
+
+	if _, err := w.Write(header); err != nil {
+		return fmt.Errorf("w.Write: %w", err)
+	}
+	for i := range c.sections {
+		if _, err := c.sections[i].WriteTo(w); err != nil {
+			return fmt.Errorf("w.Write: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// OpenCompiledDictionary maps the compiled dictionary written by [Dictionary.Compile] into memory.
+// Only the header and the section table are checked here, so the file is not read beyond its first page, and
+// the records themselves are only read as they are looked up. The checksum and the records are checked by
+// [Dictionary.VerifyCompiled], which reads the whole file.
+//
+// The file must not be modified while it is mapped, replace it by renaming a new file over it instead.
+// The modified records fail the lookups with [ErrInvalidCompiledDictionary], but the truncated file crashes with SIGBUS.
+// The file is unmapped once the returned dictionary is unreachable.
+func OpenCompiledDictionary(path string, wotd WOTDRepo) (*Dictionary, CompiledInfo, error) {
+	data, unmap, err := mapFile(path)
+	if err != nil {
+		return nil, CompiledInfo{}, fmt.Errorf("mapFile: %w", err)
+	}
+
+	m, err := newMappedIndex(data)
+	if err != nil {
+		_ = unmap()
+		return nil, CompiledInfo{}, err
+	}
+	// nothing read from the mapped file outlives the index, since the records are copied out as they are read.
+	runtime.AddCleanup(m, func(unmap func() error) { _ = unmap() }, unmap)
+
+	return &Dictionary{
+		wotd:          wotd,
+		stats:         m.info.Stats,
+		index:         m,
+		contentSHA256: m.info.ContentSHA256,
+		sources:       AssetSources{Dictionary: AssetSourceCompiled},
+	}, m.info, nil
+}
+
+// VerifyCompiled checks the checksum of the compiled dictionary, and that every record is well-formed, so the lookups
+// never read out of the file or miss in the unsorted records. It reads the whole file, so it is not done by
+// [OpenCompiledDictionary], see [Configuration.VerifyCompiledDictionary]. It returns nil if d is not compiled.
+func (d *Dictionary) VerifyCompiled() error {
+	m, ok := d.index.(*mappedIndex)
+	if !ok {
+		return nil
+	}
+	return m.verify()
+}
+
+// mappedIndex is the dictionaryIndex of the compiled dictionary. The strings are copied out of the mapped file.
+type mappedIndex struct {
+	lemmas, entryNos, exactIndex, normalizedIndex, variantKeys, standardForms, variants, assetOrder records
+	strings, data                                                                                   []byte
+
+	// file is the whole mapped file, which is only read by verify.
+	file []byte
+	info CompiledInfo
+}
+
+func newMappedIndex(data []byte) (*mappedIndex, error) {
+	if len(data) < compiledPrefixSize || !bytes.HasPrefix(data, []byte(compiledMagic)) {
+		return nil, fmt.Errorf("%w: not a compiled dictionary", ErrInvalidCompiledDictionary)
+	}
+
+	if version := binary.LittleEndian.Uint32(data[len(compiledMagic):]); version != compiledVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d, compile it again", ErrInvalidCompiledDictionary, version)
+	}
+
+	count := binary.LittleEndian.Uint32(data[len(compiledMagic)+4:])
+	if count != sectionCount || len(data) < compiledHeaderSize {
+		return nil, fmt.Errorf("%w: unexpected section count %d", ErrInvalidCompiledDictionary, count)
+	}
+
+	var sections [sectionCount][]byte
+	for i := range sections {
+		entry := data[compiledPrefixSize+i*8:]
+		offset, size := uint64(binary.LittleEndian.Uint32(entry)), uint64(binary.LittleEndian.Uint32(entry[4:]))
+		if offset+size > uint64(len(data)) {
+			return nil, fmt.Errorf("%w: section %d is out of the file", ErrInvalidCompiledDictionary, i)
+		}
+		if recordSize := recordSizes[i] * 4; recordSize > 0 && size%uint64(recordSize) != 0 {
+			return nil, fmt.Errorf("%w: section %d has a partial record", ErrInvalidCompiledDictionary, i)
+		}
+		sections[i] = data[offset : offset+size]
+	}
+
+	m := &mappedIndex{
+		lemmas:          newRecords(sections, sectionLemmas),
+		entryNos:        newRecords(sections, sectionEntryNos),
+		exactIndex:      newRecords(sections, sectionExact),
+		normalizedIndex: newRecords(sections, sectionNormalized),
+		variantKeys:     newRecords(sections, sectionVariantKeys),
+		standardForms:   newRecords(sections, sectionStandardForms),
+		variants:        newRecords(sections, sectionVariants),
+		assetOrder:      newRecords(sections, sectionAssetOrder),
+		strings:         sections[sectionStrings],
+		data:            sections[sectionData],
+		file:            data,
+	}
+
+	if err := json.Unmarshal(sections[sectionInfo], &m.info); err != nil {
+		return nil, fmt.Errorf("%w: info: %w", ErrInvalidCompiledDictionary, err)
+	}
+	m.info.Version = compiledVersion
+
+	if m.lemmas.len() == 0 {
+		return nil, fmt.Errorf("%w: no lemma", ErrInvalidCompiledDictionary)
+	}
+	if m.assetOrder.len() != m.lemmas.len() {
+		return nil, fmt.Errorf("%w: section %d has %d lemmas, expected %d",
+			ErrInvalidCompiledDictionary, sectionAssetOrder, m.assetOrder.len(), m.lemmas.len())
+	}
+
+	return m, nil
+}
+
+// verify checks the checksum of the file, and the records, see [Dictionary.VerifyCompiled].
+func (m *mappedIndex) verify() error {
+	defer runtime.KeepAlive(m)
+
+	checksumOffset := compiledHeaderSize - sha256.Size
+	hash := sha256.New()
+	hash.Write(m.file[:checksumOffset])
+	hash.Write(m.file[compiledHeaderSize:])
+	if !bytes.Equal(hash.Sum(nil), m.file[checksumOffset:compiledHeaderSize]) {
+		return fmt.Errorf("%w: checksum mismatch, the file is truncated or corrupted", ErrInvalidCompiledDictionary)
+	}
+
+	if err := m.validate(); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidCompiledDictionary, err)
+	}
+
+	return nil
+}
+
+// recordRefs is the fields of the records of a section which refer to the other sections.
+type recordRefs struct {
+	section int
+	records records
+	// strings are the fields of the offset of the strings, followed by their length.
+	strings []int
+	// lemma is the field of the lemma index, or -1 if there is none.
+	lemma int
+}
+
+// validate checks that every reference of the records is within the file, so the lookups never panic,
+// that every lemma decodes and has the entries referred to by the other records, and that the sections
+// which are binary searched are sorted by their key.
+func (m *mappedIndex) validate() error {
+	lemmaCount := m.lemmas.len()
+
+	for i := range lemmaCount {
+		if !m.lemmas.inRange(m.data, i, 4) {
+			return fmt.Errorf("section %d: record %d: data is out of the section", sectionLemmas, i)
+		}
+		if offset, count := m.lemmas.field(i, 6), m.lemmas.field(i, 7); offset+count > m.entryNos.len() {
+			return fmt.Errorf("section %d: record %d: entry numbers are out of the section", sectionLemmas, i)
+		}
+	}
+
+	for i := range m.variantKeys.len() {
+		if kind := m.variantKeys.field(i, 4); kind >= len(variantKinds) {
+			return fmt.Errorf("section %d: record %d: unknown variant kind %d", sectionVariantKeys, i, kind)
+		}
+	}
+
+	refs := []recordRefs{
+		{section: sectionLemmas, records: m.lemmas, strings: []int{0, 2}, lemma: -1},
+		{section: sectionExact, records: m.exactIndex, strings: []int{0}, lemma: 2},
+		{section: sectionNormalized, records: m.normalizedIndex, strings: []int{0}, lemma: 2},
+		{section: sectionVariantKeys, records: m.variantKeys, strings: []int{0, 5}, lemma: 2},
+		{section: sectionStandardForms, records: m.standardForms, strings: []int{0}, lemma: 2},
+		{section: sectionVariants, records: m.variants, strings: []int{0}, lemma: 2},
+		{section: sectionAssetOrder, records: m.assetOrder, lemma: 0},
+	}
+	for _, ref := range refs {
+		for i := range ref.records.len() {
+			for _, field := range ref.strings {
+				if !ref.records.inRange(m.strings, i, field) {
+					return fmt.Errorf("section %d: record %d: string is out of the section", ref.section, i)
+				}
+			}
+			if ref.lemma >= 0 && ref.records.field(i, ref.lemma) >= lemmaCount {
+				return fmt.Errorf("section %d: record %d: lemma %d is out of range", ref.section, i, ref.records.field(i, ref.lemma))
+			}
+		}
+	}
+
+	// the strings and the data are only read once their references are checked above.
+	sorted := []struct {
+		section int
+		records records
+		field   int
+	}{
+		{section: sectionLemmas, records: m.lemmas, field: 2},
+		{section: sectionExact, records: m.exactIndex, field: 0},
+		{section: sectionNormalized, records: m.normalizedIndex, field: 0},
+		{section: sectionVariantKeys, records: m.variantKeys, field: 0},
+		{section: sectionStandardForms, records: m.standardForms, field: 0},
+		{section: sectionVariants, records: m.variants, field: 0},
+	}
+	for _, s := range sorted {
+		for i := 1; i < s.records.len(); i++ {
+			if bytes.Compare(s.records.slice(m.strings, i-1, s.field), s.records.slice(m.strings, i, s.field)) > 0 {
+				return fmt.Errorf("section %d: record %d is not sorted", s.section, i)
+			}
+		}
+	}
+
+	entryCounts := make([]int, lemmaCount)
+	for i := range lemmaCount {
+		var lemma struct {
+			Entries []json.RawMessage `json:"entries"`
+		}
+		if err := json.Unmarshal(m.lemmas.slice(m.data, i, 4), &lemma); err != nil {
+			return fmt.Errorf("section %d: record %d: %w", sectionLemmas, i, err)
+		}
+		entryCounts[i] = len(lemma.Entries)
+
+		for k := m.lemmas.field(i, 6); k < m.lemmas.field(i, 6)+m.lemmas.field(i, 7); k++ {
+			if entryIdx := m.entryNos.field(k, 1); entryIdx >= entryCounts[i] {
+				return fmt.Errorf("section %d: record %d: entry %d is out of range", sectionEntryNos, k, entryIdx)
+			}
+		}
+	}
+
+	entryRefs := []struct {
+		section int
+		records records
+	}{
+		{section: sectionVariantKeys, records: m.variantKeys},
+		{section: sectionStandardForms, records: m.standardForms},
+	}
+	for _, ref := range entryRefs {
+		for i := range ref.records.len() {
+			// the lemma is in the field 2 and its entry in the field 3.
+			if entryIdx := ref.records.field(i, 3); entryIdx >= entryCounts[ref.records.field(i, 2)] {
+				return fmt.Errorf("section %d: record %d: entry %d is out of range", ref.section, i, entryIdx)
+			}
+		}
+	}
+
+	return nil
+}
+
+// records is the section of fixed size records of uint32 fields.
+type records struct {
+	b    []byte
+	size int
+}
+
+func newRecords(sections [sectionCount][]byte, section int) records {
+	return records{b: sections[section], size: recordSizes[section]}
+}
+
+func (r records) len() int {
+	return len(r.b) / (r.size * 4)
+}
+
+func (r records) field(i, field int) int {
+	return int(binary.LittleEndian.Uint32(r.b[(i*r.size+field)*4:]))
+}
+
+// slice returns the part of b referred to by the offset and the length in the field of the record i and the next one.
+// It returns nil if the reference is out of b.
+func (r records) slice(b []byte, i, field int) []byte {
+	if !r.inRange(b, i, field) {
+		return nil
+	}
+	offset, size := r.field(i, field), r.field(i, field+1)
+	return b[offset : offset+size]
+}
+
+// inRange reports whether the reference in the field of the record i and the next one is within b.
+func (r records) inRange(b []byte, i, field int) bool {
+	return r.field(i, field)+r.field(i, field+1) <= len(b)
+}
+
+// The accessors below keep m alive until they return, since the file is unmapped once m is unreachable,
+// while the records they read from still point into it.
+
+// find binary searches the records sorted by the string key in their first field.
+func (m *mappedIndex) find(r records, key string) (int, bool) {
+	defer runtime.KeepAlive(m)
+
+	i := sort.Search(r.len(), func(i int) bool {
+		return string(r.slice(m.strings, i, 0)) >= key
+	})
+	if i < r.len() && string(r.slice(m.strings, i, 0)) == key {
+		return i, true
+	}
+	return 0, false
+}
+
+func (m *mappedIndex) lemmaCount() int {
+	return m.lemmas.len()
+}
+
+func (m *mappedIndex) longestLemmaLength() int {
+	return m.info.LongestLemmaLength
+}
+
+// The references read by the lookups below are only checked by verify, so the invalid ones are treated as an error
+// or as not found instead of panicking.
+
+// lemma decodes the JSON of the lemma on every call, it isn't cached.
+func (m *mappedIndex) lemma(i int) (kbbi.Lemma, error) {
+	defer runtime.KeepAlive(m)
+
+	if i < 0 || i >= m.lemmas.len() {
+		return kbbi.Lemma{}, fmt.Errorf("%w: lemma %d is out of range", ErrInvalidCompiledDictionary, i)
+	}
+
+	var lemma kbbi.Lemma
+	if err := json.Unmarshal(m.lemmas.slice(m.data, i, 4), &lemma); err != nil {
+		return kbbi.Lemma{}, fmt.Errorf("%w: lemma %d: %w", ErrInvalidCompiledDictionary, i, err)
+	}
+	return lemma, nil
+}
+
+func (m *mappedIndex) lemmaName(i int) string {
+	defer runtime.KeepAlive(m)
+
+	if i < 0 || i >= m.lemmas.len() {
+		return ""
+	}
+	return string(m.lemmas.slice(m.strings, i, 0))
+}
+
+func (m *mappedIndex) normalizedForm(i int) string {
+	defer runtime.KeepAlive(m)
+
+	return string(m.lemmas.slice(m.strings, i, 2))
+}
+
+func (m *mappedIndex) assetLemma(n int) int {
+	defer runtime.KeepAlive(m)
+
+	return m.assetOrder.field(n, 0)
+}
+
+func (m *mappedIndex) entryNoMap(i int) map[int][]int {
+	defer runtime.KeepAlive(m)
+
+	offset, count := m.lemmas.field(i, 6), m.lemmas.field(i, 7)
+
+	entryNoMap := make(map[int][]int, count)
+	for k := offset; k < min(offset+count, m.entryNos.len()); k++ {
+		entryNo := m.entryNos.field(k, 0)
+		entryNoMap[entryNo] = append(entryNoMap[entryNo], m.entryNos.field(k, 1))
+	}
+	return entryNoMap
+}
+
+func (m *mappedIndex) exact(lemma string) (int, bool) {
+	defer runtime.KeepAlive(m)
+
+	i, ok := m.find(m.exactIndex, lemma)
+	if !ok {
+		return 0, false
+	}
+	return m.exactIndex.field(i, 2), true
+}
+
+func (m *mappedIndex) normalized(normalized string) (int, bool) {
+	defer runtime.KeepAlive(m)
+
+	i, ok := m.find(m.normalizedIndex, normalized)
+	if !ok {
+		return 0, false
+	}
+	return m.normalizedIndex.field(i, 2), true
+}
+
+func (m *mappedIndex) variant(key string) (variantIndex, bool) {
+	defer runtime.KeepAlive(m)
+
+	i, ok := m.find(m.variantKeys, key)
+	if !ok || m.variantKeys.field(i, 4) >= len(variantKinds) {
+		return variantIndex{}, false
+	}
+
+	return variantIndex{
+		idx:      m.variantKeys.field(i, 2),
+		entryIdx: m.variantKeys.field(i, 3),
+		kind:     variantKinds[m.variantKeys.field(i, 4)],
+		form:     string(m.variantKeys.slice(m.strings, i, 5)),
+	}, true
+}
+
+func (m *mappedIndex) standardForm(key string) (standardFormIndex, bool) {
+	defer runtime.KeepAlive(m)
+
+	i, ok := m.find(m.standardForms, key)
+	if !ok {
+		return standardFormIndex{}, false
+	}
+
+	return standardFormIndex{idx: m.standardForms.field(i, 2), entryIdx: m.standardForms.field(i, 3)}, true
+}
+
+func (m *mappedIndex) variantCount() int {
+	return m.variants.len()
+}
+
+func (m *mappedIndex) variantNormalizedForm(k int) string {
+	defer runtime.KeepAlive(m)
+
+	return string(m.variants.slice(m.strings, k, 0))
+}
+
+func (m *mappedIndex) variantLemma(k int) int {
+	defer runtime.KeepAlive(m)
+
+	return m.variants.field(k, 2)
+}
@@ -0,0 +1,227 @@
+package dictionary_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/raf555/kbbi-api/internal/dictionary"
+	"github.com/raf555/kbbi-api/pkg/kbbi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenCompiledDictionary(t *testing.T) {
+	dict := newTestDictionary(
+		newTestLemma("anak"),
+		newTestLemma("lari", kbbi.Entry{Entry: "la.ri", EntryVariants: []string{"terlari-lari"}}),
+		newTestLemma("berlari"),
+		newTestLemma("kupu-kupu"),
+		newTestLemma("apel", kbbi.Entry{Entry: "apel (1)"}, kbbi.Entry{Entry: "apel (2)"}),
+		newTestLemma("apèl", kbbi.Entry{Entry: "apèl"}),
+		newTestLemma("terselip",
+			kbbi.Entry{Entry: "ter.se.lip (1)"},
+			kbbi.Entry{Entry: "ter.se.lip (2)", EntryVariants: []string{"terselip ke luar"}},
+		),
+		newTestLemma("ude", kbbi.Entry{Entry: "ude", WordVariants: []string{"udeh"}}),
+		newTestLemma("apotek", kbbi.Entry{Entry: "apo.tek", NonStandardWords: []string{"apotik"}}),
+	)
+
+	var buf bytes.Buffer
+	info, err := dict.Compile(&buf)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "dict.bin")
+	require.NoError(t, os.WriteFile(path, buf.Bytes(), 0o600))
+
+	compiled, openedInfo, err := dictionary.OpenCompiledDictionary(path, nil)
+	require.NoError(t, err)
+	assert.Equal(t, info.CompiledAt, openedInfo.CompiledAt)
+	assert.Equal(t, 3, openedInfo.Version)
+	require.NoError(t, compiled.VerifyCompiled())
+
+	t.Run("lookup", func(t *testing.T) {
+		tcs := []struct {
+			in      string
+			entryNo int
+		}{
+			{in: "anak"},
+			{in: "apel", entryNo: 2},
+			{in: "apel", entryNo: 3},
+			{in: "apèl"},
+			{in: "terselip ke luar"},
+			{in: "udeh"},
+			{in: "apotik"},
+			{in: "anak-anak"},
+			{in: "kupu"},
+			{in: "xyz"},
+		}
+
+		for _, tc := range tcs {
+			expected, expectedErr := dict.Lookup(tc.in, tc.entryNo)
+			got, err := compiled.Lookup(tc.in, tc.entryNo)
+			assert.Equal(t, expectedErr, err, tc.in)
+			assert.Equal(t, expected, got, tc.in)
+		}
+	})
+
+	t.Run("search", func(t *testing.T) {
+		for _, prefix := range []string{"", "ap", "ter", "terselip ke", "xyz"} {
+			expected, err := dict.Search(prefix, 10)
+			require.NoError(t, err)
+			got, err := compiled.Search(prefix, 10)
+			require.NoError(t, err)
+			assert.Equal(t, expected, got, prefix)
+		}
+	})
+
+	t.Run("suggest", func(t *testing.T) {
+		for _, in := range []string{"apell", "berlarilah", "zzz"} {
+			assert.Equal(t, dict.Suggest(in, 5), compiled.Suggest(in, 5), in)
+		}
+	})
+
+	t.Run("lint", func(t *testing.T) {
+		assert.Equal(t, dict.Lint(), compiled.Lint())
+	})
+
+	t.Run("compile again", func(t *testing.T) {
+		_, err := compiled.Compile(&bytes.Buffer{})
+		assert.ErrorContains(t, err, "already compiled")
+	})
+}
+
+func TestOpenCompiledDictionary_Invalid(t *testing.T) {
+	var buf bytes.Buffer
+	_, err := newTestDictionary(
+		newTestLemma("anak"),
+		newTestLemma("apotek", kbbi.Entry{Entry: "apo.tek", NonStandardWords: []string{"apotik"}}),
+	).Compile(&buf)
+	require.NoError(t, err)
+	compiled := buf.Bytes()
+
+	// the layout of the header, see [dictionary.Dictionary.Compile].
+	const (
+		sectionTable         = 12
+		sectionExact         = 3
+		sectionStandardForms = 6
+		checksumOffset       = sectionTable + 11*8
+	)
+	// modify modifies a copy of the compiled dictionary, and updates its checksum if reseal is set.
+	modify := func(modify func(b []byte), reseal bool) []byte {
+		b := bytes.Clone(compiled)
+		modify(b)
+		if reseal {
+			hash := sha256.New()
+			hash.Write(b[:checksumOffset])
+			hash.Write(b[checksumOffset+sha256.Size:])
+			copy(b[checksumOffset:], hash.Sum(nil))
+		}
+		return b
+	}
+	exactRecord := int(binary.LittleEndian.Uint32(compiled[sectionTable+sectionExact*8:]))
+	standardFormRecord := int(binary.LittleEndian.Uint32(compiled[sectionTable+sectionStandardForms*8:]))
+
+	tcs := []struct {
+		name string
+		data []byte
+
+		expectedErr string
+		// expectedOnVerify is set if the file is only refused by VerifyCompiled, not when it is opened.
+		expectedOnVerify bool
+	}{
+		{
+			name:        "not compiled",
+			data:        []byte("KBBI\x01 definitely not a compiled dictionary"),
+			expectedErr: "not a compiled dictionary",
+		},
+		{
+			name:        "unsupported version",
+			data:        append([]byte("KBBC\x09"), compiled[5:]...),
+			expectedErr: "version 9",
+		},
+		{
+			name:        "truncated",
+			data:        compiled[:len(compiled)-1],
+			expectedErr: "is out of the file",
+		},
+		{
+			name:             "corrupted",
+			data:             modify(func(b []byte) { b[len(b)-1] ^= 0xff }, false),
+			expectedErr:      "checksum mismatch",
+			expectedOnVerify: true,
+		},
+		{
+			name: "section out of the file",
+			data: modify(func(b []byte) {
+				binary.LittleEndian.PutUint32(b[sectionTable+sectionExact*8+4:], uint32(len(b)))
+			}, true),
+			expectedErr: "section 3 is out of the file",
+		},
+		{
+			name: "lemma out of range",
+			data: modify(func(b []byte) {
+				binary.LittleEndian.PutUint32(b[exactRecord+8:], 99)
+			}, true),
+			expectedErr:      "section 3: record 0: lemma 99 is out of range",
+			expectedOnVerify: true,
+		},
+		{
+			name: "string out of the section",
+			data: modify(func(b []byte) {
+				binary.LittleEndian.PutUint32(b[exactRecord+4:], 1<<20)
+			}, true),
+			expectedErr:      "section 3: record 0: string is out of the section",
+			expectedOnVerify: true,
+		},
+		{
+			name: "unsorted",
+			data: modify(func(b []byte) {
+				// swaps the exact records of anak and apotek, which have 3 fields each.
+				first, second := bytes.Clone(b[exactRecord:exactRecord+12]), bytes.Clone(b[exactRecord+12:exactRecord+24])
+				copy(b[exactRecord:], second)
+				copy(b[exactRecord+12:], first)
+			}, true),
+			expectedErr:      "section 3: record 1 is not sorted",
+			expectedOnVerify: true,
+		},
+		{
+			name: "entry out of range",
+			data: modify(func(b []byte) {
+				binary.LittleEndian.PutUint32(b[standardFormRecord+12:], 5)
+			}, true),
+			expectedErr:      "section 6: record 0: entry 5 is out of range",
+			expectedOnVerify: true,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "dict.bin")
+			require.NoError(t, os.WriteFile(path, tc.data, 0o600))
+
+			dict, _, err := dictionary.OpenCompiledDictionary(path, nil)
+			if tc.expectedOnVerify {
+				require.NoError(t, err)
+				err = dict.VerifyCompiled()
+
+				issues := dict.Lint()
+				require.Len(t, issues, 1)
+				assert.Equal(t, dictionary.LintInvalidCompiled, issues[0].Check)
+
+				// the unverified file is still served, and its invalid records fail the lookups instead of panicking.
+				assert.NotPanics(t, func() {
+					for _, lemma := range []string{"anak", "apotek", "apotik"} {
+						_, _ = dict.Lookup(lemma, 0)
+					}
+					_, _ = dict.Search("", 10)
+				})
+			}
+			assert.ErrorIs(t, err, dictionary.ErrInvalidCompiledDictionary)
+			assert.ErrorContains(t, err, tc.expectedErr)
+		})
+	}
+}
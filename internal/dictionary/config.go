@@ -1,16 +1,82 @@
 package dictionary
 
-import "github.com/raf555/kbbi-api/internal/encoding"
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/raf555/kbbi-api/internal/encoding"
+)
 
 type Configuration struct {
 	WOTD       AssetConfig `env:",prefix=ASSETS_WOTD_"`
 	Dictionary AssetConfig `env:",prefix=ASSETS_DICTIONARY_"`
 
-	AssetsEncryptionKey encoding.HexString `env:"ASSETS_ENCRYPTION_KEY, required"`
-	AssetsEncryptionIV  encoding.HexString `env:"ASSETS_ENCRYPTION_IV, required"`
-	AssetsDirectory     string             `env:"ASSETS_DIRECTORY, default=./assets/"`
+	// AssetsEncryptionKey is the current key, see [Configuration.Keyring].
+	AssetsEncryptionKey   encoding.HexString `env:"ASSETS_ENCRYPTION_KEY" validate:"required_without=AssetsEncryptionKeys"`
+	AssetsEncryptionKeyID string             `env:"ASSETS_ENCRYPTION_KEY_ID"`
+	// AssetsEncryptionKeys are the other keys which can decrypt the assets, e.g. the previous key during a rotation.
+	AssetsEncryptionKeys Keyring `env:"ASSETS_ENCRYPTION_KEYS"`
+	// AssetsSignaturePublicKeys verify the signature of the assets before they are decrypted.
+	// The assets must be signed if it is set, see [VerifyAsset].
+	AssetsSignaturePublicKeys PublicKeys `env:"ASSETS_SIGNATURE_PUBLIC_KEYS"`
+	// AssetsEncryptionIV is only used for the legacy assets without envelope, the others have their own nonce.
+	AssetsEncryptionIV encoding.HexString `env:"ASSETS_ENCRYPTION_IV"`
+	AssetsDirectory    string             `env:"ASSETS_DIRECTORY, default=./assets/"`
+	// CompiledDictionary is the path of the compiled dictionary, which is opened instead of reading the dictionary
+	// asset if it is set, see [Dictionary.Compile].
+	// It can't be used with AssetsSignaturePublicKeys, since the compiled dictionary is not signed.
+	CompiledDictionary string `env:"ASSETS_COMPILED_DICTIONARY" validate:"excluded_with=AssetsSignaturePublicKeys"`
+	// VerifyCompiledDictionary checks the whole compiled dictionary when it is opened, which refuses the corrupted file
+	// but reads all of it, see [Dictionary.VerifyCompiled].
+	VerifyCompiledDictionary bool `env:"ASSETS_VERIFY_COMPILED_DICTIONARY, default=false"`
+
+	// Validation lints the dictionary on startup, see [ValidationMode].
+	Validation ValidationMode `env:"ASSETS_VALIDATION, default=off" validate:"oneof=off warn strict"`
+
+	// ReloadToken enables the admin endpoint which reloads the dictionary, the request must have it as the bearer token.
+	ReloadToken string `env:"ASSETS_RELOAD_TOKEN"`
+	// WatchInterval is how often the asset files are checked for changes to reload the dictionary, 0 disables it.
+	WatchInterval time.Duration `env:"ASSETS_WATCH_INTERVAL, default=0s" validate:"gte=0"`
+	// PollInterval is how often the download URLs are checked for new versions to reload the dictionary, 0 disables it.
+	PollInterval time.Duration `env:"ASSETS_POLL_INTERVAL, default=0s" validate:"gte=0"`
+
+	// DownloadTimeout is the timeout of each attempt to download an asset, including reading it.
+	DownloadTimeout time.Duration `env:"ASSETS_DOWNLOAD_TIMEOUT, default=30s" validate:"gt=0"`
+	// DownloadRetries is the number of retries of the download which fails with a network error or a server error.
+	// The delay before each retry doubles from DownloadBackoff up to DownloadMaxBackoff, with jitter.
+	DownloadRetries    int           `env:"ASSETS_DOWNLOAD_RETRIES, default=3" validate:"gte=0"`
+	DownloadBackoff    time.Duration `env:"ASSETS_DOWNLOAD_BACKOFF, default=1s" validate:"gt=0"`
+	DownloadMaxBackoff time.Duration `env:"ASSETS_DOWNLOAD_MAX_BACKOFF, default=30s" validate:"gtefield=DownloadBackoff"`
+	// CacheDirectory keeps the last good assets downloaded from the URLs, which are read if the URLs are unreachable.
+	// The downloads are not cached if it is empty.
+	CacheDirectory string `env:"ASSETS_CACHE_DIRECTORY"`
 }
 
 type AssetConfig struct {
 	DownloadURL string `env:"DOWNLOAD_URL"`
+	// SignatureURL is the URL of the detached signature, defaults to DownloadURL with [SignatureSuffix].
+	SignatureURL string `env:"SIGNATURE_URL"`
+}
+
+// Keyring is the keys which can decrypt the assets, the current key first.
+func (c Configuration) Keyring() Keyring {
+	keys := slices.Clone(c.AssetsEncryptionKeys)
+	if len(c.AssetsEncryptionKey) > 0 {
+		keys = slices.Insert(keys, 0, Key{ID: c.AssetsEncryptionKeyID, Key: c.AssetsEncryptionKey})
+	}
+	return keys
+}
+
+// assetReader is the reader of the asset of the kind, from its download URL if any or from the assets directory.
+func (c Configuration) assetReader(ctx context.Context, kind AssetKind, asset AssetConfig, dl *downloader) *reader {
+	var r *reader
+	if asset.DownloadURL != "" {
+		r = ReadAssetFromURL(asset.DownloadURL, c.Keyring(), c.AssetsEncryptionIV)
+		r.downloader = dl
+	} else {
+		r = ReadAsset(kind.Filename(), c.AssetsDirectory, c.Keyring(), c.AssetsEncryptionIV)
+	}
+
+	return r.WithContext(ctx).Expect(kind).Verify(c.AssetsSignaturePublicKeys, asset.SignatureURL)
 }
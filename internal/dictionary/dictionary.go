@@ -1,177 +1,421 @@
 package dictionary
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"log/slog"
 	"math/rand/v2"
 	"slices"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/raf555/kbbi-api/pkg/kbbi"
-	"github.com/samber/lo"
 )
 
 type Dictionary struct {
-	wotd                   WOTDRepo
-	stats                  Stats
-	longestLemmaLength     int
-	inverseIndex           map[string]*lemmaIndex
-	inverseNormalizedIndex map[string]*lemmaIndex
-	lemmas                 []wrappedLemma
+	wotd  WOTDRepo
+	stats Stats
+	index dictionaryIndex
+	// contentSHA256 is the hash of the JSON content of the dictionary asset, which is kept in the compiled dictionary.
+	contentSHA256 string
+	// sources are where the assets are read from, the word of the day is only known if it is loaded along with it.
+	sources AssetSources
 }
 
-type lemmaIndex struct {
-	idx        int           // index in lemmas.
-	entryNoMap map[int][]int // key is entry number, starts from 1. value is the actual index in the entries.
-}
+func loadDictionary(ctx context.Context, cfg Configuration, dl *downloader, logger *slog.Logger, wotd WOTDRepo) (*Dictionary, error) {
+	var (
+		dict *Dictionary
+		err  error
+	)
+	if path := cfg.CompiledDictionary; path != "" {
+		dict, err = openCompiledDictionary(path, cfg.VerifyCompiledDictionary, logger, wotd)
+	} else {
+		dict, err = readDictionary(ctx, cfg, dl, logger, wotd)
+	}
+	if err != nil {
+		return nil, err
+	}
 
-type wrappedLemma struct {
-	kbbi.Lemma
+	if err := dict.validate(ctx, cfg.Validation, logger); err != nil {
+		return nil, fmt.Errorf("validate: %w", err)
+	}
 
-	NormalizedForm string
+	return dict, nil
 }
 
-func NewDictionary(cfg Configuration, logger *slog.Logger, wotd WOTDRepo) (*Dictionary, error) {
+func readDictionary(ctx context.Context, cfg Configuration, dl *downloader, logger *slog.Logger, wotd WOTDRepo) (*Dictionary, error) {
 	start := time.Now()
 	logger.Info("Started reading dictionary asset")
 
-	var assetData AssetData
-
-	var reader *reader
 	if url := cfg.Dictionary.DownloadURL; url != "" {
 		logger.Info("reading dictionary asset from URL", slog.String("url", url))
-		reader = ReadAssetFromURL(url, cfg.AssetsEncryptionKey, cfg.AssetsEncryptionIV)
-	} else {
-		reader = ReadAsset("dict.db", cfg.AssetsDirectory, cfg.AssetsEncryptionKey, cfg.AssetsEncryptionIV)
 	}
-
-	if err := reader.To(&assetData); err != nil {
+	reader := cfg.assetReader(ctx, AssetKindDictionary, cfg.Dictionary, dl)
+
+	// the lemmas are indexed as they are decoded, so the whole asset is never held in memory.
+	var (
+		builder = newDictionaryBuilder(0)
+		stats   Stats
+	)
+	err := reader.stream(func(content io.Reader) error {
+		var err error
+		stats, err = decodeAssetData(content, builder.add)
+		return err
+	})
+	if err != nil {
 		return nil, fmt.Errorf("ReadAsset: %w", err)
 	}
 
+	reader.logEnvelope(logger)
+
 	logger.Info("Finished reading dictionary asset", slog.String("elapsed", time.Since(start).String()))
 
-	longestLemmaLength := 0
-	inverseIdx := make(map[string]*lemmaIndex, len(assetData.Lemmas))
-	inverseNormalizedIndex := make(map[string]*lemmaIndex)
-	lemmas := make([]wrappedLemma, 0, len(assetData.Lemmas))
+	dict := builder.build(stats, wotd)
+	dict.contentSHA256 = reader.contentSHA256
+	dict.sources.Dictionary = reader.assetSource
+	return dict, nil
+}
+
+func openCompiledDictionary(path string, verify bool, logger *slog.Logger, wotd WOTDRepo) (*Dictionary, error) {
+	start := time.Now()
+
+	dict, info, err := OpenCompiledDictionary(path, wotd)
+	if err != nil {
+		return nil, fmt.Errorf("OpenCompiledDictionary: %w", err)
+	}
 
-	for i, lemma := range assetData.Lemmas {
-		idx := &lemmaIndex{
-			idx:        i,
-			entryNoMap: map[int][]int{},
+	if verify {
+		if err := dict.VerifyCompiled(); err != nil {
+			return nil, fmt.Errorf("dict.VerifyCompiled: %w", err)
 		}
+	}
 
-		// lookup and map entry index if any
-		for j, def := range lemma.Entries {
-			_, entryNo, ok := FindEntryNoFromLemma(def.Entry)
-			if !ok {
-				continue
+	logger.Info("Opened compiled dictionary",
+		slog.String("path", path),
+		slog.String("edition", info.Stats.Edition),
+		slog.String("content_sha256", info.ContentSHA256),
+		slog.Time("compiled_at", info.CompiledAt),
+		slog.Bool("verified", verify),
+		slog.String("elapsed", time.Since(start).String()),
+	)
+
+	return dict, nil
+}
+
+// NewDictionaryFromAssetData builds the dictionary indexes from the already decoded assetData.
+func NewDictionaryFromAssetData(assetData AssetData, wotd WOTDRepo) *Dictionary {
+	builder := newDictionaryBuilder(len(assetData.Lemmas))
+	for _, lemma := range assetData.Lemmas {
+		builder.add(lemma)
+	}
+
+	return builder.build(assetData.Stats, wotd)
+}
+
+// dictionaryBuilder builds the dictionary indexes in memory one lemma at a time, in the order of the dictionary.
+type dictionaryBuilder struct {
+	memoryIndex
+}
+
+// newDictionaryBuilder returns the builder with the room for sizeHint lemmas, which may be 0 if it is unknown.
+func newDictionaryBuilder(sizeHint int) *dictionaryBuilder {
+	return &dictionaryBuilder{memoryIndex{
+		inverseIndex:           make(map[string]int, sizeHint),
+		inverseNormalizedIndex: make(map[string]int),
+		variantIndex:           make(map[string]*variantIndex),
+		standardFormIndex:      make(map[string]*standardFormIndex),
+		lemmas:                 make([]wrappedLemma, 0, sizeHint),
+	}}
+}
+
+func (b *dictionaryBuilder) add(lemma kbbi.Lemma) {
+	i := len(b.lemmas)
+	entryNoMap := map[int][]int{}
+
+	for j, def := range lemma.Entries {
+		indexVariant := func(variant string, kind VariantKind) {
+			index := &variantIndex{idx: i, entryIdx: j, kind: kind, form: variant}
+
+			// same as inverseNormalizedIndex, only use the first one if the variant is already occupied.
+			if key := variantKey(variant); key != "" {
+				if _, ok := b.variantIndex[key]; !ok {
+					b.variantIndex[key] = index
+				}
 			}
 
-			// there can be multiple entries with same number. E.g. ketak (4)
-			// could be misinput from KBBI but for now making the behavior the same as the website.
-			idx.entryNoMap[entryNo] = append(idx.entryNoMap[entryNo], j)
+			b.variants = append(b.variants, wrappedVariant{
+				variantIndex:   index,
+				NormalizedForm: strings.ToLower(Normalize(variant, true)),
+			})
 		}
 
-		inverseIdx[lemma.Lemma] = idx
-		if normalized := Normalize(lemma.Lemma, false); normalized != lemma.Lemma { // lemma has normalized form
-			// p.s. not removing punctuation here to make exact match.
-			// Don't want `s.t` to have the result of `st.` or other similar case since it's probably wrong.
-			// So for now only care for removing diacritics.
-			//
-			// If the inverseNormalizedIndex of the normalized lemma is already occupied, ignore (only use the first one).
-			if _, ok := inverseNormalizedIndex[normalized]; !ok {
-				inverseNormalizedIndex[normalized] = idx
+		for _, variant := range def.EntryVariants {
+			indexVariant(variant, VariantKindEntry)
+		}
+		for _, variant := range def.WordVariants {
+			indexVariant(variant, VariantKindWord)
+		}
+
+		for _, nonStandard := range def.NonStandardWords {
+			// same as variantIdx, only use the first one if the non-standard form is already occupied.
+			if key := variantKey(nonStandard); key != "" {
+				if _, ok := b.standardFormIndex[key]; !ok {
+					b.standardFormIndex[key] = &standardFormIndex{idx: i, entryIdx: j}
+				}
 			}
 		}
 
-		lemmaLength := len(lemma.Lemma)
-		longestLemmaLength = max(longestLemmaLength, lemmaLength)
-		lemmas = append(lemmas, wrappedLemma{
-			Lemma:          lemma,
-			NormalizedForm: Normalize(lemma.Lemma, true),
-		})
+		// lookup and map entry index if any
+		_, entryNo, ok := FindEntryNoFromLemma(def.Entry)
+		if !ok {
+			continue
+		}
+
+		// there can be multiple entries with same number. E.g. ketak (4)
+		// could be misinput from KBBI but for now making the behavior the same as the website.
+		entryNoMap[entryNo] = append(entryNoMap[entryNo], j)
 	}
 
+	b.inverseIndex[lemma.Lemma] = i
+	if normalized := Normalize(lemma.Lemma, false); normalized != lemma.Lemma { // lemma has normalized form
+		// p.s. not removing punctuation here to make exact match.
+		// Don't want `s.t` to have the result of `st.` or other similar case since it's probably wrong.
+		// So for now only care for removing diacritics.
+		//
+		// If the inverseNormalizedIndex of the normalized lemma is already occupied, ignore (only use the first one).
+		if _, ok := b.inverseNormalizedIndex[normalized]; !ok {
+			b.inverseNormalizedIndex[normalized] = i
+		}
+	}
+
+	b.longest = max(b.longest, len(lemma.Lemma))
+	b.lemmas = append(b.lemmas, wrappedLemma{
+		Lemma:          lemma,
+		NormalizedForm: Normalize(lemma.Lemma, true),
+		entryNoMap:     entryNoMap,
+	})
+}
+
+func (b *dictionaryBuilder) build(stats Stats, wotd WOTDRepo) *Dictionary {
+	b.sortLemmas()
+
+	// stable sort to keep the variants order in the dictionary for the same normalized form.
+	slices.SortStableFunc(b.variants, func(a, b wrappedVariant) int {
+		return strings.Compare(a.NormalizedForm, b.NormalizedForm)
+	})
+
+	b.lemmas = slices.Clip(b.lemmas)
 	return &Dictionary{
-		wotd:                   wotd,
-		stats:                  assetData.Stats,
-		longestLemmaLength:     longestLemmaLength,
-		inverseIndex:           inverseIdx,
-		inverseNormalizedIndex: inverseNormalizedIndex,
-		lemmas:                 lemmas,
-	}, nil
+		wotd:  wotd,
+		stats: stats,
+		index: &b.memoryIndex,
+	}
+}
+
+// sortLemmas sorts the lemmas by their normalized form for [Dictionary.Search], and moves the indexes along with them.
+// The sort is stable to keep the order in the dictionary for the same normalized form. The order in the dictionary
+// is still kept in assetOrder, since the word of the day refers to the lemmas by it.
+func (b *dictionaryBuilder) sortLemmas() {
+	order := make([]int, len(b.lemmas))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(i, j int) int {
+		return strings.Compare(b.lemmas[i].NormalizedForm, b.lemmas[j].NormalizedForm)
+	})
+
+	sorted := make([]wrappedLemma, len(b.lemmas))
+	b.assetOrder = make([]int, len(b.lemmas))
+	for pos, i := range order {
+		sorted[pos] = b.lemmas[i]
+		b.assetOrder[i] = pos
+	}
+	b.lemmas = sorted
+
+	for key, i := range b.inverseIndex {
+		b.inverseIndex[key] = b.assetOrder[i]
+	}
+	for key, i := range b.inverseNormalizedIndex {
+		b.inverseNormalizedIndex[key] = b.assetOrder[i]
+	}
+	// every variant index is in the variants, including the ones in variantIndex.
+	for _, variant := range b.variants {
+		variant.idx = b.assetOrder[variant.idx]
+	}
+	for _, index := range b.standardFormIndex {
+		index.idx = b.assetOrder[index.idx]
+	}
+}
+
+// decodeAssetData decodes the JSON of [AssetData] from r, and calls add for each lemma as soon as it is decoded,
+// instead of decoding all of them into a slice. The keys may come in any order, and the unknown ones are skipped.
+func decodeAssetData(r io.Reader, add func(kbbi.Lemma)) (Stats, error) {
+	dec := json.NewDecoder(r)
+	if err := expectDelim(dec, '{'); err != nil {
+		return Stats{}, err
+	}
+
+	var stats Stats
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return Stats{}, fmt.Errorf("dec.Token: %w", err)
+		}
+
+		switch tok {
+		case "stats":
+			if err := dec.Decode(&stats); err != nil {
+				return Stats{}, fmt.Errorf("stats: %w", err)
+			}
+		case "lemmas":
+			if err := decodeLemmas(dec, add); err != nil {
+				return Stats{}, fmt.Errorf("lemmas: %w", err)
+			}
+		default:
+			var skipped json.RawMessage
+			if err := dec.Decode(&skipped); err != nil {
+				return Stats{}, fmt.Errorf("%v: %w", tok, err)
+			}
+		}
+	}
+
+	if err := expectDelim(dec, '}'); err != nil {
+		return Stats{}, err
+	}
+
+	return stats, nil
+}
+
+func decodeLemmas(dec *json.Decoder, add func(kbbi.Lemma)) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return fmt.Errorf("dec.Token: %w", err)
+	}
+	if tok == nil {
+		return nil
+	}
+	if tok != json.Delim('[') {
+		return fmt.Errorf("unexpected %v, expected [", tok)
+	}
+
+	for i := 0; dec.More(); i++ {
+		var lemma kbbi.Lemma
+		if err := dec.Decode(&lemma); err != nil {
+			return fmt.Errorf("lemma %d: %w", i, err)
+		}
+		add(lemma)
+	}
+
+	return expectDelim(dec, ']')
+}
+
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return fmt.Errorf("dec.Token: %w", err)
+	}
+	if tok != delim {
+		return fmt.Errorf("unexpected %v, expected %v", tok, delim)
+	}
+	return nil
 }
 
 func (d *Dictionary) indexInDictRange(idx int) bool {
-	return 0 <= idx && idx < len(d.lemmas)
+	return 0 <= idx && idx < d.index.lemmaCount()
 }
 
 func (d *Dictionary) Stats() Stats {
 	return d.stats
 }
 
+// Sources returns where the assets of the dictionary are read from.
+func (d *Dictionary) Sources() AssetSources {
+	return d.sources
+}
+
+// Current returns the dictionary itself, so the dictionary which is never reloaded is a [SnapshotRepo] of its own.
+func (d *Dictionary) Current() DictionaryRepo {
+	return d
+}
+
 func (d *Dictionary) Lemma(lemma string, entryNo int) (kbbi.Lemma, error) {
 	if lemma == "" {
 		return kbbi.Lemma{}, ErrUnexpectedEmptyLemma
 	}
 
-	if len(lemma) > d.longestLemmaLength {
+	if len(lemma) > d.index.longestLemmaLength() {
 		return kbbi.Lemma{}, ErrLemmaTooLong
 	}
 
-	index := d.lookupInverseIndex(lemma)
-	if index == nil {
+	idx, ok := d.lookupInverseIndex(lemma)
+	if !ok {
 		return kbbi.Lemma{}, ErrLemmaNotFound
 	}
 
-	lemmaData := d.lemmas[index.idx]
-
 	if entryNo < 0 {
 		return kbbi.Lemma{}, ErrUnexpectedEntryNumber
 	}
 
+	lemmaData, err := d.index.lemma(idx)
+	if err != nil {
+		return kbbi.Lemma{}, err
+	}
+
 	if entryNo > 0 {
 		if entryNo > len(lemmaData.Entries) {
 			return kbbi.Lemma{}, ErrEntryNotFound
 		}
 
-		entryIndexes, ok := index.entryNoMap[entryNo]
+		entryIndexes, ok := d.index.entryNoMap(idx)[entryNo]
 		if !ok {
 			return kbbi.Lemma{}, ErrEntryNotFound
 		}
 
-		lemmaData.Entries = lo.Map(entryIndexes, func(idx int, _ int) kbbi.Entry {
-			return lemmaData.Entries[idx]
-		})
+		entries := make([]kbbi.Entry, 0, len(entryIndexes))
+		for _, entryIdx := range entryIndexes {
+			if entryIdx >= len(lemmaData.Entries) {
+				return kbbi.Lemma{}, fmt.Errorf("%w: lemma %d has no entry %d", ErrInvalidCompiledDictionary, idx, entryIdx)
+			}
+			entries = append(entries, lemmaData.Entries[entryIdx])
+		}
+		lemmaData.Entries = entries
 	}
 
-	return lemmaData.Lemma, nil
+	return lemmaData, nil
 }
 
-func (d *Dictionary) lookupInverseIndex(lemma string) *lemmaIndex {
-	// lookup on exact index first
-	index, ok := d.inverseIndex[lemma]
-	if ok {
-		return index
+// lemmaEntry returns the lemma at idx with only its entry at entryIdx.
+func (d *Dictionary) lemmaEntry(idx, entryIdx int) (kbbi.Lemma, error) {
+	lemmaData, err := d.index.lemma(idx)
+	if err != nil {
+		return kbbi.Lemma{}, err
 	}
 
-	// if not found, normalize the lemma, and check on the normalized index
-	normalized := Normalize(lemma, false)
-	index, ok = d.inverseNormalizedIndex[normalized]
-	if ok {
-		return index
+	// the entry is only out of range in the invalid compiled dictionary.
+	if entryIdx >= len(lemmaData.Entries) {
+		return kbbi.Lemma{}, fmt.Errorf("%w: lemma %d has no entry %d", ErrInvalidCompiledDictionary, idx, entryIdx)
 	}
+	lemmaData.Entries = []kbbi.Entry{lemmaData.Entries[entryIdx]}
 
-	// otherwise not found
-	return nil
+	return lemmaData, nil
 }
 
-func (d *Dictionary) RandomLemma() kbbi.Lemma {
-	randomIdx := rand.IntN(len(d.lemmas))
-	return d.lemmas[randomIdx].Lemma
+func (d *Dictionary) lookupInverseIndex(lemma string) (int, bool) {
+	// lookup on exact index first
+	if idx, ok := d.index.exact(lemma); ok {
+		return idx, true
+	}
+
+	// if not found, normalize the lemma, and check on the normalized index
+	return d.index.normalized(Normalize(lemma, false))
+}
+
+func (d *Dictionary) RandomLemma() (kbbi.Lemma, error) {
+	randomIdx := rand.IntN(d.index.lemmaCount())
+	return d.index.lemma(randomIdx)
 }
 
 func (d *Dictionary) LemmaOfTheDay() (kbbi.Lemma, error) {
@@ -182,31 +426,76 @@ func (d *Dictionary) LemmaOfTheDay() (kbbi.Lemma, error) {
 		return kbbi.Lemma{}, fmt.Errorf("%w: %d", ErrUnexpectedWotdIndex, idx)
 	}
 
-	return d.lemmas[idx].Lemma, nil
+	return d.index.lemma(d.index.assetLemma(idx))
 }
 
 // Search provides a list of lemmas based on prefix, number of result depends on limit.
 // Search behaves similarly with search feature on the KBBI application.
+// Lemmas which have entry variants matching the prefix are included after the matching lemmas.
 //
 // If prefix is empty, Search returns top limit lemmas.
-func (d *Dictionary) Search(prefix string, limit uint) []kbbi.Lemma {
+func (d *Dictionary) Search(prefix string, limit uint) ([]kbbi.Lemma, error) {
+	var (
+		results []kbbi.Lemma
+		seen    = map[int]struct{}{}
+	)
+
+	add := func(idx int) error {
+		if _, ok := seen[idx]; ok {
+			return nil
+		}
+		seen[idx] = struct{}{}
+
+		lemma, err := d.index.lemma(idx)
+		if err != nil {
+			return err
+		}
+		results = append(results, lemma)
+		return nil
+	}
+
 	if prefix == "" {
-		return lo.Map(d.lemmas[:min(int(limit), len(d.lemmas))], func(lemma wrappedLemma, _ int) kbbi.Lemma { return lemma.Lemma })
+		for idx := range min(int(limit), d.index.lemmaCount()) {
+			if err := add(idx); err != nil {
+				return nil, err
+			}
+		}
+		return results, nil
 	}
 
 	prefix = strings.ToLower(Normalize(prefix, true))
 
-	leftIdx, _ := slices.BinarySearchFunc(d.lemmas, prefix, func(curr wrappedLemma, search string) int {
-		return strings.Compare(curr.NormalizedForm, search)
+	// the ranges of the short prefixes are large, so they are only walked until the limit is reached.
+	leftIdx, rightIdx := prefixRange(d.index.lemmaCount(), prefix, d.index.normalizedForm)
+	for idx := leftIdx; idx < rightIdx && uint(len(results)) < limit; idx++ {
+		if err := add(idx); err != nil {
+			return nil, err
+		}
+	}
+
+	leftIdx, rightIdx = prefixRange(d.index.variantCount(), prefix, d.index.variantNormalizedForm)
+	for k := leftIdx; k < rightIdx && uint(len(results)) < limit; k++ {
+		if err := add(d.index.variantLemma(k)); err != nil {
+			return nil, err
+		}
+	}
+
+	return results, nil
+}
+
+// prefixRange returns the range [left, right) of n sorted items which key has the given prefix.
+func prefixRange(n int, prefix string, key func(i int) string) (int, int) {
+	leftIdx := sort.Search(n, func(i int) bool {
+		return key(i) >= prefix
 	})
 
-	rightIdx, _ := slices.BinarySearchFunc(d.lemmas, prefix+"\uffff", func(curr wrappedLemma, search string) int {
-		return strings.Compare(curr.NormalizedForm, search)
+	rightIdx := sort.Search(n, func(i int) bool {
+		return key(i) >= prefix+"\uffff"
 	})
 
-	if leftIdx >= len(d.lemmas) || rightIdx < leftIdx || !strings.HasPrefix(d.lemmas[leftIdx].NormalizedForm, prefix) {
-		return nil
+	if leftIdx >= n || rightIdx < leftIdx || !strings.HasPrefix(key(leftIdx), prefix) {
+		return 0, 0
 	}
 
-	return lo.Map(d.lemmas[leftIdx:rightIdx][:min(limit, uint(rightIdx-leftIdx))], func(lemma wrappedLemma, _ int) kbbi.Lemma { return lemma.Lemma })
+	return leftIdx, rightIdx
 }
@@ -0,0 +1,445 @@
+package dictionary_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/raf555/kbbi-api/internal/dictionary"
+	"github.com/raf555/kbbi-api/pkg/kbbi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLemma(lemma string, entries ...kbbi.Entry) kbbi.Lemma {
+	if len(entries) == 0 {
+		entries = []kbbi.Entry{{Entry: lemma}}
+	}
+	return kbbi.Lemma{Lemma: lemma, Entries: entries}
+}
+
+// sealTestAsset seals the JSON content as the asset of the kind.
+func sealTestAsset(t *testing.T, key []byte, kind dictionary.AssetKind, content string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	_, err := dictionary.WriteAsset(&buf, json.RawMessage(content), key, dictionary.EnvelopeHeader{ContentType: kind.ContentType()})
+	require.NoError(t, err)
+	return buf.Bytes()
+}
+
+func newTestDictionary(lemmas ...kbbi.Lemma) *dictionary.Dictionary {
+	slices.SortFunc(lemmas, func(a, b kbbi.Lemma) int {
+		return compareNormalized(a.Lemma, b.Lemma)
+	})
+	return dictionary.NewDictionaryFromAssetData(dictionary.AssetData{Lemmas: lemmas}, nil)
+}
+
+func compareNormalized(a, b string) int {
+	a, b = dictionary.Normalize(a, true), dictionary.Normalize(b, true)
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func TestNewReloader_Dictionary(t *testing.T) {
+	key := bytes.Repeat([]byte{1}, 32)
+
+	tcs := []struct {
+		name    string
+		content string
+
+		expectedStats dictionary.Stats
+		expectedErr   string
+	}{
+		{
+			name:          "stats first",
+			content:       `{"stats":{"edition":"VI","lemmaCount":2},"lemmas":[{"lemma":"anak"},{"lemma":"apel"}]}`,
+			expectedStats: dictionary.Stats{Edition: "VI", LemmaCount: 2},
+		},
+		{
+			name:          "lemmas first with unknown key",
+			content:       `{"lemmas":[{"lemma":"anak"},{"lemma":"apel"}],"source":{"from":"kbbi"},"stats":{"edition":"VI"}}`,
+			expectedStats: dictionary.Stats{Edition: "VI"},
+		},
+		{
+			name:        "lemmas is not a list",
+			content:     `{"lemmas":{"lemma":"anak"}}`,
+			expectedErr: "lemmas: unexpected {, expected [",
+		},
+		{
+			name:        "invalid lemma",
+			content:     `{"lemmas":[{"lemma":"anak"},{"lemma":1}]}`,
+			expectedErr: "lemma 1",
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			f, err := os.Create(filepath.Join(dir, dictionary.AssetKindDictionary.Filename()))
+			require.NoError(t, err)
+			_, err = dictionary.WriteAsset(f, json.RawMessage(tc.content), key, dictionary.EnvelopeHeader{
+				ContentType: dictionary.AssetKindDictionary.ContentType(),
+			})
+			require.NoError(t, err)
+			require.NoError(t, f.Close())
+			wotd := sealTestAsset(t, key, dictionary.AssetKindWOTD, `[1]`)
+			require.NoError(t, os.WriteFile(filepath.Join(dir, dictionary.AssetKindWOTD.Filename()), wotd, 0o600))
+
+			cfg := dictionary.Configuration{AssetsEncryptionKey: key, AssetsDirectory: dir}
+			reloader, err := dictionary.NewReloader(t.Context(), cfg, slog.New(slog.DiscardHandler))
+			if tc.expectedErr != "" {
+				assert.ErrorContains(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			dict := reloader.Current()
+			assert.Equal(t, tc.expectedStats, dict.Stats())
+
+			lemma, err := dict.Lemma("apel", 0)
+			require.NoError(t, err)
+			assert.Equal(t, "apel", lemma.Lemma)
+		})
+	}
+}
+
+func TestDictionary_Lookup(t *testing.T) {
+	dict := newTestDictionary(
+		newTestLemma("anak"),
+		newTestLemma("lari"),
+		newTestLemma("merah"),
+		newTestLemma("pohon"),
+		newTestLemma("balik"),
+		newTestLemma("kupu-kupu"),
+		newTestLemma("apel", kbbi.Entry{Entry: "apel (1)"}, kbbi.Entry{Entry: "apel (2)"}),
+		newTestLemma("terselip",
+			kbbi.Entry{Entry: "ter.se.lip (1)"},
+			kbbi.Entry{Entry: "ter.se.lip (2)", EntryVariants: []string{"terselip ke luar"}},
+		),
+		newTestLemma("ude", kbbi.Entry{Entry: "ude", WordVariants: []string{"udeh"}}),
+		newTestLemma("apotek", kbbi.Entry{Entry: "apo.tek", NonStandardWords: []string{"apotik"}}),
+		newTestLemma("aktif", kbbi.Entry{Entry: "ak.tif", NonStandardWords: []string{"aktip"}}),
+		newTestLemma("aktip", kbbi.Entry{Entry: "ak.tip"}),
+	)
+
+	tcs := []struct {
+		in      string
+		entryNo int
+
+		expectedErr           error
+		expectedLemma         string
+		expectedEntries       int
+		expectedMatch         dictionary.MatchKind
+		expectedStandardForm  string
+		expectedVariant       *dictionary.VariantMatch
+		expectedReduplication *dictionary.Reduplication
+	}{
+		{
+			in:              "anak",
+			expectedLemma:   "anak",
+			expectedEntries: 1,
+			expectedMatch:   dictionary.MatchExact,
+		},
+		{
+			in:              "kupu-kupu",
+			expectedLemma:   "kupu-kupu",
+			expectedEntries: 1,
+			expectedMatch:   dictionary.MatchExact,
+		},
+		{
+			in:                    "anak-anak",
+			expectedLemma:         "anak",
+			expectedEntries:       1,
+			expectedMatch:         dictionary.MatchReduplication,
+			expectedReduplication: &dictionary.Reduplication{Type: dictionary.ReduplicationFull, BaseWord: "anak"},
+		},
+		{
+			in:                    "berlari-lari",
+			expectedLemma:         "lari",
+			expectedEntries:       1,
+			expectedMatch:         dictionary.MatchReduplication,
+			expectedReduplication: &dictionary.Reduplication{Type: dictionary.ReduplicationAffixed, BaseWord: "lari", Prefix: "ber"},
+		},
+		{
+			in:                    "kemerah-merahan",
+			expectedLemma:         "merah",
+			expectedEntries:       1,
+			expectedMatch:         dictionary.MatchReduplication,
+			expectedReduplication: &dictionary.Reduplication{Type: dictionary.ReduplicationAffixed, BaseWord: "merah", Prefix: "ke", Suffix: "an"},
+		},
+		{
+			in:                    "pepohonan",
+			expectedLemma:         "pohon",
+			expectedEntries:       1,
+			expectedMatch:         dictionary.MatchReduplication,
+			expectedReduplication: &dictionary.Reduplication{Type: dictionary.ReduplicationPartial, BaseWord: "pohon"},
+		},
+		{
+			in:              "apel-apel",
+			entryNo:         2,
+			expectedLemma:   "apel",
+			expectedEntries: 1,
+			expectedMatch:   dictionary.MatchReduplication,
+			expectedReduplication: &dictionary.Reduplication{
+				Type:     dictionary.ReduplicationFull,
+				BaseWord: "apel",
+			},
+		},
+		{
+			in:          "apel-apel",
+			entryNo:     3,
+			expectedErr: dictionary.ErrEntryNotFound,
+		},
+		{
+			in:              "terselip ke luar",
+			expectedLemma:   "terselip",
+			expectedEntries: 1,
+			expectedMatch:   dictionary.MatchVariant,
+			expectedVariant: &dictionary.VariantMatch{Form: "terselip ke luar", Kind: dictionary.VariantKindEntry, Entry: "ter.se.lip (2)"},
+		},
+		{
+			in:              "Terselip  ke luar",
+			entryNo:         1,
+			expectedLemma:   "terselip",
+			expectedEntries: 1,
+			expectedMatch:   dictionary.MatchVariant,
+			expectedVariant: &dictionary.VariantMatch{Form: "terselip ke luar", Kind: dictionary.VariantKindEntry, Entry: "ter.se.lip (2)"},
+		},
+		{
+			in:              "udeh",
+			expectedLemma:   "ude",
+			expectedEntries: 1,
+			expectedMatch:   dictionary.MatchVariant,
+			expectedVariant: &dictionary.VariantMatch{Form: "udeh", Kind: dictionary.VariantKindWord, Entry: "ude"},
+		},
+		{
+			in:                   "apotik",
+			expectedLemma:        "apotek",
+			expectedEntries:      1,
+			expectedMatch:        dictionary.MatchNonStandard,
+			expectedStandardForm: "apotek",
+		},
+		{
+			in:                   "aktip",
+			expectedLemma:        "aktip",
+			expectedEntries:      1,
+			expectedMatch:        dictionary.MatchExact,
+			expectedStandardForm: "aktif",
+		},
+		{
+			in:          "tas-tas",
+			expectedErr: dictionary.ErrLemmaNotFound,
+		},
+		{
+			in:          "jeruk",
+			expectedErr: dictionary.ErrLemmaNotFound,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(fmt.Sprintf("input=%s (entryNo=%d)", tc.in, tc.entryNo), func(t *testing.T) {
+			result, err := dict.Lookup(tc.in, tc.entryNo)
+
+			if tc.expectedErr != nil {
+				assert.ErrorIs(t, err, tc.expectedErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedLemma, result.Lemma.Lemma)
+			assert.Len(t, result.Lemma.Entries, tc.expectedEntries)
+			assert.Equal(t, tc.expectedMatch, result.Match)
+			assert.Equal(t, tc.expectedStandardForm, result.StandardForm)
+			assert.Equal(t, tc.expectedVariant, result.Variant)
+			assert.Equal(t, tc.expectedReduplication, result.Reduplication)
+		})
+	}
+}
+
+func TestDictionary_Search(t *testing.T) {
+	dict := newTestDictionary(
+		newTestLemma("selip"),
+		newTestLemma("terselip", kbbi.Entry{Entry: "ter.se.lip", EntryVariants: []string{"terselip ke luar"}}),
+		newTestLemma("tersembunyi"),
+		newTestLemma("teras"),
+		newTestLemma("lari", kbbi.Entry{Entry: "la.ri", EntryVariants: []string{"terlari-lari"}}),
+	)
+
+	tcs := []struct {
+		prefix   string
+		limit    uint
+		expected []string
+	}{
+		{
+			prefix:   "",
+			limit:    2,
+			expected: []string{"lari", "selip"},
+		},
+		{
+			prefix:   "ters",
+			limit:    10,
+			expected: []string{"terselip", "tersembunyi"},
+		},
+		{
+			prefix:   "ters",
+			limit:    1,
+			expected: []string{"terselip"},
+		},
+		{
+			prefix:   "terselip ke",
+			limit:    10,
+			expected: []string{"terselip"},
+		},
+		{
+			prefix:   "terl",
+			limit:    10,
+			expected: []string{"lari"},
+		},
+		{
+			prefix:   "ter",
+			limit:    10,
+			expected: []string{"teras", "terselip", "tersembunyi", "lari"},
+		},
+		{
+			prefix:   "xyz",
+			limit:    10,
+			expected: []string{},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(fmt.Sprintf("prefix=%s (limit=%d)", tc.prefix, tc.limit), func(t *testing.T) {
+			result, err := dict.Search(tc.prefix, tc.limit)
+			require.NoError(t, err)
+
+			lemmas := []string{}
+			for _, lemma := range result {
+				lemmas = append(lemmas, lemma.Lemma)
+			}
+			assert.Equal(t, tc.expected, lemmas)
+		})
+	}
+}
+
+func TestDictionary_Suggest(t *testing.T) {
+	dict := newTestDictionary(
+		newTestLemma("apel"),
+		newTestLemma("apelan"),
+		newTestLemma("apotek"),
+		newTestLemma("buku"),
+		newTestLemma("lari"),
+		newTestLemma("berlari"),
+		newTestLemma("selip"),
+	)
+
+	tcs := []struct {
+		in       string
+		limit    int
+		expected []string
+	}{
+		{
+			in:       "",
+			limit:    5,
+			expected: nil,
+		},
+		{
+			in:       "apell",
+			limit:    0,
+			expected: nil,
+		},
+		{
+			in:       "apell",
+			limit:    5,
+			expected: []string{"apel", "apelan"},
+		},
+		{
+			in:       "bukunya",
+			limit:    5,
+			expected: []string{"buku"},
+		},
+		{
+			in:       "berlarilah",
+			limit:    5,
+			expected: []string{"berlari", "lari"},
+		},
+		{
+			in:       "apotekxyz",
+			limit:    5,
+			expected: []string{"apotek"},
+		},
+		{
+			in:       "apelxyz",
+			limit:    1,
+			expected: []string{"apel"},
+		},
+		{
+			in:       "zzz",
+			limit:    5,
+			expected: nil,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(fmt.Sprintf("input=%s (limit=%d)", tc.in, tc.limit), func(t *testing.T) {
+			assert.Equal(t, tc.expected, dict.Suggest(tc.in, tc.limit))
+		})
+	}
+}
+
+// todayWOTD is a [dictionary.WOTDRepo] of the 1-based indexes, which lemma of the day is always the first one.
+type todayWOTD []int
+
+func (w todayWOTD) RandomLemmaIndex() int { return w[0] }
+func (w todayWOTD) TodayLemmaIndex() int  { return w[0] }
+func (w todayWOTD) LemmaIndexes() []int   { return w }
+
+func TestDictionary_UnsortedAsset(t *testing.T) {
+	data := dictionary.AssetData{Lemmas: []kbbi.Lemma{
+		newTestLemma("teras"),
+		newTestLemma("lari"),
+		newTestLemma("tersembunyi"),
+		newTestLemma("selip"),
+	}}
+	dict := dictionary.NewDictionaryFromAssetData(data, todayWOTD{3})
+
+	var buf bytes.Buffer
+	_, err := dict.Compile(&buf)
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "dict.bin")
+	require.NoError(t, os.WriteFile(path, buf.Bytes(), 0o600))
+	compiled, _, err := dictionary.OpenCompiledDictionary(path, todayWOTD{3})
+	require.NoError(t, err)
+
+	for name, dict := range map[string]*dictionary.Dictionary{"memory": dict, "compiled": compiled} {
+		t.Run(name, func(t *testing.T) {
+			result, err := dict.Search("ter", 10)
+			require.NoError(t, err)
+
+			lemmas := []string{}
+			for _, lemma := range result {
+				lemmas = append(lemmas, lemma.Lemma)
+			}
+			assert.Equal(t, []string{"teras", "tersembunyi"}, lemmas)
+
+			// the word of the day refers to the order in the asset, not the sorted one.
+			lemma, err := dict.LemmaOfTheDay()
+			require.NoError(t, err)
+			assert.Equal(t, "tersembunyi", lemma.Lemma)
+
+			lemma, err = dict.Lemma("selip", 0)
+			require.NoError(t, err)
+			assert.Equal(t, "selip", lemma.Lemma)
+		})
+	}
+}
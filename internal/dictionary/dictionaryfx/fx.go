@@ -1,6 +1,8 @@
 package dictionaryfx
 
 import (
+	"context"
+
 	"github.com/raf555/kbbi-api/internal/dictionary"
 	httpfx "github.com/raf555/kbbi-api/internal/http/fx"
 	"github.com/raf555/salome/config/v1"
@@ -14,21 +16,41 @@ var Module = fx.Module(
 
 	fx.Provide(
 		fx.Annotate(
-			dictionary.NewWOTD,
-			fx.As(new(dictionary.WOTDRepo)),
+			dictionary.NewReloader,
+			fx.As(new(dictionary.SnapshotRepo)),
+			fx.As(fx.Self()),
 		),
-		fx.Private,
 	),
 
+	httpfx.HandlerProvider(
+		dictionary.NewHTTPHandler,
+	),
+
+	httpfx.HandlerProvider(
+		dictionary.NewAdminHTTPHandler,
+	),
+)
+
+// Snapshot provides the snapshot loaded at startup as [dictionary.DictionaryRepo] and *dictionary.Dictionary.
+// It is never updated by the reloads, so it is only for the one-shot CLI commands, and must not be used with the server,
+// whose components take the current snapshot from [dictionary.SnapshotRepo] on every request instead.
+var Snapshot = fx.Module(
+	"dictionary_snapshot",
 	fx.Provide(
 		fx.Annotate(
-			dictionary.NewDictionary,
+			(*dictionary.Reloader).Dictionary,
 			fx.As(new(dictionary.DictionaryRepo)),
 			fx.As(fx.Self()),
 		),
 	),
+)
 
-	httpfx.HandlerProvider(
-		dictionary.NewHTTPHandler,
-	),
+// ReloadInvoker reloads the dictionary on SIGHUP and on the changes of the asset files while the application is running,
+// see [dictionary.Reloader.Watch].
+var ReloadInvoker = fx.Module(
+	"dictionary_reload",
+	fx.Invoke(func(ctx context.Context, r *dictionary.Reloader) {
+		// ctx is canceled when the application stops.
+		go r.Watch(ctx)
+	}),
 )
@@ -0,0 +1,450 @@
+package dictionary
+
+import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"mime"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	defaultDownloadTimeout = 30 * time.Second
+	defaultDownloadBackoff = time.Second
+)
+
+// errNotModified is returned by the conditional request if the asset hasn't changed since the last response.
+var errNotModified = errors.New("not modified")
+
+// downloader downloads the assets with retries, and remembers the validators of the last response of each URL,
+// so the poller only downloads the asset once it changes, see [downloader.poll].
+// If the cache is set, the downloaded assets are kept in it once the snapshot which reads them is loaded, and the cached
+// asset is read instead if it is not modified or the URL is unreachable, see [downloader.commit].
+type downloader struct {
+	client *http.Client
+	// timeout is the timeout of each attempt, including reading the body.
+	timeout             time.Duration
+	retries             int
+	backoff, maxBackoff time.Duration
+	cache               *assetCache
+	logger              *slog.Logger
+
+	mu         sync.Mutex
+	validators map[string]validators
+	// prefetched are the assets downloaded by the poller, which are read by the next download of the same URL
+	// instead of downloading them again.
+	prefetched map[string]downloadedAsset
+	// downloaded are the assets downloaded for the snapshot which is being loaded, which are cached by commit.
+	downloaded map[string]downloadedAsset
+}
+
+// downloadedAsset is the asset spooled to a file, path is the file in the cache directory if the cache is set.
+type downloadedAsset struct {
+	body io.ReadSeekCloser
+	path string
+	v    validators
+}
+
+// validators are the headers of the response which identify the version of the asset.
+type validators struct {
+	etag, lastModified string
+	// sha256 is the hash of the downloaded asset, which detects the change if the validators don't.
+	// It is only known once the asset is read to the end.
+	sha256 string
+}
+
+func (v validators) empty() bool {
+	return v.etag == "" && v.lastModified == ""
+}
+
+func newDownloader(cfg Configuration, logger *slog.Logger) *downloader {
+	backoff := cmp.Or(cfg.DownloadBackoff, defaultDownloadBackoff)
+	d := &downloader{
+		client:     http.DefaultClient,
+		timeout:    cmp.Or(cfg.DownloadTimeout, defaultDownloadTimeout),
+		retries:    cfg.DownloadRetries,
+		backoff:    backoff,
+		maxBackoff: max(cfg.DownloadMaxBackoff, backoff),
+		logger:     logger,
+		validators: make(map[string]validators),
+		prefetched: make(map[string]downloadedAsset),
+		downloaded: make(map[string]downloadedAsset),
+	}
+	if cfg.CacheDirectory != "" {
+		d.cache = &assetCache{dir: cfg.CacheDirectory}
+	}
+	return d
+}
+
+// download returns the body of the URL and where it is read from, the body must be closed.
+// The asset prefetched by poll is returned instead if any, as a seekable file. Without the cache, the body is streamed
+// from the response, and the timeout of the last attempt covers reading it.
+func (d *downloader) download(ctx context.Context, url string) (io.ReadCloser, AssetSource, error) {
+	d.mu.Lock()
+	prefetched, ok := d.prefetched[url]
+	delete(d.prefetched, url)
+	if ok {
+		d.replaceDownloaded(url, prefetched)
+	}
+	d.mu.Unlock()
+	if ok {
+		return prefetched.body, AssetSourceNetwork, nil
+	}
+
+	if d.cache != nil {
+		return d.downloadCached(ctx, url)
+	}
+
+	body, v, err := d.get(ctx, url, validators{})
+	if err != nil {
+		return nil, "", err
+	}
+
+	d.mu.Lock()
+	d.validators[url] = v
+	d.mu.Unlock()
+
+	return &hashingBody{ReadCloser: body, hash: sha256.New(), done: func(sum string) {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		// the validators may already be replaced by the poller.
+		if last, ok := d.validators[url]; ok && last.etag == v.etag && last.lastModified == v.lastModified {
+			last.sha256 = sum
+			d.validators[url] = last
+		}
+	}}, AssetSourceNetwork, nil
+}
+
+// downloadCached downloads the asset with the validators of the cached one, and reads the cached asset if it is
+// not modified, or if the URL is unreachable after the retries. The other errors, e.g. 404, are returned as is,
+// so a removed or misconfigured URL isn't hidden by the cached asset.
+func (d *downloader) downloadCached(ctx context.Context, url string) (io.ReadCloser, AssetSource, error) {
+	entry, cached, err := d.cache.entry(url)
+	if err != nil {
+		d.logger.Warn("Failed to read cached asset", slog.String("url", url), slog.String("error", err.Error()))
+	}
+
+	var last validators
+	if cached {
+		last = entry.validators()
+	}
+
+	body, v, err := d.get(ctx, url, last)
+	switch {
+	case errors.Is(err, errNotModified):
+		f, cacheErr := d.cache.open(entry)
+		if cacheErr == nil {
+			d.setValidators(url, last)
+			return f, AssetSourceCache, nil
+		}
+
+		d.logger.Warn("Failed to open cached asset, downloading it again",
+			slog.String("url", url),
+			slog.String("error", cacheErr.Error()),
+		)
+		body, v, err = d.get(ctx, url, validators{})
+		if err != nil {
+			return nil, "", err
+		}
+	case err != nil:
+		var retryable *retryableError
+		if !cached || ctx.Err() != nil || !errors.As(err, &retryable) {
+			return nil, "", err
+		}
+
+		f, cacheErr := d.cache.open(entry)
+		if cacheErr != nil {
+			return nil, "", fmt.Errorf("%w (cached asset: %w)", err, cacheErr)
+		}
+
+		d.logger.Warn("Failed to download asset, reading the cached one",
+			slog.String("url", url),
+			slog.Time("downloaded_at", entry.DownloadedAt),
+			slog.String("error", err.Error()),
+		)
+		d.setValidators(url, last)
+		return f, AssetSourceCacheFallback, nil
+	}
+
+	asset, err := d.spool(body, v)
+	if err != nil {
+		return nil, "", err
+	}
+
+	d.mu.Lock()
+	d.validators[url] = asset.v
+	d.replaceDownloaded(url, asset)
+	d.mu.Unlock()
+
+	return asset.body, AssetSourceNetwork, nil
+}
+
+// poll sends the conditional request with the validators of the last response of the URL, and reports whether
+// the asset has changed. The asset is unchanged if the server responds with the same validators, or if it has
+// the same hash as the last one. The changed asset is kept for the next download of the URL.
+func (d *downloader) poll(ctx context.Context, url string) (bool, error) {
+	d.mu.Lock()
+	last := d.validators[url]
+	d.mu.Unlock()
+
+	body, v, err := d.get(ctx, url, last)
+	if errors.Is(err, errNotModified) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	// the server may ignore the conditional request, and send the same asset again.
+	if !v.empty() && v.etag == last.etag && v.lastModified == last.lastModified {
+		_ = body.Close()
+		return false, nil
+	}
+
+	asset, err := d.spool(body, v)
+	if err != nil {
+		return false, err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	last = d.validators[url]
+	d.validators[url] = asset.v
+	if last.sha256 != "" && asset.v.sha256 == last.sha256 {
+		asset.remove()
+		return false, nil
+	}
+
+	if old, ok := d.prefetched[url]; ok {
+		old.remove()
+	}
+	d.prefetched[url] = asset
+
+	return true, nil
+}
+
+// commit moves the assets downloaded for the snapshot into the cache, once the snapshot is loaded.
+func (d *downloader) commit() error {
+	d.mu.Lock()
+	downloaded := d.downloaded
+	d.downloaded = make(map[string]downloadedAsset)
+	d.mu.Unlock()
+
+	var errs []error
+	for url, asset := range downloaded {
+		if err := d.cache.store(url, asset.path, asset.v); err != nil {
+			asset.remove()
+			errs = append(errs, fmt.Errorf("%s: %w", url, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// discard removes the assets downloaded for the snapshot which fails to load, so the cache keeps the last good ones.
+func (d *downloader) discard() {
+	d.mu.Lock()
+	downloaded := d.downloaded
+	d.downloaded = make(map[string]downloadedAsset)
+	d.mu.Unlock()
+
+	for _, asset := range downloaded {
+		asset.remove()
+	}
+}
+
+// finish commits the downloaded assets if the snapshot is loaded, i.e. err is nil, or discards them otherwise.
+// The snapshot is still served if it fails to commit them, the cache just keeps the older assets.
+func (d *downloader) finish(err error) {
+	if err != nil {
+		d.discard()
+		return
+	}
+
+	if err := d.commit(); err != nil {
+		d.logger.Warn("Failed to cache downloaded assets", slog.String("error", err.Error()))
+	}
+}
+
+func (d *downloader) setValidators(url string, v validators) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.validators[url] = v
+}
+
+// replaceDownloaded must be called with d.mu held.
+func (d *downloader) replaceDownloaded(url string, asset downloadedAsset) {
+	if asset.path == "" {
+		return
+	}
+	if old, ok := d.downloaded[url]; ok {
+		old.remove()
+	}
+	d.downloaded[url] = asset
+}
+
+// spool copies the body into a file, in the cache directory if the cache is set, and hashes it.
+func (d *downloader) spool(body io.ReadCloser, v validators) (downloadedAsset, error) {
+	defer func() {
+		_ = body.Close()
+	}()
+
+	hash := sha256.New()
+	if d.cache == nil {
+		f, err := spool(io.TeeReader(body, hash))
+		if err != nil {
+			return downloadedAsset{}, err
+		}
+		v.sha256 = hex.EncodeToString(hash.Sum(nil))
+		return downloadedAsset{body: f, v: v}, nil
+	}
+
+	f, err := d.cache.create()
+	if err != nil {
+		return downloadedAsset{}, err
+	}
+	asset := downloadedAsset{body: f, path: f.Name()}
+
+	if _, err := io.Copy(f, io.TeeReader(body, hash)); err != nil {
+		asset.remove()
+		return downloadedAsset{}, fmt.Errorf("io.Copy: %w", err)
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		asset.remove()
+		return downloadedAsset{}, fmt.Errorf("seek: %w", err)
+	}
+
+	v.sha256 = hex.EncodeToString(hash.Sum(nil))
+	asset.v = v
+	return asset, nil
+}
+
+// remove closes the asset and removes its file.
+func (a downloadedAsset) remove() {
+	_ = a.body.Close()
+	if a.path != "" {
+		_ = os.Remove(a.path)
+	}
+}
+
+// get sends the request, and retries on the network errors and the server errors with exponential backoff and jitter.
+// The request is conditional if last has the validators, and fails with errNotModified if the asset hasn't changed.
+func (d *downloader) get(ctx context.Context, url string, last validators) (io.ReadCloser, validators, error) {
+	for attempt := 0; ; attempt++ {
+		body, v, err := d.attempt(ctx, url, last)
+		var retryable *retryableError
+		if !errors.As(err, &retryable) || attempt >= d.retries {
+			return body, v, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, validators{}, fmt.Errorf("%w (retry canceled: %w)", err, ctx.Err())
+		case <-time.After(d.backoffDelay(attempt)):
+		}
+	}
+}
+
+func (d *downloader) attempt(ctx context.Context, url string, last validators) (io.ReadCloser, validators, error) {
+	ctx, cancel := context.WithTimeout(ctx, d.timeout)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		cancel()
+		return nil, validators{}, fmt.Errorf("http.NewRequest: %w", err)
+	}
+	if last.etag != "" {
+		req.Header.Set("If-None-Match", last.etag)
+	}
+	if last.lastModified != "" {
+		req.Header.Set("If-Modified-Since", last.lastModified)
+	}
+
+	res, err := d.client.Do(req)
+	if err != nil {
+		cancel()
+		return nil, validators{}, &retryableError{fmt.Errorf("http do: %w", err)}
+	}
+
+	if err := checkResponse(res); err != nil {
+		_ = res.Body.Close()
+		cancel()
+		return nil, validators{}, err
+	}
+
+	v := validators{etag: res.Header.Get("ETag"), lastModified: res.Header.Get("Last-Modified")}
+	return &assetStream{Reader: res.Body, closers: []io.Closer{res.Body, closerFunc(cancel)}}, v, nil
+}
+
+// checkResponse checks that the response is the asset, e.g. not the HTML error page of the server.
+func checkResponse(res *http.Response) error {
+	switch code := res.StatusCode; {
+	case code == http.StatusNotModified:
+		return errNotModified
+	case code == http.StatusTooManyRequests, code == http.StatusRequestTimeout, code >= 500:
+		return &retryableError{fmt.Errorf("unexpected status code %d", code)}
+	case code != http.StatusOK:
+		return fmt.Errorf("unexpected status code %d", code)
+	}
+
+	if mediaType, _, _ := mime.ParseMediaType(res.Header.Get("Content-Type")); mediaType == "text/html" {
+		return fmt.Errorf("unexpected content type %q", mediaType)
+	}
+
+	return nil
+}
+
+// backoffDelay is the delay before the retry after the attempt, which doubles on each attempt up to maxBackoff.
+// The delay is randomized between its half and itself, so the instances don't retry at the same time.
+func (d *downloader) backoffDelay(attempt int) time.Duration {
+	delay := d.backoff
+	for range attempt {
+		if delay >= d.maxBackoff/2 {
+			delay = d.maxBackoff
+			break
+		}
+		delay *= 2
+	}
+	delay = min(delay, d.maxBackoff)
+
+	return delay/2 + rand.N(delay/2+1)
+}
+
+// hashingBody hashes the body as it is read, and calls done with the hash once the body is read to the end.
+type hashingBody struct {
+	io.ReadCloser
+	hash hash.Hash
+	done func(sum string)
+}
+
+func (b *hashingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.hash.Write(p[:n])
+	if err == io.EOF && b.done != nil {
+		b.done(hex.EncodeToString(b.hash.Sum(nil)))
+		b.done = nil
+	}
+	return n, err
+}
+
+type retryableError struct {
+	err error
+}
+
+func (e *retryableError) Error() string {
+	return e.err.Error()
+}
+
+func (e *retryableError) Unwrap() error {
+	return e.err
+}
@@ -0,0 +1,221 @@
+package dictionary_test
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/raf555/kbbi-api/internal/dictionary"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewReloader_Download(t *testing.T) {
+	key := bytes.Repeat([]byte{1}, 32)
+	asset := sealTestAsset(t, key, dictionary.AssetKindDictionary, `{"stats":{"edition":"VI"},"lemmas":[{"lemma":"apel"}]}`)
+	// the word of the day is read from the assets directory, so only the dictionary is downloaded.
+	assetsDir := t.TempDir()
+	wotd := sealTestAsset(t, key, dictionary.AssetKindWOTD, `[1]`)
+	require.NoError(t, os.WriteFile(filepath.Join(assetsDir, dictionary.AssetKindWOTD.Filename()), wotd, 0o600))
+
+	notFound := func(w http.ResponseWriter) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte("<html>not found</html>"))
+	}
+
+	tcs := []struct {
+		name    string
+		retries int
+		respond func(w http.ResponseWriter, attempt int)
+
+		expectedAttempts int
+		expectedErr      string
+	}{
+		{
+			name:    "retried server error",
+			retries: 2,
+			respond: func(w http.ResponseWriter, attempt int) {
+				if attempt < 3 {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				_, _ = w.Write(asset)
+			},
+			expectedAttempts: 3,
+		},
+		{
+			name:    "retries exhausted",
+			retries: 1,
+			respond: func(w http.ResponseWriter, _ int) {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			},
+			expectedAttempts: 2,
+			expectedErr:      "unexpected status code 503",
+		},
+		{
+			name:    "not found is not retried",
+			retries: 2,
+			respond: func(w http.ResponseWriter, _ int) {
+				notFound(w)
+			},
+			expectedAttempts: 1,
+			expectedErr:      "unexpected status code 404",
+		},
+		{
+			name:    "HTML page",
+			retries: 2,
+			respond: func(w http.ResponseWriter, _ int) {
+				w.Header().Set("Content-Type", "text/html")
+				_, _ = w.Write([]byte("<html>maintenance</html>"))
+			},
+			expectedAttempts: 1,
+			expectedErr:      `unexpected content type "text/html"`,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			var attempts atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				tc.respond(w, int(attempts.Add(1)))
+			}))
+			defer srv.Close()
+
+			cfg := dictionary.Configuration{
+				AssetsEncryptionKey: key,
+				AssetsDirectory:     assetsDir,
+				Dictionary:          dictionary.AssetConfig{DownloadURL: srv.URL + "/dict.db"},
+				DownloadRetries:     tc.retries,
+				DownloadBackoff:     time.Millisecond,
+			}
+			reloader, err := dictionary.NewReloader(t.Context(), cfg, slog.New(slog.DiscardHandler))
+			assert.Equal(t, tc.expectedAttempts, int(attempts.Load()))
+			if tc.expectedErr != "" {
+				assert.ErrorContains(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "VI", reloader.Current().Stats().Edition)
+		})
+	}
+}
+
+func TestReloader_Poll(t *testing.T) {
+	key := bytes.Repeat([]byte{1}, 32)
+	wotd := sealTestAsset(t, key, dictionary.AssetKindWOTD, `[1]`)
+	versions := []struct {
+		etag, lastModified string
+		asset              []byte
+	}{
+		{
+			etag:         `"v1"`,
+			lastModified: "Mon, 05 Oct 2026 00:00:00 GMT",
+			asset:        sealTestAsset(t, key, dictionary.AssetKindDictionary, `{"stats":{"edition":"VI"},"lemmas":[{"lemma":"apel"}]}`),
+		},
+		{
+			etag:         `"v2"`,
+			lastModified: "Mon, 12 Oct 2026 00:00:00 GMT",
+			asset:        sealTestAsset(t, key, dictionary.AssetKindDictionary, `{"stats":{"edition":"VII"},"lemmas":[{"lemma":"apel"}]}`),
+		},
+	}
+
+	tcs := []struct {
+		name      string
+		validator string
+		cached    bool
+		// unconditional is set if the server ignores the conditional request, and always responds with the asset.
+		unconditional bool
+	}{
+		{name: "ETag", validator: "ETag"},
+		{name: "Last-Modified", validator: "Last-Modified"},
+		{name: "cached", validator: "ETag", cached: true},
+		{name: "unconditional ETag", validator: "ETag", unconditional: true},
+		{name: "unconditional Last-Modified", validator: "Last-Modified", unconditional: true},
+		{name: "no validator", unconditional: true},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				mu        sync.Mutex
+				version   int
+				downloads int
+			)
+			mux := http.NewServeMux()
+			mux.HandleFunc("/wotd.db", func(w http.ResponseWriter, _ *http.Request) {
+				_, _ = w.Write(wotd)
+			})
+			mux.HandleFunc("/dict.db", func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				defer mu.Unlock()
+
+				current := versions[version]
+				switch tc.validator {
+				case "ETag":
+					w.Header().Set("ETag", current.etag)
+					if !tc.unconditional && r.Header.Get("If-None-Match") == current.etag {
+						w.WriteHeader(http.StatusNotModified)
+						return
+					}
+				case "Last-Modified":
+					w.Header().Set("Last-Modified", current.lastModified)
+					if !tc.unconditional && r.Header.Get("If-Modified-Since") == current.lastModified {
+						w.WriteHeader(http.StatusNotModified)
+						return
+					}
+				}
+
+				downloads++
+				_, _ = w.Write(current.asset)
+			})
+			srv := httptest.NewServer(mux)
+			defer srv.Close()
+
+			cfg := dictionary.Configuration{
+				AssetsEncryptionKey: key,
+				Dictionary:          dictionary.AssetConfig{DownloadURL: srv.URL + "/dict.db"},
+				WOTD:                dictionary.AssetConfig{DownloadURL: srv.URL + "/wotd.db"},
+				PollInterval:        5 * time.Millisecond,
+			}
+			if tc.cached {
+				cfg.CacheDirectory = t.TempDir()
+			}
+			reloader, err := dictionary.NewReloader(t.Context(), cfg, slog.New(slog.DiscardHandler))
+			require.NoError(t, err)
+			go reloader.Watch(t.Context())
+
+			// the unchanged asset is not downloaded again, or at least not reloaded if the server always sends it.
+			loaded := reloader.Current()
+			time.Sleep(50 * time.Millisecond)
+			assert.Same(t, loaded, reloader.Current())
+			mu.Lock()
+			if !tc.unconditional {
+				assert.Equal(t, 1, downloads)
+			}
+			version = 1
+			mu.Unlock()
+
+			assert.Eventually(t, func() bool {
+				return reloader.Current().Stats().Edition == "VII"
+			}, time.Second, 5*time.Millisecond)
+			if tc.cached {
+				assert.Equal(t, dictionary.AssetSourceNetwork, reloader.Current().Sources().Dictionary)
+			}
+
+			// the new version is only downloaded by the poller, the reload reads the same one.
+			if !tc.unconditional {
+				mu.Lock()
+				assert.Equal(t, 2, downloads)
+				mu.Unlock()
+			}
+		})
+	}
+}
@@ -0,0 +1,283 @@
+package dictionary
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// The envelope wraps the encrypted asset with its metadata:
+//
+//	magic (4 bytes) | version (1 byte) | header length (4 bytes, big-endian) | header (JSON) | ciphertext
+//
+// Everything before the ciphertext is authenticated as the additional data of AES-GCM,
+// so the header can't be modified without failing the decryption.
+// The ciphertext is split into chunks, see [chunkReader].
+// The asset without the magic is the legacy format, which is the ciphertext only, encrypted with a fixed IV.
+const (
+	envelopeMagic   = "KBBI"
+	envelopeVersion = 1
+
+	envelopePrefixSize = len(envelopeMagic) + 1 + 4
+	// maxEnvelopeHeaderSize guards against reading a corrupted header length.
+	maxEnvelopeHeaderSize = 64 << 10
+)
+
+// ContentEncodingGzip is the only supported content encoding of the asset.
+const ContentEncodingGzip = "gzip"
+
+var ErrUnsupportedEnvelope = errors.New("dictionary: unsupported asset envelope")
+
+// EnvelopeHeader is the metadata of the asset, see [WriteAsset].
+type EnvelopeHeader struct {
+	// Version is the format version of the envelope. It is set when the envelope is read.
+	Version int `json:"-"`
+	// KeyID identifies the key used to encrypt the asset. It is empty if the key has no ID.
+	KeyID string `json:"keyId,omitempty"`
+	// Nonce is the random prefix of the chunk nonces generated for each asset.
+	Nonce []byte `json:"nonce"`
+	// ChunkSize is the size of the plaintext of each chunk.
+	ChunkSize       int       `json:"chunkSize"`
+	ContentType     string    `json:"contentType"`
+	ContentEncoding string    `json:"contentEncoding"`
+	Edition         string    `json:"edition,omitempty"`
+	CreatedAt       time.Time `json:"createdAt"`
+}
+
+// ContentType is the content type of the asset written in the envelope header.
+func (k AssetKind) ContentType() string {
+	return "application/vnd.kbbi." + string(k) + "+json"
+}
+
+// newEnvelopeWriter writes the envelope with the header to w, and returns the writer which encrypts the content
+// into the chunks. The nonce of the header is always generated. The writer must be closed to write the last chunk.
+func newEnvelopeWriter(w io.Writer, header EnvelopeHeader, key []byte) (io.WriteCloser, error) {
+	aesGCM, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	header.Nonce = make([]byte, chunkNoncePrefixSize)
+	if _, err := rand.Read(header.Nonce); err != nil {
+		return nil, fmt.Errorf("rand.Read: %w", err)
+	}
+	header.ChunkSize = defaultChunkSize
+
+	headerJSON, err := json.Marshal(header)
+	if err != nil {
+		return nil, fmt.Errorf("json.Marshal: %w", err)
+	}
+
+	prefix := make([]byte, 0, envelopePrefixSize+len(headerJSON))
+	prefix = append(prefix, envelopeMagic...)
+	prefix = append(prefix, envelopeVersion)
+	prefix = binary.BigEndian.AppendUint32(prefix, uint32(len(headerJSON)))
+	prefix = append(prefix, headerJSON...)
+
+	if _, err := w.Write(prefix); err != nil {
+		return nil, fmt.Errorf("w.Write: %w", err)
+	}
+
+	return newChunkWriter(w, aesGCM, header.Nonce, prefix, header.ChunkSize), nil
+}
+
+// readEnvelopeHeader reads the envelope header, and returns it along with the additional data.
+// ok is false if the asset is in the legacy format, in which case nothing is read.
+func readEnvelopeHeader(r *bufio.Reader) (header EnvelopeHeader, aad []byte, ok bool, err error) {
+	prefix, err := r.Peek(envelopePrefixSize)
+	if err != nil || !bytes.HasPrefix(prefix, []byte(envelopeMagic)) {
+		return EnvelopeHeader{}, nil, false, nil
+	}
+
+	version := prefix[len(envelopeMagic)]
+	if version != envelopeVersion {
+		return EnvelopeHeader{}, nil, true, fmt.Errorf("%w: version %d", ErrUnsupportedEnvelope, version)
+	}
+
+	headerSize := binary.BigEndian.Uint32(prefix[len(envelopeMagic)+1:])
+	if headerSize > maxEnvelopeHeaderSize {
+		return EnvelopeHeader{}, nil, true, fmt.Errorf("%w: invalid header size %d", ErrUnsupportedEnvelope, headerSize)
+	}
+
+	aad = make([]byte, envelopePrefixSize+int(headerSize))
+	if _, err := io.ReadFull(r, aad); err != nil {
+		return EnvelopeHeader{}, nil, true, fmt.Errorf("%w: invalid header size %d: %w", ErrUnsupportedEnvelope, headerSize, err)
+	}
+
+	if err := json.Unmarshal(aad[envelopePrefixSize:], &header); err != nil {
+		return EnvelopeHeader{}, nil, true, fmt.Errorf("%w: header: %w", ErrUnsupportedEnvelope, err)
+	}
+	header.Version = int(version)
+
+	if header.ContentEncoding != ContentEncodingGzip {
+		return EnvelopeHeader{}, nil, true, fmt.Errorf("%w: content encoding %q", ErrUnsupportedEnvelope, header.ContentEncoding)
+	}
+
+	if len(header.Nonce) != chunkNoncePrefixSize {
+		return EnvelopeHeader{}, nil, true, fmt.Errorf("%w: invalid nonce size %d", ErrUnsupportedEnvelope, len(header.Nonce))
+	}
+	if header.ChunkSize <= 0 || header.ChunkSize > maxChunkSize {
+		return EnvelopeHeader{}, nil, true, fmt.Errorf("%w: invalid chunk size %d", ErrUnsupportedEnvelope, header.ChunkSize)
+	}
+
+	return header, aad, true, nil
+}
+
+// parseEnvelope reads the header of the asset without decrypting it. ok is false if the asset is in the legacy format.
+func parseEnvelope(data []byte) (header EnvelopeHeader, ok bool, err error) {
+	asset, _, err := SplitSignature(data)
+	if err != nil {
+		return EnvelopeHeader{}, false, err
+	}
+
+	header, _, ok, err = readEnvelopeHeader(bufio.NewReader(bytes.NewReader(asset)))
+	return header, ok, err
+}
+
+// openEnvelopeStream reads the envelope from r, and returns the stream of the compressed content along with the key
+// which decrypts it. The embedded signature, if any, is skipped since it is verified separately, see [VerifyAsset].
+// legacyNonce is only used for the legacy format. header is nil for the legacy format.
+//
+// The envelope is decrypted as it is read, while the legacy format is read fully since it is a single message.
+func openEnvelopeStream(r io.Reader, keys Keyring, legacyNonce []byte) (compressed io.Reader, header *EnvelopeHeader, key Key, err error) {
+	br := bufio.NewReader(r)
+	if _, _, err := readEmbeddedSignature(br); err != nil {
+		return nil, nil, Key{}, err
+	}
+
+	envelope, aad, ok, err := readEnvelopeHeader(br)
+	if err != nil {
+		return nil, nil, Key{}, err
+	}
+
+	if !ok {
+		if len(legacyNonce) == 0 {
+			return nil, nil, Key{}, fmt.Errorf("legacy asset without envelope requires the encryption IV")
+		}
+
+		ciphertext, err := io.ReadAll(br)
+		if err != nil {
+			return nil, nil, Key{}, fmt.Errorf("io.ReadAll: %w", err)
+		}
+
+		// the legacy asset has no key ID, so every key is tried.
+		plaintext, key, err := keys.open(legacyNonce, ciphertext, nil)
+		if err != nil {
+			return nil, nil, Key{}, err
+		}
+		return bytes.NewReader(plaintext), nil, key, nil
+	}
+
+	var cr *chunkReader
+	candidates, err := keys.candidates(envelope.KeyID)
+	if err == nil {
+		cr, err = newChunkReader(br, candidates, envelope.Nonce, aad, envelope.ChunkSize)
+	}
+	if err != nil {
+		if envelope.KeyID != "" {
+			return nil, nil, Key{}, fmt.Errorf("asset encrypted with key %q: %w", envelope.KeyID, err)
+		}
+		return nil, nil, Key{}, err
+	}
+
+	return cr, &envelope, cr.key, nil
+}
+
+// openEnvelope decrypts the asset into its compressed content, see [openEnvelopeStream].
+func openEnvelope(data []byte, keys Keyring, legacyNonce []byte) (compressed []byte, header *EnvelopeHeader, key Key, err error) {
+	r, header, key, err := openEnvelopeStream(bytes.NewReader(data), keys, legacyNonce)
+	if err != nil {
+		return nil, nil, Key{}, err
+	}
+
+	compressed, err = io.ReadAll(r)
+	if err != nil {
+		return nil, nil, Key{}, err
+	}
+
+	return compressed, header, key, nil
+}
+
+// RekeyAsset decrypts the asset with the keyring, and encrypts it again with the new key in a new envelope.
+// The content is kept as is, so its hash doesn't change. The header is kept except the key ID and the nonce,
+// or is made from the content for the legacy asset. It returns the new asset and the key which decrypts the old one.
+func RekeyAsset(asset []byte, keys Keyring, legacyNonce []byte, newKey Key) ([]byte, Key, error) {
+	compressed, envelope, oldKey, err := openEnvelope(asset, keys, legacyNonce)
+	if err != nil {
+		return nil, Key{}, fmt.Errorf("open: %w", err)
+	}
+
+	var header EnvelopeHeader
+	if envelope != nil {
+		header = *envelope
+	} else {
+		header, err = legacyHeader(compressed)
+		if err != nil {
+			return nil, Key{}, err
+		}
+	}
+	header.KeyID = newKey.ID
+
+	var buf bytes.Buffer
+	ew, err := newEnvelopeWriter(&buf, header, newKey.Key)
+	if err != nil {
+		return nil, Key{}, fmt.Errorf("newEnvelopeWriter: %w", err)
+	}
+	if _, err := ew.Write(compressed); err != nil {
+		return nil, Key{}, fmt.Errorf("ew.Write: %w", err)
+	}
+	if err := ew.Close(); err != nil {
+		return nil, Key{}, fmt.Errorf("ew.Close: %w", err)
+	}
+
+	return buf.Bytes(), oldKey, nil
+}
+
+// legacyHeader makes the envelope header of the legacy asset from its compressed content.
+func legacyHeader(compressed []byte) (EnvelopeHeader, error) {
+	content, err := gunzip(compressed)
+	if err != nil {
+		return EnvelopeHeader{}, err
+	}
+
+	kind := assetKindOf(content)
+	header := EnvelopeHeader{
+		ContentType:     kind.ContentType(),
+		ContentEncoding: ContentEncodingGzip,
+		CreatedAt:       time.Now().UTC().Truncate(time.Second),
+	}
+
+	if kind == AssetKindDictionary {
+		var data struct {
+			Stats Stats `json:"stats"`
+		}
+		if err := json.Unmarshal(content, &data); err != nil {
+			return EnvelopeHeader{}, fmt.Errorf("json.Unmarshal: %w", err)
+		}
+		header.Edition = data.Stats.Edition
+	}
+
+	return header, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("aes.NewCipher: %w", err)
+	}
+
+	aesGCM, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("cipher.NewGCM: %w", err)
+	}
+
+	return aesGCM, nil
+}
@@ -0,0 +1,225 @@
+package dictionary_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/raf555/kbbi-api/internal/dictionary"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadAsset_Envelope(t *testing.T) {
+	key, legacyNonce := bytes.Repeat([]byte{1}, 32), bytes.Repeat([]byte{2}, 12)
+	createdAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	header := dictionary.EnvelopeHeader{
+		KeyID:       "2026-01",
+		ContentType: dictionary.AssetKindWOTD.ContentType(),
+		Edition:     "test",
+		CreatedAt:   createdAt,
+	}
+	seal := func(t *testing.T) []byte {
+		var buf bytes.Buffer
+		_, err := dictionary.WriteAsset(&buf, []int{1, 2, 3}, key, header)
+		require.NoError(t, err)
+		return buf.Bytes()
+	}
+
+	asset := seal(t)
+
+	tamperedHeader := bytes.Clone(asset)
+	i := bytes.Index(tamperedHeader, []byte(`"edition":"test"`))
+	require.NotEqual(t, -1, i)
+	copy(tamperedHeader[i:], `"edition":"tost"`)
+
+	tcs := []struct {
+		name  string
+		asset []byte
+		nonce []byte
+		kind  dictionary.AssetKind
+
+		expectedVersion int
+		expectedErr     string
+	}{
+		{
+			name:            "envelope",
+			asset:           asset,
+			kind:            dictionary.AssetKindWOTD,
+			expectedVersion: 1,
+		},
+		{
+			name:        "unexpected content type",
+			asset:       asset,
+			kind:        dictionary.AssetKindDictionary,
+			expectedErr: `content type "application/vnd.kbbi.wotd+json", expected "application/vnd.kbbi.dict+json"`,
+		},
+		{
+			name:        "tampered header",
+			asset:       tamperedHeader,
+			expectedErr: "message authentication failed",
+		},
+		{
+			name:        "unsupported version",
+			asset:       append([]byte("KBBI\x09"), asset[5:]...),
+			expectedErr: "unsupported asset envelope: version 9",
+		},
+		{
+			name:  "legacy",
+			asset: sealLegacy(t, key, legacyNonce, "[1,2,3]"),
+			nonce: legacyNonce,
+		},
+		{
+			name:        "legacy without IV",
+			asset:       sealLegacy(t, key, legacyNonce, "[1,2,3]"),
+			expectedErr: "requires the encryption IV",
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "wotd.db"), tc.asset, 0o600))
+
+			reader := dictionary.ReadAsset("wotd.db", dir, dictionary.Keyring{{Key: key}}, tc.nonce).Expect(tc.kind)
+
+			var indexes []int
+			err := reader.To(&indexes)
+			if tc.expectedErr != "" {
+				assert.ErrorContains(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, []int{1, 2, 3}, indexes)
+
+			header, ok := reader.Envelope()
+			assert.Equal(t, tc.expectedVersion != 0, ok)
+			if ok {
+				assert.Equal(t, tc.expectedVersion, header.Version)
+				assert.Equal(t, "2026-01", header.KeyID)
+				assert.Equal(t, dictionary.AssetKindWOTD.ContentType(), header.ContentType)
+				assert.Equal(t, "test", header.Edition)
+				assert.Equal(t, createdAt, header.CreatedAt)
+				assert.Len(t, header.Nonce, 7)
+			}
+		})
+	}
+
+	t.Run("nonce is random per asset", func(t *testing.T) {
+		other := seal(t)
+
+		first := dictionary.InspectAsset(asset, dictionary.Keyring{{Key: key}}, nil)
+		second := dictionary.InspectAsset(other, dictionary.Keyring{{Key: key}}, nil)
+
+		assert.NotEqual(t, first.Envelope.Nonce, second.Envelope.Nonce)
+		assert.Equal(t, first.ContentSHA256, second.ContentSHA256)
+	})
+}
+
+func TestReadAsset_Chunks(t *testing.T) {
+	key := bytes.Repeat([]byte{1}, 32)
+
+	// random indexes don't compress well, so the asset spans several chunks.
+	rng := rand.New(rand.NewPCG(1, 2))
+	indexes := make([]int, 200_000)
+	for i := range indexes {
+		indexes[i] = rng.Int()
+	}
+
+	var buf bytes.Buffer
+	_, err := dictionary.WriteAsset(&buf, indexes, key, dictionary.EnvelopeHeader{ContentType: dictionary.AssetKindWOTD.ContentType()})
+	require.NoError(t, err)
+	asset := buf.Bytes()
+
+	res := dictionary.InspectAsset(asset, dictionary.Keyring{{Key: key}}, nil)
+	require.True(t, res.OK(), res.Error)
+
+	// the chunks start after the header, and each of them is the chunk size plus the AES-GCM tag.
+	start := 9 + int(binary.BigEndian.Uint32(asset[5:9]))
+	sealedSize := res.Envelope.ChunkSize + 16
+	require.Greater(t, len(asset)-start, 2*sealedSize)
+
+	chunk := func(i int) []byte {
+		return asset[start+i*sealedSize : start+(i+1)*sealedSize]
+	}
+
+	tcs := []struct {
+		name  string
+		asset []byte
+
+		expectedErr string
+	}{
+		{
+			name:  "chunks",
+			asset: asset,
+		},
+		{
+			name:        "truncated at chunk boundary",
+			asset:       asset[:start+2*sealedSize],
+			expectedErr: "chunk 1: aesGCM.Open",
+		},
+		{
+			name:        "truncated",
+			asset:       asset[:len(asset)-1],
+			expectedErr: "message authentication failed",
+		},
+		{
+			name:        "reordered",
+			asset:       slices.Concat(asset[:start], chunk(1), chunk(0), asset[start+2*sealedSize:]),
+			expectedErr: "chunk 0",
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "wotd.db"), tc.asset, 0o600))
+
+			var got []int
+			err := dictionary.ReadAsset("wotd.db", dir, dictionary.Keyring{{Key: key}}, nil).To(&got)
+			if tc.expectedErr != "" {
+				assert.ErrorContains(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, indexes, got)
+		})
+	}
+}
+
+// sealLegacy encrypts the content in the legacy format, which has no envelope and uses a fixed IV.
+func sealLegacy(t *testing.T, key, nonce []byte, content string) []byte {
+	t.Helper()
+
+	return newTestGCM(t, key).Seal(nil, nonce, gzipContent(t, content), nil)
+}
+
+func gzipContent(t *testing.T, content string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	_, err := gz.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+	return buf.Bytes()
+}
+
+func newTestGCM(t *testing.T, key []byte) cipher.AEAD {
+	t.Helper()
+
+	block, err := aes.NewCipher(key)
+	require.NoError(t, err)
+	aesGCM, err := cipher.NewGCM(block)
+	require.NoError(t, err)
+	return aesGCM
+}
@@ -1,12 +1,31 @@
 package dictionary
 
-import "errors"
+import "github.com/raf555/kbbi-api/pkg/kbbi"
 
 var (
-	ErrLemmaNotFound         = errors.New("dictionary: lemma not found")
-	ErrLemmaTooLong          = errors.New("dictionary: lemma length too long")
-	ErrEntryNotFound         = errors.New("dictionary: entry not found")
-	ErrUnexpectedEmptyLemma  = errors.New("dictionary: unexpected empty lemma")
-	ErrUnexpectedEntryNumber = errors.New("dictionary: unexpected entry number")
-	ErrUnexpectedWotdIndex   = errors.New("dictionary: unexpected wotd lemma index")
+	ErrLemmaNotFound         = newError("lemma not found", kbbi.ErrorCodeLemmaNotFound)
+	ErrLemmaTooLong          = newError("lemma length too long", kbbi.ErrorCodeLemmaTooLong)
+	ErrEntryNotFound         = newError("entry not found", kbbi.ErrorCodeEntryNotFound)
+	ErrUnexpectedEmptyLemma  = newError("unexpected empty lemma", kbbi.ErrorCodeEmptyLemma)
+	ErrUnexpectedEntryNumber = newError("unexpected entry number", kbbi.ErrorCodeInvalidEntryNumber)
+	ErrUnexpectedWotdIndex   = newError("unexpected wotd lemma index", kbbi.ErrorCodeInternalServerError)
+	ErrReloadInProgress      = newError("reload is already in progress", kbbi.ErrorCodeReloadInProgress)
 )
+
+// dictionaryError is an error which carries a machine-readable code to be shown in the API response.
+type dictionaryError struct {
+	msg  string
+	code kbbi.ErrorCode
+}
+
+func newError(msg string, code kbbi.ErrorCode) error {
+	return &dictionaryError{msg, code}
+}
+
+func (e *dictionaryError) Error() string {
+	return "dictionary: " + e.msg
+}
+
+func (e *dictionaryError) ErrorCode() string {
+	return string(e.code)
+}
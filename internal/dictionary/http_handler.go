@@ -17,18 +17,44 @@ import (
 	"github.com/samber/lo"
 )
 
+// maxSuggestions is the maximum number of suggested lemmas shown when a lemma is not found.
+const maxSuggestions = 5
+
+var (
+	msgEmptyLemma = httphandler.Messages{
+		httphandler.LocaleEnglish:    "empty lemma",
+		httphandler.LocaleIndonesian: "lema kosong",
+	}
+	msgInvalidEntryNumber = httphandler.Messages{
+		httphandler.LocaleEnglish:    "invalid entry number: %d",
+		httphandler.LocaleIndonesian: "nomor entri tidak valid: %d",
+	}
+	msgLemmaNotFound = httphandler.Messages{
+		httphandler.LocaleEnglish:    "lemma not found",
+		httphandler.LocaleIndonesian: "lema tidak ditemukan",
+	}
+	msgEntryNotFound = httphandler.Messages{
+		httphandler.LocaleEnglish:    "lemma's entry not found",
+		httphandler.LocaleIndonesian: "entri lema tidak ditemukan",
+	}
+	msgLemmaTooLong = httphandler.Messages{
+		httphandler.LocaleEnglish:    "lemma is too long",
+		httphandler.LocaleIndonesian: "lema terlalu panjang",
+	}
+)
+
 type HTTPHandler struct {
-	dict DictionaryRepo
+	snapshots SnapshotRepo
 }
 
-func NewHTTPHandler(dict DictionaryRepo) *HTTPHandler {
+func NewHTTPHandler(snapshots SnapshotRepo) *HTTPHandler {
 	return &HTTPHandler{
-		dict: dict,
+		snapshots: snapshots,
 	}
 }
 
 func (h *HTTPHandler) MustRegisterRoutes(g *gin.Engine) {
-	entryGroupV1 := g.Group("/api/v1/entry")
+	entryGroupV1 := g.Group("/api/v1/entry", h.pinSnapshot)
 
 	entryGroupV1.GET("/_random",
 		httphandler.MakeSimpleRedirectHandler(
@@ -52,6 +78,7 @@ func (h *HTTPHandler) MustRegisterRoutes(g *gin.Engine) {
 
 	entryGroupV1.GET("/:entry",
 		h.redirectToLowercase,
+		h.redirectToStandardForm,
 		httphandler.MakeHandler(
 			h.Entry,
 			httphandler.DefaultRequestBinder,
@@ -60,71 +87,139 @@ func (h *HTTPHandler) MustRegisterRoutes(g *gin.Engine) {
 	)
 }
 
+type snapshotCtxKey struct{}
+
+// pinSnapshot pins the current snapshot to the request, so the middlewares and the handler of the request
+// are all served by the same snapshot even if the dictionary is reloaded in the middle of it.
+func (h *HTTPHandler) pinSnapshot(ctx *gin.Context) {
+	ctx.Request = ctx.Request.WithContext(context.WithValue(ctx.Request.Context(), snapshotCtxKey{}, h.snapshots.Current()))
+}
+
+// dict returns the snapshot pinned to the request by pinSnapshot.
+func (h *HTTPHandler) dict(ctx context.Context) DictionaryRepo {
+	if dict, ok := ctx.Value(snapshotCtxKey{}).(DictionaryRepo); ok {
+		return dict
+	}
+	return h.snapshots.Current()
+}
+
 func (*HTTPHandler) redirectToLowercase(ctx *gin.Context) {
 	param := ctx.Param("entry")
 
 	if lowered := strings.ToLower(param); lowered != param {
-		// TODO: fix this hack maybe?
-		//
-		// for some reason, the ctx.Request.URL.Path is having the unescaped path,
-		// which caused the underlying http.Redirect to not form the new path properly if the entry has a slash.
-		// 	e.g. /api/v1/entry/termometer%20maks%2fmin%20Fahrenheit -> /api/v1/entry/termometer maks/min Fahrenheit
-		// the underlying http.Redirect will wrongly redirect the path which will become like this.
-		//	e.g. new path (lowered): termometer maks/min fahrenheit
-		//	i.e. /api/v1/entry/termometer maks/min Fahrenheit -> /api/v1/entry/termometer maks/termometer maks/min fahrenheit
-		//
-		// this is a hack to make sure it properly redirects to the correct lemma path.
-		if ctx.Request.URL.RawPath != "" {
-			ctx.Request.URL.Path = ctx.Request.URL.RawPath
-		}
+		redirectToLemma(ctx, http.StatusMovedPermanently, lowered)
+	}
+}
 
-		path := url.PathEscape(lowered)
-		if query := ctx.Request.URL.RawQuery; query != "" {
-			path += fmt.Sprintf("?%s", query)
-		}
+// redirectToStandardForm redirects to the standard form of the lemma if requested with [NonStandardRedirect].
+// The lemma which exists in the dictionary is never redirected, even if it is also listed as a non-standard form,
+// otherwise the standard form which only differs in its diacritics (e.g. apèl of apel) redirects to itself.
+func (h *HTTPHandler) redirectToStandardForm(ctx *gin.Context) {
+	if NonStandardBehavior(ctx.Query("onNonStandard")) != NonStandardRedirect {
+		return
+	}
+
+	lemma := ctx.Param("entry")
+	if newLemma, _, ok := FindEntryNoFromLemma(lemma); ok {
+		lemma = newLemma
+	}
+
+	dict := h.dict(ctx.Request.Context())
+	if _, err := dict.Lemma(lemma, 0); !errors.Is(err, ErrLemmaNotFound) && !errors.Is(err, ErrLemmaTooLong) {
+		return
+	}
 
-		ctx.Redirect(http.StatusMovedPermanently, path)
-		ctx.Abort()
+	if standardForm, ok := dict.StandardForm(lemma); ok && standardForm != lemma {
+		// not permanent since the dictionary may change in the future.
+		redirectToLemma(ctx, http.StatusFound, standardForm)
 	}
 }
 
+func redirectToLemma(ctx *gin.Context, code int, lemma string) {
+	// TODO: fix this hack maybe?
+	//
+	// for some reason, the ctx.Request.URL.Path is having the unescaped path,
+	// which caused the underlying http.Redirect to not form the new path properly if the entry has a slash.
+	// 	e.g. /api/v1/entry/termometer%20maks%2fmin%20Fahrenheit -> /api/v1/entry/termometer maks/min Fahrenheit
+	// the underlying http.Redirect will wrongly redirect the path which will become like this.
+	//	e.g. new path (lowered): termometer maks/min fahrenheit
+	//	i.e. /api/v1/entry/termometer maks/min Fahrenheit -> /api/v1/entry/termometer maks/termometer maks/min fahrenheit
+	//
+	// this is a hack to make sure it properly redirects to the correct lemma path.
+	if ctx.Request.URL.RawPath != "" {
+		ctx.Request.URL.Path = ctx.Request.URL.RawPath
+	}
+
+	path := url.PathEscape(lemma)
+	if query := ctx.Request.URL.RawQuery; query != "" {
+		path += fmt.Sprintf("?%s", query)
+	}
+
+	ctx.Redirect(code, path)
+	ctx.Abort()
+}
+
 // Entry godoc
 // @Summary      Show Lemma Information
-// @Description  Show the information of provided lemma
+// @Description  Show the information of provided lemma.
+// @Description  If the lemma is a variant of an entry (e.g. terselip ke luar), only the entry which owns the variant is shown along with the matched variant.
+// @Description  If the lemma is a non-standard form of another lemma (e.g. apotik), the standard form is shown in the standardForm field.
+// @Description  If the lemma is a reduplicated form (e.g. anak-anak, berlari-lari) which is not in the dictionary, the base lemma is shown along with the reduplication information.
+// @Description  If the lemma is not found, the error response contains a list of similar lemmas as suggestions.
 // @Tags         entry
 // @Accept       json
-// @Produce      json
+// @Produce      json,xml,application/yaml,application/msgpack,application/cbor,plain
 // @Param        entry    path      string  true  "Lemma. E.g. apel, aku (2), etc."
 // @Param        entryNo  query     int	  	false "Lemma's entry number (optional). Start from 1. Will be skipped if there's entry number in the lemma." minimum(1)
-// @Success      200   	  {object}  kbbi.Lemma
+// @Param        lang     query     string  false "Language of the error messages (optional). Overrides the Accept-Language header." Enums(en, id)
+// @Param        onNonStandard  query  string  false "Behavior if the lemma is a non-standard form (bentuk tidak baku) of another lemma. `annotate` resolves to the standard form if the lemma is not found, `redirect` redirects to the standard form if the lemma is not found, `strict` never resolves to the standard form. Defaults to `annotate`." Enums(annotate, redirect, strict)
+// @Param        format   query     string  false "Response format (optional). Overrides the Accept header. curl and wget are served with colored text by default." Enums(json, xml, yaml, msgpack, cbor, text)
+// @Param        width    query     int     false "Maximum line width of the text format (optional). Defaults to 80." minimum(20) maximum(500)
+// @Success      200   	  {object}  EntryResponse
+// @Success      302   	  {object}  EntryResponse
 // @Failure      400      {object}  httpres.Error
 // @Failure      404      {object}  httpres.Error
+// @Failure      406      {object}  httpres.Error
 // @Failure      414      {object}  httpres.Error
 // @Failure      500      {object}  httpres.Error
+// @Failure      default  {object}  httpres.Problem "Sent instead of httpres.Error if the client accepts application/problem+json."
 // @Router       /api/v1/entry/{entry} [get]
 func (h *HTTPHandler) Entry(ctx context.Context, req *EntryRequest) (*EntryResponse, error) {
 	req.transform()
+	dict := h.dict(ctx)
 
-	data, err := h.dict.Lemma(req.Lemma, req.EntryNo)
+	result, err := dict.Lookup(req.Lemma, req.EntryNo)
+	if err == nil && req.OnNonStandard == NonStandardStrict && result.Match == MatchNonStandard {
+		// the non-standard form itself is not in the dictionary, so it is treated as not found.
+		err = ErrLemmaNotFound
+	}
 	if err != nil {
-		wrappedErr := fmt.Errorf("h.dict.Lemma: %w", err)
+		wrappedErr := fmt.Errorf("dict.Lookup: %w", err)
 		switch {
 		case errors.Is(err, ErrUnexpectedEmptyLemma):
-			return nil, httperr.Wrap(wrappedErr, http.StatusBadRequest, "empty lemma")
+			return nil, httperr.Wrap(wrappedErr, http.StatusBadRequest, msgEmptyLemma.In(ctx))
 		case errors.Is(err, ErrUnexpectedEntryNumber):
-			return nil, httperr.Wrapf(wrappedErr, http.StatusBadRequest, "invalid entry number: %d", req.EntryNo)
+			return nil, httperr.Wrapf(wrappedErr, http.StatusBadRequest, msgInvalidEntryNumber.In(ctx), req.EntryNo)
 		case errors.Is(err, ErrLemmaNotFound):
-			return nil, httperr.Wrap(wrappedErr, http.StatusNotFound, "lemma not found")
+			return nil, httperr.WithSuggestions(
+				httperr.Wrap(wrappedErr, http.StatusNotFound, msgLemmaNotFound.In(ctx)),
+				dict.Suggest(req.Lemma, maxSuggestions),
+			)
 		case errors.Is(err, ErrEntryNotFound):
-			return nil, httperr.Wrap(wrappedErr, http.StatusNotFound, "lemma's entry not found")
+			return nil, httperr.Wrap(wrappedErr, http.StatusNotFound, msgEntryNotFound.In(ctx))
 		case errors.Is(err, ErrLemmaTooLong):
-			return nil, httperr.Wrap(wrappedErr, http.StatusRequestURITooLong, "lemma is too long")
+			return nil, httperr.Wrap(wrappedErr, http.StatusRequestURITooLong, msgLemmaTooLong.In(ctx))
 		default:
 			return nil, wrappedErr
 		}
 	}
 
-	return &EntryResponse{data}, nil
+	return &EntryResponse{
+		Lemma:         result.Lemma,
+		StandardForm:  result.StandardForm,
+		Variant:       result.Variant,
+		Reduplication: result.Reduplication,
+	}, nil
 }
 
 // Entry godoc
@@ -134,12 +229,16 @@ func (h *HTTPHandler) Entry(ctx context.Context, req *EntryRequest) (*EntryRespo
 // @Success      200      {object}  kbbi.Lemma
 // @Success      302      {object}  kbbi.Lemma
 // @Failure      500      {object}  httpres.Error
+// @Failure      default  {object}  httpres.Problem "Sent instead of httpres.Error if the client accepts application/problem+json."
 // @Router       /api/v1/entry/_random [get]
 func (h *HTTPHandler) Random(ctx context.Context) (httphandler.RedirectResult, error) {
 	ctx, span := trace.FromContext(ctx).Start(ctx, "dictionary.HTTPHandler/Random")
 	defer span.End()
 
-	lemma := h.dict.RandomLemma()
+	lemma, err := h.dict(ctx).RandomLemma()
+	if err != nil {
+		return httphandler.RedirectResult{}, fmt.Errorf("dict.RandomLemma: %w", err)
+	}
 
 	mt := metric.FromContext(ctx) // TODO: for metrics test, remove later
 	mt.Count(ctx, "kbbi_random", 1)
@@ -157,11 +256,12 @@ func (h *HTTPHandler) Random(ctx context.Context) (httphandler.RedirectResult, e
 // @Success      200      {object}  kbbi.Lemma
 // @Success      302      {object}  kbbi.Lemma
 // @Failure      500      {object}  httpres.Error
+// @Failure      default  {object}  httpres.Problem "Sent instead of httpres.Error if the client accepts application/problem+json."
 // @Router       /api/v1/entry/_wotd [get]
 func (h *HTTPHandler) WOTD(ctx context.Context) (httphandler.RedirectResult, error) {
-	wotd, err := h.dict.LemmaOfTheDay()
+	wotd, err := h.dict(ctx).LemmaOfTheDay()
 	if err != nil {
-		return httphandler.RedirectResult{}, fmt.Errorf("h.dict.LemmaOfTheDay: %w", err)
+		return httphandler.RedirectResult{}, fmt.Errorf("dict.LemmaOfTheDay: %w", err)
 	}
 
 	return httphandler.RedirectResult{
@@ -173,15 +273,22 @@ func (h *HTTPHandler) WOTD(ctx context.Context) (httphandler.RedirectResult, err
 // Entry godoc
 // @Summary      Search Lemmas
 // @Description  Suggest a list of lemmas based on keyword. Search is done similarly with the application.
+// @Description  Lemmas which have entry variants matching the keyword are also included after the matching lemmas.
 // @Tags         entry
-// @Produce      json
+// @Produce      json,xml,application/yaml,application/msgpack,application/cbor,plain
 // @Param        entry	  query     string	  	false 	"The query to be used for search."
 // @Param        limit	  query     uint	  	true	"Maximum number of lemmas to be returned." maximum(100)
+// @Param        format   query     string  false "Response format (optional). Overrides the Accept header. curl and wget are served with colored text by default." Enums(json, xml, yaml, msgpack, cbor, text)
 // @Success      200   	  {object}  SearchResponse
+// @Failure      406      {object}  httpres.Error
 // @Failure      500      {object}  httpres.Error
+// @Failure      default  {object}  httpres.Problem "Sent instead of httpres.Error if the client accepts application/problem+json."
 // @Router       /api/v1/entry/_search [get]
 func (h *HTTPHandler) Search(ctx context.Context, req *SearchRequest) (*SearchResponse, error) {
-	result := h.dict.Search(req.Lemma, req.Limit)
+	result, err := h.dict(ctx).Search(req.Lemma, req.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("dict.Search: %w", err)
+	}
 
 	return &SearchResponse{
 		Lemmas: lo.Map(result, func(lemma kbbi.Lemma, _ int) string { return lemma.Lemma }),
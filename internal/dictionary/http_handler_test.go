@@ -0,0 +1,118 @@
+package dictionary_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/raf555/kbbi-api/internal/dictionary"
+	"github.com/raf555/kbbi-api/pkg/kbbi"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPHandler_RedirectToStandardForm(t *testing.T) {
+	dict := newTestDictionary(
+		newTestLemma("apèl", kbbi.Entry{Entry: "apèl", NonStandardWords: []string{"apel"}}),
+		newTestLemma("apotek", kbbi.Entry{Entry: "apotek", NonStandardWords: []string{"apotik"}}),
+		newTestLemma("teoretis", kbbi.Entry{Entry: "teoretis", NonStandardWords: []string{"teoritis"}}),
+		newTestLemma("teoritis"),
+	)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	dictionary.NewHTTPHandler(dict).MustRegisterRoutes(router)
+
+	tcs := []struct {
+		name string
+		path string
+
+		expectedCode     int
+		expectedLocation string
+	}{
+		{
+			name:             "non-standard form",
+			path:             "/api/v1/entry/apotik?onNonStandard=redirect",
+			expectedCode:     http.StatusFound,
+			expectedLocation: "/api/v1/entry/apotek?onNonStandard=redirect",
+		},
+		{
+			name:         "standard form which only differs in diacritics",
+			path:         "/api/v1/entry/ap%C3%A8l?onNonStandard=redirect",
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "non-standard form which only differs in diacritics",
+			path:         "/api/v1/entry/apel?onNonStandard=redirect",
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "non-standard form which exists",
+			path:         "/api/v1/entry/teoritis?onNonStandard=redirect",
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "without redirect",
+			path:         "/api/v1/entry/apotik",
+			expectedCode: http.StatusOK,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tc.path, nil))
+
+			assert.Equal(t, tc.expectedCode, rec.Code)
+			assert.Equal(t, tc.expectedLocation, rec.Header().Get("Location"))
+		})
+	}
+}
+
+// countingSnapshots counts how many times the snapshot is taken.
+type countingSnapshots struct {
+	dict  *dictionary.Dictionary
+	calls atomic.Int32
+}
+
+func (s *countingSnapshots) Current() dictionary.DictionaryRepo {
+	s.calls.Add(1)
+	return s.dict
+}
+
+func TestHTTPHandler_PinSnapshot(t *testing.T) {
+	snapshots := &countingSnapshots{dict: newTestDictionary(
+		newTestLemma("apotek", kbbi.Entry{Entry: "apotek", NonStandardWords: []string{"apotik"}}),
+	)}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	// same as the server, so the handlers see the request context.
+	router.ContextWithFallback = true
+	dictionary.NewHTTPHandler(snapshots).MustRegisterRoutes(router)
+
+	tcs := []struct {
+		name string
+		path string
+
+		expectedCode int
+	}{
+		{name: "redirect", path: "/api/v1/entry/apotik?onNonStandard=redirect", expectedCode: http.StatusFound},
+		{name: "lookup after the redirect check", path: "/api/v1/entry/apotek?onNonStandard=redirect", expectedCode: http.StatusOK},
+		{name: "not found with suggestions", path: "/api/v1/entry/apotel", expectedCode: http.StatusNotFound},
+		{name: "search", path: "/api/v1/entry/_search?entry=apo&limit=5", expectedCode: http.StatusOK},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			snapshots.calls.Store(0)
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tc.path, nil))
+
+			assert.Equal(t, tc.expectedCode, rec.Code)
+			assert.Equal(t, int32(1), snapshots.calls.Load())
+		})
+	}
+}
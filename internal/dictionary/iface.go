@@ -5,11 +5,23 @@ import "github.com/raf555/kbbi-api/pkg/kbbi"
 type WOTDRepo interface {
 	RandomLemmaIndex() int
 	TodayLemmaIndex() int
+	LemmaIndexes() []int
+}
+
+// SnapshotRepo serves the current snapshot of the dictionary, see [Reloader].
+// A request takes the snapshot once and makes all of its calls on it, so it is never served by two snapshots.
+type SnapshotRepo interface {
+	Current() DictionaryRepo
 }
 
 type DictionaryRepo interface {
+	Stats() Stats
+	Sources() AssetSources
 	Lemma(lemma string, entryNo int) (kbbi.Lemma, error)
-	RandomLemma() kbbi.Lemma
+	Lookup(lemma string, entryNo int) (LookupResult, error)
+	StandardForm(lemma string) (string, bool)
+	RandomLemma() (kbbi.Lemma, error)
 	LemmaOfTheDay() (kbbi.Lemma, error)
-	Search(prefix string, limit uint) []kbbi.Lemma
+	Search(prefix string, limit uint) ([]kbbi.Lemma, error)
+	Suggest(lemma string, limit int) []string
 }
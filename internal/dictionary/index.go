@@ -0,0 +1,127 @@
+package dictionary
+
+import (
+	"github.com/raf555/kbbi-api/pkg/kbbi"
+)
+
+// dictionaryIndex stores the lemmas sorted by their normalized form, along with the indexes to look them up.
+// It is either built in memory from the dictionary asset, or mapped from the compiled dictionary, see [Dictionary.Compile].
+// The lemmas are referred to by their position i in the sorted lemmas.
+type dictionaryIndex interface {
+	lemmaCount() int
+	longestLemmaLength() int
+	// lemma is the lemma at i along with its entries. It only fails if the compiled dictionary is invalid,
+	// with [ErrInvalidCompiledDictionary].
+	lemma(i int) (kbbi.Lemma, error)
+	// lemmaName is the name of the lemma at i, which is cheaper than lemma if the entries are not needed.
+	// It is empty if the compiled dictionary is invalid.
+	lemmaName(i int) string
+	// normalizedForm is the form of the lemma at i which the lemmas are sorted by, see [Normalize].
+	normalizedForm(i int) string
+	// assetLemma is the position of the n-th lemma in the dictionary asset, counted from 0,
+	// which the word of the day refers to.
+	assetLemma(n int) int
+	// entryNoMap maps the entry number of the lemma at i, starting from 1, to the actual indexes in its entries.
+	entryNoMap(i int) map[int][]int
+
+	// exact looks up the lemma by its name as is.
+	exact(lemma string) (int, bool)
+	// normalized looks up the lemma by its name without diacritics, for the lemmas which have diacritics.
+	normalized(normalized string) (int, bool)
+	// variant looks up the variant by its [variantKey].
+	variant(key string) (variantIndex, bool)
+	// standardForm looks up the standard form by the [variantKey] of the non-standard form.
+	standardForm(key string) (standardFormIndex, bool)
+
+	variantCount() int
+	// variantNormalizedForm is the form of the variant at k which the variants are sorted by.
+	variantNormalizedForm(k int) string
+	// variantLemma is the position of the lemma which owns the variant at k.
+	variantLemma(k int) int
+}
+
+// memoryIndex is the dictionaryIndex built in memory by [dictionaryBuilder].
+type memoryIndex struct {
+	longest                int
+	inverseIndex           map[string]int
+	inverseNormalizedIndex map[string]int
+	variantIndex           map[string]*variantIndex
+	standardFormIndex      map[string]*standardFormIndex // key is the non-standard form.
+	lemmas                 []wrappedLemma
+	assetOrder             []int            // position in lemmas of each lemma in the order of the dictionary asset.
+	variants               []wrappedVariant // sorted by its normalized form, used for search.
+}
+
+type wrappedLemma struct {
+	kbbi.Lemma
+
+	NormalizedForm string
+	// entryNoMap's key is entry number, starts from 1. value is the actual index in the entries.
+	entryNoMap map[int][]int
+}
+
+func (m *memoryIndex) lemmaCount() int {
+	return len(m.lemmas)
+}
+
+func (m *memoryIndex) longestLemmaLength() int {
+	return m.longest
+}
+
+func (m *memoryIndex) lemma(i int) (kbbi.Lemma, error) {
+	return m.lemmas[i].Lemma, nil
+}
+
+func (m *memoryIndex) lemmaName(i int) string {
+	return m.lemmas[i].Lemma.Lemma
+}
+
+func (m *memoryIndex) normalizedForm(i int) string {
+	return m.lemmas[i].NormalizedForm
+}
+
+func (m *memoryIndex) assetLemma(n int) int {
+	return m.assetOrder[n]
+}
+
+func (m *memoryIndex) entryNoMap(i int) map[int][]int {
+	return m.lemmas[i].entryNoMap
+}
+
+func (m *memoryIndex) exact(lemma string) (int, bool) {
+	i, ok := m.inverseIndex[lemma]
+	return i, ok
+}
+
+func (m *memoryIndex) normalized(normalized string) (int, bool) {
+	i, ok := m.inverseNormalizedIndex[normalized]
+	return i, ok
+}
+
+func (m *memoryIndex) variant(key string) (variantIndex, bool) {
+	index, ok := m.variantIndex[key]
+	if !ok {
+		return variantIndex{}, false
+	}
+	return *index, true
+}
+
+func (m *memoryIndex) standardForm(key string) (standardFormIndex, bool) {
+	index, ok := m.standardFormIndex[key]
+	if !ok {
+		return standardFormIndex{}, false
+	}
+	return *index, true
+}
+
+func (m *memoryIndex) variantCount() int {
+	return len(m.variants)
+}
+
+func (m *memoryIndex) variantNormalizedForm(k int) string {
+	return m.variants[k].NormalizedForm
+}
+
+func (m *memoryIndex) variantLemma(k int) int {
+	return m.variants[k].idx
+}
@@ -0,0 +1,132 @@
+package dictionary
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// AssetInspection is the result of [InspectAsset]. The fields after the failed stage are empty.
+type AssetInspection struct {
+	Size   int    `json:"size"`
+	SHA256 string `json:"sha256"`
+	// KeyID and KeyFingerprint identify the key which decrypts the asset without revealing it.
+	KeyID          string `json:"keyId,omitempty"`
+	KeyFingerprint string `json:"keyFingerprint,omitempty"`
+	// Envelope is the header of the asset, nil for the legacy asset without envelope.
+	Envelope *EnvelopeHeader `json:"envelope,omitempty"`
+	// EnvelopeVersion is the format version of the envelope, 0 for the legacy asset.
+	EnvelopeVersion int `json:"envelopeVersion"`
+
+	Decrypted  bool `json:"decrypted"`
+	Gunzipped  bool `json:"gunzipped"`
+	JSONParsed bool `json:"jsonParsed"`
+	// Error is the error of the failed stage, if any.
+	Error string `json:"error,omitempty"`
+
+	Kind          AssetKind `json:"kind,omitempty"`
+	ContentSize   int       `json:"contentSize,omitempty"`
+	ContentSHA256 string    `json:"contentSha256,omitempty"`
+
+	// Stats is the stats block written in the dictionary asset, which may not match its actual counts.
+	Stats      *Stats `json:"stats,omitempty"`
+	LemmaCount int    `json:"lemmaCount,omitempty"`
+	EntryCount int    `json:"entryCount,omitempty"`
+	// Count is the number of the lemma indexes of the word of the day asset.
+	Count int `json:"count,omitempty"`
+}
+
+// OK reports whether the asset can be read by the server.
+func (i AssetInspection) OK() bool {
+	return i.Error == ""
+}
+
+// InspectAsset reads the encrypted asset stage by stage, and reports how far it goes.
+func InspectAsset(ciphertext []byte, keys Keyring, nonce []byte) AssetInspection {
+	res := AssetInspection{
+		Size:   len(ciphertext),
+		SHA256: sha256Hex(ciphertext),
+	}
+
+	// the header is readable without the key, so it is reported even if the decryption fails.
+	if _, _, err := SplitSignature(ciphertext); err != nil {
+		res.Error = fmt.Sprintf("signature: %s", err)
+		return res
+	}
+	if header, ok, err := parseEnvelope(ciphertext); ok {
+		if err != nil {
+			res.Error = fmt.Sprintf("envelope: %s", err)
+			return res
+		}
+		res.Envelope, res.EnvelopeVersion = &header, header.Version
+	}
+
+	compressed, _, key, err := openEnvelope(ciphertext, keys, nonce)
+	if err != nil {
+		res.Error = fmt.Sprintf("decrypt: %s", err)
+		return res
+	}
+	res.Decrypted, res.KeyID, res.KeyFingerprint = true, key.ID, KeyFingerprint(key.Key)
+
+	gz, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		res.Error = fmt.Sprintf("gunzip: %s", err)
+		return res
+	}
+
+	content, err := io.ReadAll(gz)
+	if err != nil {
+		res.Error = fmt.Sprintf("gunzip: %s", err)
+		return res
+	}
+	res.Gunzipped = true
+	res.ContentSize, res.ContentSHA256 = len(content), sha256Hex(content)
+
+	if assetKindOf(content) == AssetKindWOTD {
+		var indexes []int
+		if err := json.Unmarshal(content, &indexes); err != nil {
+			res.Error = fmt.Sprintf("json: %s", err)
+			return res
+		}
+		res.JSONParsed, res.Kind, res.Count = true, AssetKindWOTD, len(indexes)
+		return res
+	}
+
+	var data AssetData
+	if err := json.Unmarshal(content, &data); err != nil {
+		res.Error = fmt.Sprintf("json: %s", err)
+		return res
+	}
+	res.JSONParsed, res.Kind, res.Stats = true, AssetKindDictionary, &data.Stats
+
+	res.LemmaCount = len(data.Lemmas)
+	for _, lemma := range data.Lemmas {
+		res.EntryCount += len(lemma.Entries)
+	}
+
+	return res
+}
+
+// assetKindOf guesses the kind of the asset from its JSON content.
+func assetKindOf(content []byte) AssetKind {
+	// the word of the day asset is a list of the lemma indexes, while the dictionary is an object.
+	if trimmed := bytes.TrimSpace(content); len(trimmed) > 0 && trimmed[0] == '[' {
+		return AssetKindWOTD
+	}
+	return AssetKindDictionary
+}
+
+// KeyFingerprint is the first 8 bytes of the SHA-256 of the key, hex encoded.
+func KeyFingerprint(key []byte) string {
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:8])
+}
+
+func sha256Hex(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
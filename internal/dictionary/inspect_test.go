@@ -0,0 +1,82 @@
+package dictionary_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/raf555/kbbi-api/internal/dictionary"
+	"github.com/raf555/kbbi-api/pkg/kbbi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInspectAsset(t *testing.T) {
+	key, otherKey := bytes.Repeat([]byte{1}, 32), bytes.Repeat([]byte{2}, 32)
+
+	var dict bytes.Buffer
+	_, err := dictionary.WriteAsset(&dict, dictionary.AssetData{
+		Stats:  dictionary.Stats{Edition: "test", EntryCount: 5, LemmaCount: 1},
+		Lemmas: []kbbi.Lemma{newTestLemma("apel", kbbi.Entry{Entry: "apel (1)"}, kbbi.Entry{Entry: "apel (2)"})},
+	}, key, dictionary.EnvelopeHeader{KeyID: "k1", ContentType: dictionary.AssetKindDictionary.ContentType()})
+	require.NoError(t, err)
+
+	var wotd bytes.Buffer
+	_, err = dictionary.WriteAsset(&wotd, []int{1, 1}, key, dictionary.EnvelopeHeader{ContentType: dictionary.AssetKindWOTD.ContentType()})
+	require.NoError(t, err)
+
+	tcs := []struct {
+		name  string
+		asset []byte
+		key   []byte
+
+		expectedOK   bool
+		expectedKind dictionary.AssetKind
+		expectedErr  string
+	}{
+		{
+			name:         "dictionary",
+			asset:        dict.Bytes(),
+			key:          key,
+			expectedOK:   true,
+			expectedKind: dictionary.AssetKindDictionary,
+		},
+		{
+			name:         "wotd",
+			asset:        wotd.Bytes(),
+			key:          key,
+			expectedOK:   true,
+			expectedKind: dictionary.AssetKindWOTD,
+		},
+		{
+			name:        "wrong key",
+			asset:       dict.Bytes(),
+			key:         otherKey,
+			expectedErr: `asset encrypted with key "k1"`,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			res := dictionary.InspectAsset(tc.asset, dictionary.Keyring{{Key: tc.key}}, nil)
+
+			assert.Equal(t, len(tc.asset), res.Size)
+			assert.Equal(t, tc.expectedOK, res.OK())
+			assert.Equal(t, tc.expectedKind, res.Kind)
+			assert.Equal(t, 1, res.EnvelopeVersion)
+			if tc.expectedOK {
+				assert.Equal(t, dictionary.KeyFingerprint(tc.key), res.KeyFingerprint)
+			}
+			if tc.expectedErr != "" {
+				assert.False(t, res.Decrypted)
+				assert.Contains(t, res.Error, tc.expectedErr)
+			}
+		})
+	}
+
+	t.Run("stats mismatch is reported", func(t *testing.T) {
+		res := dictionary.InspectAsset(dict.Bytes(), dictionary.Keyring{{Key: key}}, nil)
+		assert.Equal(t, 5, res.Stats.EntryCount)
+		assert.Equal(t, 2, res.EntryCount)
+		assert.Equal(t, 1, res.LemmaCount)
+	})
+}
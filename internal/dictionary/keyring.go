@@ -0,0 +1,107 @@
+package dictionary
+
+import (
+	"encoding"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrKeyNotFound = errors.New("dictionary: key not found")
+
+// Key is an encryption key of the assets.
+type Key struct {
+	// ID is written in the envelope of the asset encrypted with the key. It may be empty.
+	ID  string
+	Key []byte
+}
+
+// String identifies the key in the logs and errors without revealing it.
+func (k Key) String() string {
+	if k.ID == "" {
+		return KeyFingerprint(k.Key)
+	}
+	return fmt.Sprintf("%s (%s)", k.ID, KeyFingerprint(k.Key))
+}
+
+// Keyring is the keys which can decrypt the assets, e.g. the current and the previous key during a rotation.
+// It is parsed from the comma separated `id:hex` pairs, e.g. `2026-01:ab12...,2025-07:cd34...`.
+type Keyring []Key
+
+var _ encoding.TextUnmarshaler = (*Keyring)(nil)
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (k *Keyring) UnmarshalText(text []byte) error {
+	var keys Keyring
+	seen := map[string]struct{}{}
+
+	for pair := range strings.SplitSeq(string(text), ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		id, hexKey, ok := strings.Cut(pair, ":")
+		if !ok || id == "" {
+			return fmt.Errorf("keyring: expected id:hex, got a key without ID")
+		}
+		if _, ok := seen[id]; ok {
+			return fmt.Errorf("keyring: duplicate key ID %q", id)
+		}
+		seen[id] = struct{}{}
+
+		key, err := hex.DecodeString(hexKey)
+		if err != nil {
+			return fmt.Errorf("keyring: key %q: hex.DecodeString: %w", id, err)
+		}
+
+		keys = append(keys, Key{ID: id, Key: key})
+	}
+
+	*k = keys
+	return nil
+}
+
+// candidates is the keys to try on the asset encrypted with the key ID, in order.
+// The asset without key ID can be encrypted with any key, while the asset with key ID is decrypted with the key of the ID.
+// The keys without ID are tried if there is none, e.g. if the current key is not given an ID yet.
+func (k Keyring) candidates(id string) (Keyring, error) {
+	if len(k) == 0 {
+		return nil, fmt.Errorf("%w: empty keyring", ErrKeyNotFound)
+	}
+
+	if id == "" {
+		return k, nil
+	}
+
+	var unnamed Keyring
+	for _, key := range k {
+		if key.ID == id {
+			return Keyring{key}, nil
+		}
+		if key.ID == "" {
+			unnamed = append(unnamed, key)
+		}
+	}
+
+	if len(unnamed) == 0 {
+		return nil, fmt.Errorf("%w: key %q is not in the keyring", ErrKeyNotFound, id)
+	}
+
+	return unnamed, nil
+}
+
+// open decrypts the ciphertext with the first key which can decrypt it.
+func (k Keyring) open(nonce, ciphertext, additionalData []byte) ([]byte, Key, error) {
+	var errs []string
+	for _, key := range k {
+		plaintext, err := decrypt(key.Key, nonce, ciphertext, additionalData)
+		if err == nil {
+			return plaintext, key, nil
+		}
+		errs = append(errs, fmt.Sprintf("key %s: %s", key, err))
+	}
+
+	return nil, Key{}, fmt.Errorf("none of the keys can decrypt the asset: %s", strings.Join(errs, "; "))
+}
@@ -0,0 +1,190 @@
+package dictionary_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/raf555/kbbi-api/internal/dictionary"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyring_UnmarshalText(t *testing.T) {
+	tcs := []struct {
+		name string
+		in   string
+
+		expected    dictionary.Keyring
+		expectedErr string
+	}{
+		{
+			name: "keys",
+			in:   "2026-01:0102, 2025-07:0304",
+			expected: dictionary.Keyring{
+				{ID: "2026-01", Key: []byte{1, 2}},
+				{ID: "2025-07", Key: []byte{3, 4}},
+			},
+		},
+		{
+			name: "empty",
+			in:   "",
+		},
+		{
+			name:        "without ID",
+			in:          "0102",
+			expectedErr: "expected id:hex",
+		},
+		{
+			name:        "duplicate ID",
+			in:          "a:0102,a:0304",
+			expectedErr: `duplicate key ID "a"`,
+		},
+		{
+			name:        "invalid hex",
+			in:          "a:xyz",
+			expectedErr: `key "a"`,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			var keyring dictionary.Keyring
+			err := keyring.UnmarshalText([]byte(tc.in))
+			if tc.expectedErr != "" {
+				assert.ErrorContains(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, keyring)
+		})
+	}
+}
+
+func TestOpenAsset_Keyring(t *testing.T) {
+	current := dictionary.Key{ID: "2026-10", Key: bytes.Repeat([]byte{1}, 32)}
+	previous := dictionary.Key{ID: "2026-01", Key: bytes.Repeat([]byte{2}, 32)}
+	unnamed := dictionary.Key{Key: bytes.Repeat([]byte{3}, 32)}
+	legacyNonce := bytes.Repeat([]byte{4}, 12)
+
+	seal := func(key dictionary.Key) []byte {
+		var buf bytes.Buffer
+		_, err := dictionary.WriteAsset(&buf, []int{1}, key.Key, dictionary.EnvelopeHeader{
+			KeyID:       key.ID,
+			ContentType: dictionary.AssetKindWOTD.ContentType(),
+		})
+		require.NoError(t, err)
+		return buf.Bytes()
+	}
+
+	tcs := []struct {
+		name  string
+		asset []byte
+		keys  dictionary.Keyring
+
+		expectedKey dictionary.Key
+		expectedErr string
+	}{
+		{
+			name:        "key ID",
+			asset:       seal(previous),
+			keys:        dictionary.Keyring{current, previous},
+			expectedKey: previous,
+		},
+		{
+			name:        "key ID not in keyring",
+			asset:       seal(previous),
+			keys:        dictionary.Keyring{current},
+			expectedErr: `key "2026-01" is not in the keyring`,
+		},
+		{
+			name:        "key ID of unnamed key",
+			asset:       seal(dictionary.Key{ID: "2026-01", Key: unnamed.Key}),
+			keys:        dictionary.Keyring{current, unnamed},
+			expectedKey: unnamed,
+		},
+		{
+			name:        "without key ID",
+			asset:       seal(unnamed),
+			keys:        dictionary.Keyring{current, previous, unnamed},
+			expectedKey: unnamed,
+		},
+		{
+			name:        "legacy",
+			asset:       sealLegacy(t, previous.Key, legacyNonce, "[1]"),
+			keys:        dictionary.Keyring{current, previous},
+			expectedKey: previous,
+		},
+		{
+			name:        "no key",
+			asset:       seal(unnamed),
+			keys:        dictionary.Keyring{current, previous},
+			expectedErr: "none of the keys can decrypt the asset",
+		},
+		{
+			name:        "empty keyring",
+			asset:       seal(current),
+			expectedErr: "empty keyring",
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			res := dictionary.InspectAsset(tc.asset, tc.keys, legacyNonce)
+			if tc.expectedErr != "" {
+				assert.Contains(t, res.Error, tc.expectedErr)
+				return
+			}
+			require.True(t, res.OK(), res.Error)
+			assert.Equal(t, tc.expectedKey.ID, res.KeyID)
+			assert.Equal(t, dictionary.KeyFingerprint(tc.expectedKey.Key), res.KeyFingerprint)
+		})
+	}
+}
+
+func TestRekeyAsset(t *testing.T) {
+	oldKey := dictionary.Key{ID: "2026-01", Key: bytes.Repeat([]byte{1}, 32)}
+	newKey := dictionary.Key{ID: "2026-10", Key: bytes.Repeat([]byte{2}, 32)}
+	legacyNonce := bytes.Repeat([]byte{3}, 12)
+
+	var asset bytes.Buffer
+	_, err := dictionary.WriteAsset(&asset, []int{1, 2}, oldKey.Key, dictionary.EnvelopeHeader{
+		KeyID:       oldKey.ID,
+		ContentType: dictionary.AssetKindWOTD.ContentType(),
+		Edition:     "test",
+	})
+	require.NoError(t, err)
+
+	tcs := []struct {
+		name  string
+		asset []byte
+
+		expectedEdition string
+	}{
+		{
+			name:            "envelope",
+			asset:           asset.Bytes(),
+			expectedEdition: "test",
+		},
+		{
+			name:  "legacy",
+			asset: sealLegacy(t, oldKey.Key, legacyNonce, "[1,2]"),
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			rekeyed, decryptedWith, err := dictionary.RekeyAsset(tc.asset, dictionary.Keyring{oldKey}, legacyNonce, newKey)
+			require.NoError(t, err)
+			assert.Equal(t, oldKey.ID, decryptedWith.ID)
+
+			assert.False(t, dictionary.InspectAsset(rekeyed, dictionary.Keyring{oldKey}, nil).OK())
+
+			res := dictionary.InspectAsset(rekeyed, dictionary.Keyring{newKey}, nil)
+			require.True(t, res.OK(), res.Error)
+			assert.Equal(t, newKey.ID, res.Envelope.KeyID)
+			assert.Equal(t, dictionary.AssetKindWOTD.ContentType(), res.Envelope.ContentType)
+			assert.Equal(t, tc.expectedEdition, res.Envelope.Edition)
+			assert.Equal(t, 2, res.Count)
+		})
+	}
+}
@@ -0,0 +1,223 @@
+package dictionary
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/samber/lo"
+)
+
+// Severity is the severity of a [LintIssue].
+type Severity string
+
+const (
+	// SeverityInfo is a known quirk of the data which doesn't need to be fixed.
+	SeverityInfo Severity = "info"
+	// SeverityWarning is a data problem which is visible to the user, but doesn't break the server.
+	SeverityWarning Severity = "warning"
+	// SeverityError is a data problem which breaks the server at runtime.
+	SeverityError Severity = "error"
+)
+
+var severityRanks = map[Severity]int{
+	SeverityInfo:    1,
+	SeverityWarning: 2,
+	SeverityError:   3,
+}
+
+// ParseSeverity parses the severity name, e.g. from a flag.
+func ParseSeverity(s string) (Severity, bool) {
+	_, ok := severityRanks[Severity(s)]
+	return Severity(s), ok
+}
+
+// AtLeast reports whether s is at least as severe as other.
+func (s Severity) AtLeast(other Severity) bool {
+	return severityRanks[s] >= severityRanks[other]
+}
+
+// LintCheck is the name of a check done by [Dictionary.Lint].
+type LintCheck string
+
+const (
+	LintDanglingReference   LintCheck = "dangling-reference"
+	LintDanglingBaseWord    LintCheck = "dangling-base-word"
+	LintDuplicateEntryNo    LintCheck = "duplicate-entry-number"
+	LintEmptyDefinitions    LintCheck = "empty-definitions"
+	LintStatsMismatch       LintCheck = "stats-mismatch"
+	LintWOTDIndexOutOfRange LintCheck = "wotd-index-out-of-range"
+	LintInvalidCompiled     LintCheck = "invalid-compiled-dictionary"
+)
+
+// lintSeverities is the severity of each check.
+var lintSeverities = map[LintCheck]Severity{
+	LintDanglingReference:   SeverityWarning,
+	LintDanglingBaseWord:    SeverityWarning,
+	LintDuplicateEntryNo:    SeverityInfo, // e.g. ketak (4), the website behaves the same.
+	LintEmptyDefinitions:    SeverityWarning,
+	LintStatsMismatch:       SeverityWarning,
+	LintWOTDIndexOutOfRange: SeverityError, // surfaces as ErrUnexpectedWotdIndex.
+	LintInvalidCompiled:     SeverityError, // surfaces as ErrInvalidCompiledDictionary.
+}
+
+// LintIssue is a data problem found by [Dictionary.Lint].
+type LintIssue struct {
+	Severity Severity  `json:"severity"`
+	Check    LintCheck `json:"check"`
+	// Lemma is the lemma which has the issue, if any.
+	Lemma   string `json:"lemma,omitempty"`
+	Message string `json:"message"`
+}
+
+// Lint checks the loaded dictionary and word of the day for the data problems.
+// The compiled dictionary is verified first, and the other checks are skipped if it is invalid.
+// The issues are sorted by severity, from the most severe one.
+func (d *Dictionary) Lint() []LintIssue {
+	var issues []LintIssue
+	report := func(check LintCheck, lemma, format string, args ...any) {
+		issues = append(issues, LintIssue{
+			Severity: lintSeverities[check],
+			Check:    check,
+			Lemma:    lemma,
+			Message:  fmt.Sprintf(format, args...),
+		})
+	}
+
+	// the other checks read the records, which can't be trusted if the compiled dictionary is corrupted.
+	if err := d.VerifyCompiled(); err != nil {
+		report(LintInvalidCompiled, "", "%s", err)
+		return issues
+	}
+
+	entryCount := 0
+	for i := range d.index.lemmaCount() {
+		lemma, err := d.index.lemma(i)
+		if err != nil {
+			// only if the file is modified in place after it is verified.
+			report(LintInvalidCompiled, "", "%s", err)
+			continue
+		}
+		entryCount += len(lemma.Entries)
+
+		for _, entry := range lemma.Entries {
+			if entry.BaseWord != "" && !d.resolves(entry.BaseWord) {
+				report(LintDanglingBaseWord, lemma.Lemma, "entry %q has base word %q which is not found", entry.Entry, entry.BaseWord)
+			}
+
+			if len(entry.Definitions) == 0 && entry.BaseWord == "" {
+				report(LintEmptyDefinitions, lemma.Lemma, "entry %q has no definition and no base word", entry.Entry)
+			}
+
+			for _, def := range entry.Definitions {
+				if def.ReferencedLemma != "" && !d.resolves(def.ReferencedLemma) {
+					report(LintDanglingReference, lemma.Lemma, "entry %q refers to %q which is not found", entry.Entry, def.ReferencedLemma)
+				}
+			}
+		}
+
+		entryNoMap := d.index.entryNoMap(i)
+		for _, entryNo := range slices.Sorted(maps.Keys(entryNoMap)) {
+			if n := len(entryNoMap[entryNo]); n > 1 {
+				report(LintDuplicateEntryNo, lemma.Lemma, "entry number %d is used by %d entries", entryNo, n)
+			}
+		}
+	}
+
+	if d.stats.LemmaCount != d.index.lemmaCount() {
+		report(LintStatsMismatch, "", "stats has %d lemmas, but the dictionary has %d", d.stats.LemmaCount, d.index.lemmaCount())
+	}
+	if d.stats.EntryCount != entryCount {
+		report(LintStatsMismatch, "", "stats has %d entries, but the dictionary has %d", d.stats.EntryCount, entryCount)
+	}
+
+	if d.wotd != nil {
+		for i, idx := range d.wotd.LemmaIndexes() {
+			if !wotdIndexInRange(idx, d.index.lemmaCount()) {
+				report(LintWOTDIndexOutOfRange, "", "index %d at position %d is out of the range [1, %d]", idx, i, d.index.lemmaCount())
+			}
+		}
+	}
+
+	slices.SortStableFunc(issues, func(a, b LintIssue) int {
+		return severityRanks[b.Severity] - severityRanks[a.Severity]
+	})
+
+	return issues
+}
+
+// resolves reports whether the reference, which may have an entry number such as `apel (1)`, is a lemma of the dictionary.
+// The other forms found by [Dictionary.Lookup], e.g. the reduplication of a lemma, are not accepted as the reference.
+func (d *Dictionary) resolves(ref string) bool {
+	lemma, entryNo := strings.TrimSpace(ref), 0
+	if newLemma, no, ok := FindEntryNoFromLemma(lemma); ok {
+		lemma, entryNo = newLemma, no
+	}
+
+	_, err := d.Lemma(lemma, entryNo)
+	return err == nil
+}
+
+// ValidationMode is the mode of the dictionary validation on startup.
+type ValidationMode string
+
+const (
+	ValidationOff ValidationMode = "off"
+	// ValidationWarn logs the issues found by [Dictionary.Lint].
+	ValidationWarn ValidationMode = "warn"
+	// ValidationStrict logs the issues, and fails the startup if there is any [SeverityError] issue.
+	ValidationStrict ValidationMode = "strict"
+)
+
+// maxLoggedExamples is the maximum number of the issues logged for each check on startup.
+const maxLoggedExamples = 3
+
+func (d *Dictionary) validate(ctx context.Context, mode ValidationMode, logger *slog.Logger) error {
+	if mode == "" || mode == ValidationOff {
+		return nil
+	}
+
+	start := time.Now()
+	issues := d.Lint()
+
+	// the issues are summarized per check, since there can be a lot of them in the full dictionary.
+	byCheck := lo.GroupBy(issues, func(issue LintIssue) LintCheck { return issue.Check })
+	errorCount := 0
+	for _, check := range slices.Sorted(maps.Keys(byCheck)) {
+		checkIssues := byCheck[check]
+		severity := lintSeverities[check]
+		if severity == SeverityError {
+			errorCount += len(checkIssues)
+		}
+
+		level := slog.LevelInfo
+		if severity.AtLeast(SeverityWarning) {
+			level = slog.LevelWarn
+		}
+
+		examples := lo.Map(checkIssues[:min(len(checkIssues), maxLoggedExamples)], func(issue LintIssue, _ int) string {
+			return issue.Message
+		})
+		logger.Log(ctx, level, "Found dictionary data issues",
+			slog.String("check", string(check)),
+			slog.String("severity", string(severity)),
+			slog.Int("count", len(checkIssues)),
+			slog.Any("examples", examples),
+		)
+	}
+
+	logger.Info("Finished validating dictionary",
+		slog.Int("issues", len(issues)),
+		slog.String("elapsed", time.Since(start).String()),
+	)
+
+	if mode == ValidationStrict && errorCount > 0 {
+		return fmt.Errorf("%w: %d issue(s) with %s severity", ErrInvalidAssetData, errorCount, SeverityError)
+	}
+
+	return nil
+}
@@ -0,0 +1,115 @@
+package dictionary_test
+
+import (
+	"testing"
+
+	"github.com/raf555/kbbi-api/internal/dictionary"
+	"github.com/raf555/kbbi-api/pkg/kbbi"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDictionary_Lint(t *testing.T) {
+	definition := func(ref string) []kbbi.EntryDefinition {
+		return []kbbi.EntryDefinition{{Definition: "lihat", ReferencedLemma: ref}}
+	}
+
+	tcs := []struct {
+		name   string
+		lemmas []kbbi.Lemma
+		stats  dictionary.Stats
+		wotd   todayWOTD
+
+		expected []dictionary.LintIssue
+	}{
+		{
+			name: "clean",
+			lemmas: []kbbi.Lemma{
+				newTestLemma("anak", kbbi.Entry{Entry: "anak", Definitions: definition("apel (2)")}),
+				newTestLemma("apel",
+					kbbi.Entry{Entry: "apel (1)", Definitions: definition("")},
+					kbbi.Entry{Entry: "apel (2)", BaseWord: "anak"},
+				),
+			},
+			stats: dictionary.Stats{LemmaCount: 2, EntryCount: 3},
+			wotd:  todayWOTD{1, 2},
+		},
+		{
+			name: "issues",
+			lemmas: []kbbi.Lemma{
+				newTestLemma("anak", kbbi.Entry{Entry: "anak", Definitions: definition("bapak")}),
+				newTestLemma("apel",
+					kbbi.Entry{Entry: "apel (1)"},
+					kbbi.Entry{Entry: "apel (1)", BaseWord: "pel", Definitions: definition("")},
+				),
+			},
+			stats: dictionary.Stats{LemmaCount: 2, EntryCount: 2},
+			wotd:  todayWOTD{1, 3},
+			expected: []dictionary.LintIssue{
+				{
+					Severity: dictionary.SeverityError,
+					Check:    dictionary.LintWOTDIndexOutOfRange,
+					Message:  "index 3 at position 1 is out of the range [1, 2]",
+				},
+				{
+					Severity: dictionary.SeverityWarning,
+					Check:    dictionary.LintDanglingReference,
+					Lemma:    "anak",
+					Message:  `entry "anak" refers to "bapak" which is not found`,
+				},
+				{
+					Severity: dictionary.SeverityWarning,
+					Check:    dictionary.LintEmptyDefinitions,
+					Lemma:    "apel",
+					Message:  `entry "apel (1)" has no definition and no base word`,
+				},
+				{
+					Severity: dictionary.SeverityWarning,
+					Check:    dictionary.LintDanglingBaseWord,
+					Lemma:    "apel",
+					Message:  `entry "apel (1)" has base word "pel" which is not found`,
+				},
+				{
+					Severity: dictionary.SeverityWarning,
+					Check:    dictionary.LintStatsMismatch,
+					Message:  "stats has 2 entries, but the dictionary has 3",
+				},
+				{
+					Severity: dictionary.SeverityInfo,
+					Check:    dictionary.LintDuplicateEntryNo,
+					Lemma:    "apel",
+					Message:  "entry number 1 is used by 2 entries",
+				},
+			},
+		},
+		{
+			name: "reduplication is not a lemma",
+			lemmas: []kbbi.Lemma{
+				newTestLemma("kemerahan", kbbi.Entry{Entry: "kemerahan", BaseWord: "merah-merah", Definitions: definition("kemerah-merahan")}),
+				newTestLemma("merah", kbbi.Entry{Entry: "merah", Definitions: definition("")}),
+			},
+			stats: dictionary.Stats{LemmaCount: 2, EntryCount: 2},
+			wotd:  todayWOTD{1},
+			expected: []dictionary.LintIssue{
+				{
+					Severity: dictionary.SeverityWarning,
+					Check:    dictionary.LintDanglingBaseWord,
+					Lemma:    "kemerahan",
+					Message:  `entry "kemerahan" has base word "merah-merah" which is not found`,
+				},
+				{
+					Severity: dictionary.SeverityWarning,
+					Check:    dictionary.LintDanglingReference,
+					Lemma:    "kemerahan",
+					Message:  `entry "kemerahan" refers to "kemerah-merahan" which is not found`,
+				},
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			dict := dictionary.NewDictionaryFromAssetData(dictionary.AssetData{Stats: tc.stats, Lemmas: tc.lemmas}, tc.wotd)
+			assert.Equal(t, tc.expected, dict.Lint())
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package dictionary
+
+import (
+	"errors"
+
+	"github.com/raf555/kbbi-api/pkg/kbbi"
+)
+
+type MatchKind string
+
+const (
+	// MatchExact means the lemma is found as is.
+	MatchExact MatchKind = "exact"
+	// MatchNormalized means the lemma is found after its diacritics are removed.
+	MatchNormalized MatchKind = "normalized"
+	// MatchVariant means the lemma is resolved from one of its entry variants.
+	MatchVariant MatchKind = "variant"
+	// MatchNonStandard means the lemma is resolved from its non-standard form.
+	MatchNonStandard MatchKind = "nonStandard"
+	// MatchReduplication means the lemma is resolved from its reduplicated form.
+	MatchReduplication MatchKind = "reduplication"
+)
+
+// LookupResult is the result of [Dictionary.Lookup].
+type LookupResult struct {
+	Lemma kbbi.Lemma
+
+	// Match is how the requested lemma is matched to Lemma.
+	Match MatchKind
+
+	// StandardForm is the standard form (bentuk baku) of the requested lemma,
+	// if the requested lemma is a non-standard form of another lemma. E.g. `apotek` of `apotik`.
+	//
+	// It is also present if the non-standard form itself exists in the dictionary.
+	StandardForm string
+
+	// Variant describes the variant of the entry which the requested lemma is matched to,
+	// if the lemma is resolved from one of its entry variants.
+	Variant *VariantMatch
+
+	// Reduplication describes the reduplicated form of the requested lemma,
+	// if the lemma is resolved from its base word.
+	Reduplication *Reduplication
+}
+
+type standardFormIndex struct {
+	idx      int // index in lemmas of the standard form.
+	entryIdx int // index in the standard form's entries which lists the non-standard form.
+}
+
+// Lookup behaves the same as [Dictionary.Lemma], but it also recognizes other forms of the lemma.
+// If lemma is not found, Lookup falls back to the following in order:
+//  1. Entry variants (see [VariantKind]). The result will only contain the entry which owns the variant.
+//     entryNo is ignored in this case since the variant already refers to a single entry.
+//     E.g. terselip ke luar will be resolved to terselip.
+//  2. Non-standard forms (bentuk tidak baku). The result will only contain the entry which lists the non-standard form.
+//     entryNo is ignored in this case as well.
+//     E.g. apotik will be resolved to apotek.
+//  3. Reduplicated forms (kata ulang). The result will be the base lemma.
+//     E.g. anak-anak, berlari-lari, and kemerah-merahan will be resolved to anak, lari, and merah respectively.
+func (d *Dictionary) Lookup(lemma string, entryNo int) (LookupResult, error) {
+	data, err := d.Lemma(lemma, entryNo)
+	if err == nil {
+		match := MatchExact
+		if data.Lemma != lemma {
+			match = MatchNormalized
+		}
+
+		result := LookupResult{Lemma: data, Match: match}
+		if standardForm, ok := d.StandardForm(lemma); ok && standardForm != data.Lemma {
+			result.StandardForm = standardForm
+		}
+
+		return result, nil
+	}
+
+	// other forms can be longer than any lemma in the dictionary, so it is still worth to look for them.
+	if !errors.Is(err, ErrLemmaNotFound) && !errors.Is(err, ErrLemmaTooLong) {
+		return LookupResult{}, err
+	}
+
+	if result, ok, err := d.lookupVariant(lemma); ok || err != nil {
+		return result, err
+	}
+
+	if result, ok, err := d.lookupNonStandard(lemma); ok || err != nil {
+		return result, err
+	}
+
+	redup, ok := ParseReduplication(lemma)
+	if !ok {
+		return LookupResult{}, err
+	}
+
+	for _, base := range d.reduplicationBaseCandidates(redup) {
+		data, baseErr := d.Lemma(base, entryNo)
+		// the affixed base word may be longer than any lemma, while the stripped one is not.
+		if errors.Is(baseErr, ErrLemmaNotFound) || errors.Is(baseErr, ErrLemmaTooLong) {
+			continue
+		}
+		if baseErr != nil {
+			return LookupResult{}, baseErr
+		}
+
+		redup.BaseWord = base
+		return LookupResult{Lemma: data, Match: MatchReduplication, Reduplication: &redup}, nil
+	}
+
+	return LookupResult{}, err
+}
+
+// StandardForm returns the standard form (bentuk baku) of lemma if lemma is listed
+// as a non-standard form of another lemma.
+//
+// e.g. apotik will return (apotek, true)
+func (d *Dictionary) StandardForm(lemma string) (string, bool) {
+	index, ok := d.index.standardForm(variantKey(lemma))
+	if !ok {
+		return "", false
+	}
+
+	// the name is only empty in the invalid compiled dictionary.
+	standardForm := d.index.lemmaName(index.idx)
+	return standardForm, standardForm != ""
+}
+
+func (d *Dictionary) lookupNonStandard(lemma string) (LookupResult, bool, error) {
+	index, ok := d.index.standardForm(variantKey(lemma))
+	if !ok {
+		return LookupResult{}, false, nil
+	}
+
+	lemmaData, err := d.lemmaEntry(index.idx, index.entryIdx)
+	if err != nil {
+		return LookupResult{}, false, err
+	}
+
+	return LookupResult{
+		Lemma:        lemmaData,
+		Match:        MatchNonStandard,
+		StandardForm: lemmaData.Lemma,
+	}, true, nil
+}
+
+func (d *Dictionary) reduplicationBaseCandidates(redup Reduplication) []string {
+	candidates := redup.BaseCandidates()
+
+	// the base word of partial reduplication may still have affixes. E.g. pepohonan -> pohonan -> pohon
+	if redup.Type == ReduplicationPartial {
+		candidates = append(candidates, StripAffixes(redup.BaseWord)...)
+	}
+
+	return candidates
+}
@@ -0,0 +1,18 @@
+//go:build !unix
+
+package dictionary
+
+import (
+	"fmt"
+	"os"
+)
+
+// mapFile reads the whole file into memory, since it can't be mapped on this platform.
+func mapFile(path string) (data []byte, unmap func() error, err error) {
+	data, err = os.ReadFile(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("os.ReadFile: %w", err)
+	}
+
+	return data, func() error { return nil }, nil
+}
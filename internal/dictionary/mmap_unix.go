@@ -0,0 +1,41 @@
+//go:build unix
+
+package dictionary
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// mapFile maps the file into memory read-only. The mapping stays valid after the file is closed, until unmap is called.
+func mapFile(path string) (data []byte, unmap func() error, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("os.Open: %w", err)
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, nil, fmt.Errorf("f.Stat: %w", err)
+	}
+
+	size := fi.Size()
+	if size == 0 {
+		return nil, nil, errors.New("empty file")
+	}
+	if int64(int(size)) != size {
+		return nil, nil, fmt.Errorf("file too large to map: %d bytes", size)
+	}
+
+	data, err = syscall.Mmap(int(f.Fd()), 0, int(size), syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, nil, fmt.Errorf("syscall.Mmap: %w", err)
+	}
+
+	return data, func() error { return syscall.Munmap(data) }, nil
+}
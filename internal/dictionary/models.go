@@ -1,6 +1,10 @@
 package dictionary
 
 import (
+	"io"
+	"time"
+
+	"github.com/raf555/kbbi-api/internal/plaintext"
 	"github.com/raf555/kbbi-api/pkg/kbbi"
 )
 
@@ -10,15 +14,52 @@ type AssetData struct {
 }
 
 type Stats struct {
-	Edition    string `json:"edition"`
-	EntryCount int    `json:"entryCount"`
-	LemmaCount int    `json:"lemmaCount"`
+	Edition    string `json:"edition" xml:"edition"`
+	EntryCount int    `json:"entryCount" xml:"entryCount"`
+	LemmaCount int    `json:"lemmaCount" xml:"lemmaCount"`
 }
 
+// AssetSource is where an asset of the snapshot is read from.
+type AssetSource string
+
+const (
+	// AssetSourceFile is the asset file in the assets directory.
+	AssetSourceFile AssetSource = "file"
+	// AssetSourceCompiled is the compiled dictionary, see [Configuration.CompiledDictionary].
+	AssetSourceCompiled AssetSource = "compiled"
+	// AssetSourceNetwork is the asset downloaded from its URL.
+	AssetSourceNetwork AssetSource = "network"
+	// AssetSourceCache is the cached asset which the server of the URL reports as not modified.
+	AssetSourceCache AssetSource = "cache"
+	// AssetSourceCacheFallback is the cached asset which is read since the URL can't be downloaded,
+	// so it may be older than the one on the server.
+	AssetSourceCacheFallback AssetSource = "cache-fallback"
+)
+
+// AssetSources are the sources of the assets of the snapshot.
+type AssetSources struct {
+	Dictionary AssetSource `json:"dictionary" xml:"dictionary"`
+	WOTD       AssetSource `json:"wotd" xml:"wotd"`
+}
+
+type NonStandardBehavior string
+
+const (
+	// NonStandardAnnotate resolves non-standard form to its standard form if the non-standard form is not found,
+	// and annotates the response with the standard form.
+	NonStandardAnnotate NonStandardBehavior = "annotate"
+	// NonStandardRedirect redirects non-standard form to its standard form.
+	NonStandardRedirect NonStandardBehavior = "redirect"
+	// NonStandardStrict never resolves non-standard form to its standard form, but still annotates the response.
+	NonStandardStrict NonStandardBehavior = "strict"
+)
+
 type EntryRequest struct {
 	Lemma string `uri:"entry" validate:"required"`
 	// EntryNo is optional; value 0 means "no specific entry number requested".
 	EntryNo int `form:"entryNo" validate:"gte=0"`
+	// OnNonStandard is optional; empty value means [NonStandardAnnotate].
+	OnNonStandard NonStandardBehavior `form:"onNonStandard" validate:"omitempty,oneof=redirect annotate strict"`
 }
 
 // transform mutates the EntryRequest in place by looking for an entry number in the lemma string.
@@ -32,7 +73,16 @@ func (e *EntryRequest) transform() {
 }
 
 type EntryResponse struct {
-	kbbi.Lemma
+	kbbi.Lemma `yaml:",inline"`
+
+	// StandardForm is only present if the requested lemma is a non-standard form of another lemma.
+	StandardForm string `json:"standardForm,omitempty" xml:"standardForm,omitempty"`
+
+	// Variant is only present if the requested lemma is resolved from one of its entry variants.
+	Variant *VariantMatch `json:"variant,omitempty" xml:"variant,omitempty"`
+
+	// Reduplication is only present if the requested lemma is resolved from its reduplicated form.
+	Reduplication *Reduplication `json:"reduplication,omitempty" xml:"reduplication,omitempty"`
 }
 
 type SearchRequest struct {
@@ -41,5 +91,44 @@ type SearchRequest struct {
 }
 
 type SearchResponse struct {
-	Lemmas []string `json:"lemmas"`
+	Lemmas []string `json:"lemmas" xml:"lemmas>lemma"`
+}
+
+func (r *EntryResponse) RenderText(w io.Writer, opts plaintext.Options) error {
+	var notes []string
+	if r.StandardForm != "" {
+		notes = append(notes, "bentuk baku: "+r.StandardForm)
+	}
+	if r.Variant != nil {
+		notes = append(notes, "varian dari entri "+r.Variant.Entry)
+	}
+	if r.Reduplication != nil {
+		notes = append(notes, "bentuk ulang dari "+r.Reduplication.BaseWord)
+	}
+
+	for _, note := range notes {
+		if err := plaintext.RenderNote(w, note, opts); err != nil {
+			return err
+		}
+	}
+
+	return plaintext.RenderLemma(w, r.Lemma, opts)
+}
+
+func (r *SearchResponse) RenderText(w io.Writer, opts plaintext.Options) error {
+	return plaintext.RenderList(w, r.Lemmas, opts)
+}
+
+type ReloadRequest struct {
+	Authorization string `header:"Authorization"`
+}
+
+// ReloadResult is the snapshot swapped in by [Reloader.Reload].
+type ReloadResult struct {
+	Stats   Stats        `json:"stats" xml:"stats"`
+	Sources AssetSources `json:"sources" xml:"sources"`
+	// ContentSHA256 is the hash of the JSON content of the dictionary asset, empty for the dictionary from assetData.
+	ContentSHA256 string    `json:"contentSha256,omitempty" xml:"contentSha256,omitempty"`
+	ReloadedAt    time.Time `json:"reloadedAt" xml:"reloadedAt"`
+	Elapsed       string    `json:"elapsed" xml:"elapsed"`
 }
@@ -0,0 +1,305 @@
+package dictionary
+
+import (
+	"strings"
+)
+
+type ReduplicationType string
+
+const (
+	// ReduplicationFull is a full reduplication (dwilingga). E.g. `anak-anak`.
+	ReduplicationFull ReduplicationType = "full"
+	// ReduplicationPartial is a reduplication of the first syllable (dwipurwa). E.g. `lelaki`, `tetamu`.
+	ReduplicationPartial ReduplicationType = "partial"
+	// ReduplicationAffixed is a reduplication combined with affixes. E.g. `berlari-lari`, `kemerah-merahan`.
+	ReduplicationAffixed ReduplicationType = "affixed"
+	// ReduplicationSoundChange is a reduplication with sound change (dwilingga salin suara). E.g. `sayur-mayur`.
+	ReduplicationSoundChange ReduplicationType = "soundChange"
+)
+
+// Reduplication describes a reduplicated word form (kata ulang).
+type Reduplication struct {
+	Type     ReduplicationType `json:"type" xml:"type"`
+	BaseWord string            `json:"baseWord" xml:"baseWord"`
+	Prefix   string            `json:"prefix" xml:"prefix"`
+	Suffix   string            `json:"suffix" xml:"suffix"`
+
+	// alternative is another base word candidate, only used for sound change reduplication
+	// since either side can be the base word. E.g. `bolak-balik` has `balik` as the base word.
+	alternative string
+}
+
+// BaseCandidates returns the base word candidates of the reduplication, ordered by preference.
+func (r Reduplication) BaseCandidates() []string {
+	if r.alternative == "" {
+		return []string{r.BaseWord}
+	}
+	return []string{r.BaseWord, r.alternative}
+}
+
+// ParseReduplication looks for a reduplication pattern in word.
+// ok will be false if word does not look like a reduplicated form.
+//
+// The result is only a candidate, the base word is not guaranteed to exist in the dictionary.
+//
+// e.g. berlari-lari will return ({affixed, lari, ber, ""}, true)
+func ParseReduplication(word string) (Reduplication, bool) {
+	word = strings.ToLower(word)
+
+	left, right, found := strings.Cut(word, "-")
+	if !found {
+		return parsePartialReduplication(word)
+	}
+
+	if left == "" || right == "" || strings.Contains(right, "-") {
+		return Reduplication{}, false
+	}
+
+	if left == right {
+		return Reduplication{Type: ReduplicationFull, BaseWord: left}, true
+	}
+
+	// the base word overlaps between the end of the left side and the start of the right side.
+	// e.g. ber(lari)-(lari), ke(merah)-(merah)an, (anak)-(anak)nya
+	if overlap := longestOverlap(left, right); overlap >= 2 {
+		return Reduplication{
+			Type:     ReduplicationAffixed,
+			BaseWord: right[:overlap],
+			Prefix:   left[:len(left)-overlap],
+			Suffix:   right[overlap:],
+		}, true
+	}
+
+	// the right side is the affixed form of the left side.
+	// e.g. tarik-menarik, pukul-memukul
+	for _, candidate := range StripAffixes(right) {
+		if candidate == left {
+			return Reduplication{
+				Type:     ReduplicationAffixed,
+				BaseWord: left,
+				Prefix:   right[:len(right)-commonSuffixLength(left, right)],
+			}, true
+		}
+	}
+
+	// both sides sound alike, i.e. similar length and only differ on some letters.
+	// e.g. sayur-mayur, lauk-pauk, bolak-balik
+	lengthDiff := len(left) - len(right)
+	if similarity := commonPrefixLength(left, right) + commonSuffixLength(left, right); similarity >= 2 && -2 <= lengthDiff && lengthDiff <= 2 {
+		return Reduplication{
+			Type:        ReduplicationSoundChange,
+			BaseWord:    left,
+			alternative: right,
+		}, true
+	}
+
+	return Reduplication{}, false
+}
+
+// parsePartialReduplication looks for dwipurwa pattern, i.e. the first consonant repeated with `e`.
+// e.g. lelaki -> laki, tetamu -> tamu
+func parsePartialReduplication(word string) (Reduplication, bool) {
+	if len(word) < 5 || word[1] != 'e' || word[0] != word[2] || !isConsonant(word[0]) {
+		return Reduplication{}, false
+	}
+
+	return Reduplication{Type: ReduplicationPartial, BaseWord: word[2:]}, true
+}
+
+var (
+	// particles are attached after the possessive clitics. E.g. buku-nya-lah.
+	particleClitics = []string{"lah", "kah", "tah", "pun"}
+	// possessiveClitics are personal pronoun enclitics.
+	possessiveClitics = []string{"nya", "ku", "mu"}
+	// proclitics are personal pronoun proclitics. E.g. ku-baca, kau-ambil.
+	proclitics = []string{"kau", "ku"}
+)
+
+// CliticSplit is a candidate of word split from its clitics.
+type CliticSplit struct {
+	// Base is the word without its clitics.
+	Base string
+	// Clitics holds the clitics in the order they appear in the word.
+	// Proclitics are suffixed with `-` and enclitics are prefixed with `-`. E.g. `ku-`, `-nya`.
+	Clitics []string
+}
+
+// SplitClitics returns the candidates of word without its clitics, ordered from the least stripped one.
+// The original word is not included in the candidates.
+//
+// e.g. bukunyalah will return [{bukunya, [-lah]}, {buku, [-nya, -lah]}]
+func SplitClitics(word string) []CliticSplit {
+	word = strings.ToLower(word)
+
+	var splits []CliticSplit
+
+	enclitics := []CliticSplit{{Base: word}}
+	if base, clitic, ok := cutClitic(word, particleClitics, strings.CutSuffix); ok {
+		enclitics = append(enclitics, CliticSplit{Base: base, Clitics: []string{"-" + clitic}})
+	}
+
+	for _, split := range enclitics {
+		if base, clitic, ok := cutClitic(split.Base, possessiveClitics, strings.CutSuffix); ok {
+			enclitics = append(enclitics, CliticSplit{Base: base, Clitics: append([]string{"-" + clitic}, split.Clitics...)})
+		}
+	}
+
+	for _, split := range enclitics {
+		if len(split.Clitics) > 0 {
+			splits = append(splits, split)
+		}
+	}
+
+	for _, split := range enclitics {
+		if base, clitic, ok := cutClitic(split.Base, proclitics, strings.CutPrefix); ok {
+			splits = append(splits, CliticSplit{Base: base, Clitics: append([]string{clitic + "-"}, split.Clitics...)})
+		}
+	}
+
+	return splits
+}
+
+func cutClitic(word string, clitics []string, cut func(s, affix string) (string, bool)) (string, string, bool) {
+	for _, clitic := range clitics {
+		// the remaining word should at least have 2 letters.
+		if base, ok := cut(word, clitic); ok && len(base) >= 2 {
+			return base, clitic, true
+		}
+	}
+	return "", "", false
+}
+
+// derivationalSuffixes are ordered so that the longest suffix is stripped first.
+var derivationalSuffixes = []string{"kan", "an", "i"}
+
+// StripAffixes returns the candidates of word without its derivational affixes, ordered from the least stripped one.
+// The original word is not included in the candidates.
+//
+// The rules loosely follow the Nazief & Adriani stemmer, but instead of looking up the dictionary on each step,
+// it returns all possible candidates and leaves the lookup to the caller.
+//
+// e.g. menyukai will return [sukai, menyuka, suka]
+func StripAffixes(word string) []string {
+	word = strings.ToLower(word)
+
+	var (
+		candidates []string
+		seen       = map[string]struct{}{word: {}}
+	)
+
+	add := func(candidate string) {
+		// stripped word should at least have 2 letters.
+		if len(candidate) < 2 {
+			return
+		}
+		if _, ok := seen[candidate]; ok {
+			return
+		}
+		seen[candidate] = struct{}{}
+		candidates = append(candidates, candidate)
+	}
+
+	suffixStripped := []string{word}
+	for _, suffix := range derivationalSuffixes {
+		if base, ok := strings.CutSuffix(word, suffix); ok && len(base) >= 3 {
+			suffixStripped = append(suffixStripped, base)
+		}
+	}
+
+	for _, base := range suffixStripped {
+		add(base)
+		for _, stripped := range stripPrefix(base) {
+			add(stripped)
+			// prefix can be stacked at most twice. E.g. di-per-, mem-per-, ke-ber-
+			for _, stripped := range stripPrefix(stripped) {
+				add(stripped)
+			}
+		}
+	}
+
+	return candidates
+}
+
+// stripPrefix returns candidates of word without its first derivational prefix.
+func stripPrefix(word string) []string {
+	var candidates []string
+
+	// me- and pe- have nasal variants which may replace the first letter of the base word.
+	for _, prefix := range []string{"me", "pe"} {
+		rest, ok := strings.CutPrefix(word, prefix)
+		if !ok {
+			continue
+		}
+
+		if strings.HasPrefix(rest, "nge") && len(rest) <= 6 { // monosyllabic base word. E.g. mengebom -> bom
+			candidates = append(candidates, rest[3:])
+		}
+
+		switch {
+		case strings.HasPrefix(rest, "ng"):
+			if rest := rest[2:]; rest != "" && isVowel(rest[0]) {
+				candidates = append(candidates, "k"+rest) // mengenal -> kenal
+			}
+			candidates = append(candidates, rest[2:]) // menggali -> gali, mengambil -> ambil
+		case strings.HasPrefix(rest, "ny"):
+			candidates = append(candidates, "s"+rest[2:]) // menyapu -> sapu
+		case strings.HasPrefix(rest, "m"):
+			if rest := rest[1:]; rest != "" && isVowel(rest[0]) {
+				candidates = append(candidates, "p"+rest) // memukul -> pukul
+			}
+			candidates = append(candidates, rest[1:]) // membaca -> baca
+		case strings.HasPrefix(rest, "n"):
+			if rest := rest[1:]; rest != "" && isVowel(rest[0]) {
+				candidates = append(candidates, "t"+rest) // menulis -> tulis
+			}
+			candidates = append(candidates, rest[1:]) // mendengar -> dengar
+		case strings.HasPrefix(rest, "r"):
+			candidates = append(candidates, rest[1:]) // perbuat -> buat
+			candidates = append(candidates, rest)     // merasa -> rasa
+		default:
+			candidates = append(candidates, rest) // melihat -> lihat, pelari -> lari
+		}
+	}
+
+	for _, prefix := range []string{"ber", "ter", "bel", "di", "ke", "se", "be", "te"} {
+		if rest, ok := strings.CutPrefix(word, prefix); ok {
+			candidates = append(candidates, rest)
+		}
+	}
+
+	return candidates
+}
+
+// longestOverlap returns the length of the longest suffix of a which is also the prefix of b.
+func longestOverlap(a, b string) int {
+	for k := min(len(a), len(b)); k > 0; k-- {
+		if a[len(a)-k:] == b[:k] {
+			return k
+		}
+	}
+	return 0
+}
+
+func commonPrefixLength(a, b string) int {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	return n
+}
+
+func commonSuffixLength(a, b string) int {
+	n := 0
+	for n < len(a) && n < len(b) && a[len(a)-1-n] == b[len(b)-1-n] {
+		n++
+	}
+	return n
+}
+
+func isVowel(c byte) bool {
+	return strings.IndexByte("aiueo", c) >= 0
+}
+
+func isConsonant(c byte) bool {
+	return 'a' <= c && c <= 'z' && !isVowel(c)
+}
@@ -0,0 +1,194 @@
+package dictionary_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/raf555/kbbi-api/internal/dictionary"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseReduplication(t *testing.T) {
+	tcs := []struct {
+		in string
+
+		expectedOk         bool
+		expectedType       dictionary.ReduplicationType
+		expectedCandidates []string
+		expectedPrefix     string
+		expectedSuffix     string
+	}{
+		{
+			in:         "anak",
+			expectedOk: false,
+		},
+		{
+			in:         "-anak",
+			expectedOk: false,
+		},
+		{
+			in:         "anak-",
+			expectedOk: false,
+		},
+		{
+			in:         "jawa-bali",
+			expectedOk: false,
+		},
+		{
+			in:                 "anak-anak",
+			expectedOk:         true,
+			expectedType:       dictionary.ReduplicationFull,
+			expectedCandidates: []string{"anak"},
+		},
+		{
+			in:                 "Kupu-Kupu",
+			expectedOk:         true,
+			expectedType:       dictionary.ReduplicationFull,
+			expectedCandidates: []string{"kupu"},
+		},
+		{
+			in:                 "berlari-lari",
+			expectedOk:         true,
+			expectedType:       dictionary.ReduplicationAffixed,
+			expectedCandidates: []string{"lari"},
+			expectedPrefix:     "ber",
+		},
+		{
+			in:                 "kemerah-merahan",
+			expectedOk:         true,
+			expectedType:       dictionary.ReduplicationAffixed,
+			expectedCandidates: []string{"merah"},
+			expectedPrefix:     "ke",
+			expectedSuffix:     "an",
+		},
+		{
+			in:                 "anak-anaknya",
+			expectedOk:         true,
+			expectedType:       dictionary.ReduplicationAffixed,
+			expectedCandidates: []string{"anak"},
+			expectedSuffix:     "nya",
+		},
+		{
+			in:                 "tarik-menarik",
+			expectedOk:         true,
+			expectedType:       dictionary.ReduplicationAffixed,
+			expectedCandidates: []string{"tarik"},
+			expectedPrefix:     "men",
+		},
+		{
+			in:                 "sayur-mayur",
+			expectedOk:         true,
+			expectedType:       dictionary.ReduplicationSoundChange,
+			expectedCandidates: []string{"sayur", "mayur"},
+		},
+		{
+			in:                 "bolak-balik",
+			expectedOk:         true,
+			expectedType:       dictionary.ReduplicationSoundChange,
+			expectedCandidates: []string{"bolak", "balik"},
+		},
+		{
+			in:                 "tetamu",
+			expectedOk:         true,
+			expectedType:       dictionary.ReduplicationPartial,
+			expectedCandidates: []string{"tamu"},
+		},
+		{
+			in:         "meja",
+			expectedOk: false,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(fmt.Sprintf("input=%s", tc.in), func(t *testing.T) {
+			redup, ok := dictionary.ParseReduplication(tc.in)
+
+			if !tc.expectedOk {
+				assert.False(t, ok)
+				assert.Zero(t, redup)
+				return
+			}
+
+			assert.True(t, ok)
+			assert.Equal(t, tc.expectedType, redup.Type)
+			assert.Equal(t, tc.expectedCandidates, redup.BaseCandidates())
+			assert.Equal(t, tc.expectedPrefix, redup.Prefix)
+			assert.Equal(t, tc.expectedSuffix, redup.Suffix)
+		})
+	}
+}
+
+func TestSplitClitics(t *testing.T) {
+	tcs := []struct {
+		in       string
+		expected []dictionary.CliticSplit
+	}{
+		{
+			in:       "meja",
+			expected: nil,
+		},
+		{
+			in: "bukunya",
+			expected: []dictionary.CliticSplit{
+				{Base: "buku", Clitics: []string{"-nya"}},
+			},
+		},
+		{
+			in: "bukunyalah",
+			expected: []dictionary.CliticSplit{
+				{Base: "bukunya", Clitics: []string{"-lah"}},
+				{Base: "buku", Clitics: []string{"-nya", "-lah"}},
+			},
+		},
+		{
+			in: "kubaca",
+			expected: []dictionary.CliticSplit{
+				{Base: "baca", Clitics: []string{"ku-"}},
+			},
+		},
+		{
+			in: "kauambilkah",
+			expected: []dictionary.CliticSplit{
+				{Base: "kauambil", Clitics: []string{"-kah"}},
+				{Base: "ambilkah", Clitics: []string{"kau-"}},
+				{Base: "ambil", Clitics: []string{"kau-", "-kah"}},
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(fmt.Sprintf("input=%s", tc.in), func(t *testing.T) {
+			assert.Equal(t, tc.expected, dictionary.SplitClitics(tc.in))
+		})
+	}
+}
+
+func TestStripAffixes(t *testing.T) {
+	tcs := []struct {
+		in       string
+		contains string
+	}{
+		{in: "berlari", contains: "lari"},
+		{in: "menyukai", contains: "suka"},
+		{in: "memukul", contains: "pukul"},
+		{in: "menulis", contains: "tulis"},
+		{in: "mengenal", contains: "kenal"},
+		{in: "mengambil", contains: "ambil"},
+		{in: "membaca", contains: "baca"},
+		{in: "melihat", contains: "lihat"},
+		{in: "mengebom", contains: "bom"},
+		{in: "makanan", contains: "makan"},
+		{in: "kebersihan", contains: "bersih"},
+		{in: "diperbaiki", contains: "baik"},
+		{in: "mempermainkan", contains: "main"},
+		{in: "pelari", contains: "lari"},
+	}
+
+	for _, tc := range tcs {
+		t.Run(fmt.Sprintf("input=%s", tc.in), func(t *testing.T) {
+			candidates := dictionary.StripAffixes(tc.in)
+			assert.Contains(t, candidates, tc.contains)
+			assert.NotContains(t, candidates, tc.in)
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package dictionary
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+var ErrInvalidAssetData = errors.New("dictionary: invalid asset data")
+
+// AssetKind is the kind of the asset content.
+type AssetKind string
+
+const (
+	// AssetKindDictionary is the dictionary asset, its content is [AssetData].
+	AssetKindDictionary AssetKind = "dict"
+	// AssetKindWOTD is the word of the day asset, its content is the list of the lemma indexes.
+	AssetKindWOTD AssetKind = "wotd"
+)
+
+// Filename is the file name of the asset read by the server.
+func (k AssetKind) Filename() string {
+	return string(k) + ".db"
+}
+
+// AssetManifest describes a packed asset. It is written next to the asset.
+type AssetManifest struct {
+	File string    `json:"file"`
+	Kind AssetKind `json:"kind"`
+	// Stats is only present for [AssetKindDictionary].
+	Stats *Stats `json:"stats,omitempty"`
+	// Count is the number of the lemma indexes, only present for [AssetKindWOTD].
+	Count int `json:"count,omitempty"`
+	// KeyID is the ID of the encryption key written in the envelope, if any.
+	KeyID string `json:"keyId,omitempty"`
+	// Signature is how the asset is signed, embedded or detached. It is empty if the asset is not signed.
+	Signature string `json:"signature,omitempty"`
+	// Size is the size of the encrypted asset in bytes.
+	Size int64 `json:"size"`
+	// SHA256 is the hash of the encrypted asset.
+	SHA256 string `json:"sha256"`
+	// ContentSHA256 is the hash of the JSON content, which stays the same if the asset is re-encrypted.
+	ContentSHA256 string    `json:"contentSha256"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
+
+// ParseAssetData decodes the dictionary source JSON, validates it against the kbbi schema and computes its stats.
+// The edition of the source stats is kept if edition is empty.
+func ParseAssetData(r io.Reader, edition string) (AssetData, error) {
+	var data AssetData
+	if err := decodeStrict(r, &data); err != nil {
+		return AssetData{}, err
+	}
+
+	if err := validateLemmas(data); err != nil {
+		return AssetData{}, err
+	}
+
+	if edition != "" {
+		data.Stats.Edition = edition
+	}
+	if data.Stats.Edition == "" {
+		return AssetData{}, fmt.Errorf("%w: empty edition", ErrInvalidAssetData)
+	}
+
+	data.Stats.LemmaCount = len(data.Lemmas)
+	data.Stats.EntryCount = 0
+	for _, lemma := range data.Lemmas {
+		data.Stats.EntryCount += len(lemma.Entries)
+	}
+
+	return data, nil
+}
+
+// ParseWOTDData decodes the word of the day source JSON, which is the list of the lemma indexes starting from 1.
+// lemmaCount is the number of the lemmas in the dictionary, the indexes are not checked against it if it is 0.
+func ParseWOTDData(r io.Reader, lemmaCount int) ([]int, error) {
+	var indexes []int
+	if err := decodeStrict(r, &indexes); err != nil {
+		return nil, err
+	}
+
+	if len(indexes) == 0 {
+		return nil, fmt.Errorf("%w: empty lemma indexes", ErrInvalidAssetData)
+	}
+
+	var errs []error
+	for i, idx := range indexes {
+		if !wotdIndexInRange(idx, lemmaCount) {
+			errs = append(errs, fmt.Errorf("%w: [%d]: lemma index %d is out of range", ErrInvalidAssetData, i, idx))
+		}
+	}
+
+	return indexes, errors.Join(errs...)
+}
+
+func decodeStrict(r io.Reader, target any) error {
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(target); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidAssetData, err)
+	}
+
+	if decoder.More() {
+		return fmt.Errorf("%w: unexpected data after the JSON value", ErrInvalidAssetData)
+	}
+
+	return nil
+}
+
+// validateLemmas checks the lemmas which would break the dictionary indexes.
+func validateLemmas(data AssetData) error {
+	if len(data.Lemmas) == 0 {
+		return fmt.Errorf("%w: empty lemmas", ErrInvalidAssetData)
+	}
+
+	var errs []error
+	invalid := func(i int, format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%w: lemmas[%d]: %s", ErrInvalidAssetData, i, fmt.Sprintf(format, args...)))
+	}
+
+	seen := make(map[string]struct{}, len(data.Lemmas))
+	for i, lemma := range data.Lemmas {
+		if strings.TrimSpace(lemma.Lemma) == "" {
+			invalid(i, "empty lemma")
+			continue
+		}
+
+		if _, ok := seen[lemma.Lemma]; ok {
+			invalid(i, "duplicate lemma %q", lemma.Lemma)
+		}
+		seen[lemma.Lemma] = struct{}{}
+
+		// the lemmas are not sorted here since the word of the day refers to the lemma index.
+		if i > 0 && Normalize(data.Lemmas[i-1].Lemma, true) > Normalize(lemma.Lemma, true) {
+			invalid(i, "lemma %q is not sorted, it should be before %q", lemma.Lemma, data.Lemmas[i-1].Lemma)
+		}
+
+		if len(lemma.Entries) == 0 {
+			invalid(i, "lemma %q has no entry", lemma.Lemma)
+		}
+		for j, entry := range lemma.Entries {
+			if strings.TrimSpace(entry.Entry) == "" {
+				invalid(i, "entries[%d]: empty entry", j)
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// wotdIndexInRange reports whether the word of the day index, which starts from 1, refers to a lemma.
+// The upper bound is not checked if lemmaCount is 0.
+func wotdIndexInRange(idx, lemmaCount int) bool {
+	return idx >= 1 && (lemmaCount == 0 || idx <= lemmaCount)
+}
@@ -0,0 +1,133 @@
+package dictionary_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/raf555/kbbi-api/internal/dictionary"
+	"github.com/raf555/kbbi-api/pkg/kbbi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteAsset(t *testing.T) {
+	key := make([]byte, 32)
+	_, _ = rand.Read(key)
+
+	data := dictionary.AssetData{
+		Stats:  dictionary.Stats{Edition: "test", EntryCount: 2, LemmaCount: 1},
+		Lemmas: []kbbi.Lemma{newTestLemma("apel", kbbi.Entry{Entry: "apel (1)"}, kbbi.Entry{Entry: "apel (2)"})},
+	}
+
+	var buf bytes.Buffer
+	contentHash, err := dictionary.WriteAsset(&buf, data, key, dictionary.EnvelopeHeader{
+		ContentType: dictionary.AssetKindDictionary.ContentType(),
+	})
+	require.NoError(t, err)
+	assert.Len(t, contentHash, 64)
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "dict.db"), buf.Bytes(), 0o600))
+
+	var decoded dictionary.AssetData
+	require.NoError(t, dictionary.ReadAsset("dict.db", dir, dictionary.Keyring{{Key: key}}, nil).To(&decoded))
+	assert.Equal(t, data, decoded)
+}
+
+func TestParseAssetData(t *testing.T) {
+	tcs := []struct {
+		name    string
+		in      string
+		edition string
+
+		expectedStats dictionary.Stats
+		expectedErr   string
+	}{
+		{
+			name:          "computes stats",
+			in:            `{"stats":{"edition":"2024","lemmaCount":99},"lemmas":[{"lemma":"anak","entries":[{"entry":"anak"}]},{"lemma":"apel","entries":[{"entry":"apel (1)"},{"entry":"apel (2)"}]}]}`,
+			expectedStats: dictionary.Stats{Edition: "2024", EntryCount: 3, LemmaCount: 2},
+		},
+		{
+			name:          "overrides edition",
+			in:            `{"stats":{"edition":"2024"},"lemmas":[{"lemma":"anak","entries":[{"entry":"anak"}]}]}`,
+			edition:       "2025",
+			expectedStats: dictionary.Stats{Edition: "2025", EntryCount: 1, LemmaCount: 1},
+		},
+		{
+			name:        "unknown field",
+			in:          `{"stats":{"edition":"2024"},"lemmas":[{"lemma":"anak","entries":[{"entry":"anak","foo":1}]}]}`,
+			expectedErr: `unknown field "foo"`,
+		},
+		{
+			name:        "empty edition",
+			in:          `{"lemmas":[{"lemma":"anak","entries":[{"entry":"anak"}]}]}`,
+			expectedErr: "empty edition",
+		},
+		{
+			name:        "unsorted lemmas",
+			in:          `{"stats":{"edition":"2024"},"lemmas":[{"lemma":"apel","entries":[{"entry":"apel"}]},{"lemma":"anak","entries":[{"entry":"anak"}]}]}`,
+			expectedErr: `lemma "anak" is not sorted`,
+		},
+		{
+			name:        "duplicate lemma",
+			in:          `{"stats":{"edition":"2024"},"lemmas":[{"lemma":"anak","entries":[{"entry":"anak"}]},{"lemma":"anak","entries":[{"entry":"anak"}]}]}`,
+			expectedErr: `duplicate lemma "anak"`,
+		},
+		{
+			name:        "no entry",
+			in:          `{"stats":{"edition":"2024"},"lemmas":[{"lemma":"anak","entries":[]}]}`,
+			expectedErr: `lemma "anak" has no entry`,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := dictionary.ParseAssetData(strings.NewReader(tc.in), tc.edition)
+			if tc.expectedErr != "" {
+				assert.ErrorIs(t, err, dictionary.ErrInvalidAssetData)
+				assert.ErrorContains(t, err, tc.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedStats, data.Stats)
+		})
+	}
+}
+
+func TestParseWOTDData(t *testing.T) {
+	tcs := []struct {
+		name       string
+		in         string
+		lemmaCount int
+
+		expected    []int
+		expectedErr string
+	}{
+		{name: "valid", in: `[1,2,3]`, lemmaCount: 3, expected: []int{1, 2, 3}},
+		{name: "unchecked count", in: `[1,100]`, expected: []int{1, 100}},
+		{name: "zero index", in: `[0,1]`, lemmaCount: 3, expectedErr: "[0]: lemma index 0 is out of range"},
+		{name: "zero index with unchecked count", in: `[0]`, expectedErr: "[0]: lemma index 0 is out of range"},
+		{name: "past the last lemma", in: `[1,4]`, lemmaCount: 3, expectedErr: "[1]: lemma index 4 is out of range"},
+		{name: "empty", in: `[]`, expectedErr: "empty lemma indexes"},
+		{name: "not a list", in: `{"indexes":[1]}`, expectedErr: "invalid asset data"},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			indexes, err := dictionary.ParseWOTDData(strings.NewReader(tc.in), tc.lemmaCount)
+			if tc.expectedErr != "" {
+				assert.ErrorIs(t, err, dictionary.ErrInvalidAssetData)
+				assert.ErrorContains(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, indexes)
+		})
+	}
+}
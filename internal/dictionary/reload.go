@@ -0,0 +1,258 @@
+package dictionary
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// Reloader is the [SnapshotRepo] which serves the latest snapshot of the dictionary, along with its word of the day.
+// Reload builds a new snapshot in the background and swaps it in as a whole. Each request takes the current snapshot
+// once and is served by it from start to end, so the requests which already hold the old snapshot finish on it.
+type Reloader struct {
+	cfg    Configuration
+	logger *slog.Logger
+	// load loads a new snapshot.
+	load func(ctx context.Context) (*Dictionary, error)
+	// downloader is shared by the reloads and the poller, so the poller knows the version of the downloaded assets.
+	downloader *downloader
+
+	current atomic.Pointer[Dictionary]
+	// reloading is held while a snapshot is being loaded, so the triggers at the same time don't load it twice.
+	reloading sync.Mutex
+}
+
+// NewReloader loads the first snapshot, which fails the startup if it can't be loaded.
+func NewReloader(ctx context.Context, cfg Configuration, logger *slog.Logger) (*Reloader, error) {
+	r := &Reloader{cfg: cfg, logger: logger, downloader: newDownloader(cfg, logger)}
+	r.load = func(ctx context.Context) (*Dictionary, error) {
+		wotd, err := loadWOTD(ctx, cfg, r.downloader, logger)
+		if err != nil {
+			return nil, fmt.Errorf("loadWOTD: %w", err)
+		}
+
+		dict, err := loadDictionary(ctx, cfg, r.downloader, logger, wotd)
+		if err != nil {
+			return nil, fmt.Errorf("loadDictionary: %w", err)
+		}
+		dict.sources.WOTD = wotd.source
+
+		return dict, nil
+	}
+
+	dict, err := r.load(ctx)
+	r.downloader.finish(err)
+	if err != nil {
+		return nil, err
+	}
+	r.current.Store(dict)
+	logger.Info("Loaded dictionary",
+		slog.String("dictionary_source", string(dict.sources.Dictionary)),
+		slog.String("wotd_source", string(dict.sources.WOTD)),
+	)
+
+	return r, nil
+}
+
+// Current returns the current snapshot.
+func (r *Reloader) Current() DictionaryRepo {
+	return r.current.Load()
+}
+
+// Dictionary returns the current snapshot as the dictionary itself, for the commands which compile or lint it.
+func (r *Reloader) Dictionary() *Dictionary {
+	return r.current.Load()
+}
+
+// Reload loads a new snapshot and swaps it in. The current snapshot is kept if the new one fails to load or validate,
+// e.g. the asset is not signed by the trusted keys. trigger describes what triggers the reload for the logs.
+// It returns [ErrReloadInProgress] if another reload is in progress.
+func (r *Reloader) Reload(ctx context.Context, trigger string) (ReloadResult, error) {
+	if !r.reloading.TryLock() {
+		return ReloadResult{}, ErrReloadInProgress
+	}
+	defer r.reloading.Unlock()
+
+	start := time.Now()
+	logger := r.logger.With(slog.String("trigger", trigger))
+	logger.Info("Started reloading dictionary")
+
+	dict, err := r.load(ctx)
+	if err == nil {
+		err = dict.checkWOTD()
+	}
+	// the assets are only cached once they are swapped in, so the cache keeps the last good ones.
+	r.downloader.finish(err)
+	if err != nil {
+		logger.Error("Failed to reload dictionary, keeping the current one", slog.String("error", err.Error()))
+		return ReloadResult{}, fmt.Errorf("reload: %w", err)
+	}
+
+	old := r.current.Swap(dict)
+
+	res := ReloadResult{
+		Stats:         dict.stats,
+		Sources:       dict.sources,
+		ContentSHA256: dict.contentSHA256,
+		ReloadedAt:    time.Now(),
+		Elapsed:       time.Since(start).String(),
+	}
+	logger.Info("Finished reloading dictionary",
+		slog.String("old_edition", old.stats.Edition),
+		slog.String("old_content_sha256", old.contentSHA256),
+		slog.String("edition", res.Stats.Edition),
+		slog.String("content_sha256", res.ContentSHA256),
+		slog.String("dictionary_source", string(res.Sources.Dictionary)),
+		slog.String("wotd_source", string(res.Sources.WOTD)),
+		slog.String("elapsed", res.Elapsed),
+	)
+
+	return res, nil
+}
+
+// checkWOTD checks that the word of the day only points to the lemmas in the dictionary,
+// since the old snapshot serves it just fine while the new one would fail every day it is out of the range.
+func (d *Dictionary) checkWOTD() error {
+	if d.wotd == nil {
+		return nil
+	}
+
+	for i, idx := range d.wotd.LemmaIndexes() {
+		if !wotdIndexInRange(idx, d.index.lemmaCount()) {
+			return fmt.Errorf("%w: %d at position %d", ErrUnexpectedWotdIndex, idx, i)
+		}
+	}
+	return nil
+}
+
+// Watch reloads the dictionary on SIGHUP, on the changes of the asset files if [Configuration.WatchInterval] is set,
+// and on the new versions of the assets from the download URLs if [Configuration.PollInterval] is set, until ctx is done.
+func (r *Reloader) Watch(ctx context.Context) {
+	if r.cfg.PollInterval > 0 && len(r.remoteURLs()) > 0 {
+		go r.poll(ctx)
+	}
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGHUP)
+	defer signal.Stop(sig)
+
+	// the ticker never ticks if the watch is disabled.
+	var tick <-chan time.Time
+	if r.cfg.WatchInterval > 0 {
+		ticker := time.NewTicker(r.cfg.WatchInterval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	// the files are only reloaded once they stop changing for an interval, so a file which is being copied
+	// is not read halfway. The failed reload is not retried until the files change again.
+	seen := r.statAssets()
+	pending := seen
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case s := <-sig:
+			// the files read by this reload don't have to be reloaded again.
+			seen = r.statAssets()
+			pending = seen
+			_, _ = r.Reload(ctx, "signal "+s.String())
+		case <-tick:
+			stats := r.statAssets()
+			if slices.Equal(stats, seen) {
+				continue
+			}
+			if !slices.Equal(stats, pending) {
+				pending = stats
+				continue
+			}
+
+			seen = stats
+			_, _ = r.Reload(ctx, "asset files changed")
+		}
+	}
+}
+
+// poll checks the download URLs for new versions of the assets every [Configuration.PollInterval], and reloads the
+// dictionary if any of them changes. The new version is downloaded once, the reload reads the one downloaded here.
+// The version which fails to reload is not retried until the next version.
+func (r *Reloader) poll(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		changed := false
+		for _, url := range r.remoteURLs() {
+			ok, err := r.downloader.poll(ctx, url)
+			if err != nil {
+				if ctx.Err() == nil {
+					r.logger.Warn("Failed to poll asset", slog.String("url", url), slog.String("error", err.Error()))
+				}
+				continue
+			}
+			changed = changed || ok
+		}
+
+		if changed {
+			_, _ = r.Reload(ctx, "asset URL changed")
+		}
+	}
+}
+
+// remoteURLs are the download URLs of the assets read by the snapshot.
+func (r *Reloader) remoteURLs() []string {
+	var urls []string
+	if url := r.cfg.WOTD.DownloadURL; url != "" {
+		urls = append(urls, url)
+	}
+	if url := r.cfg.Dictionary.DownloadURL; url != "" && r.cfg.CompiledDictionary == "" {
+		urls = append(urls, url)
+	}
+	return urls
+}
+
+// assetFileStat is the part of the file info which changes when the file is replaced or modified.
+// Both are zero if the file doesn't exist.
+type assetFileStat struct {
+	size    int64
+	modTime int64
+}
+
+// statAssets stats the asset files read by the snapshot, the ones from the download URLs are not watched.
+func (r *Reloader) statAssets() []assetFileStat {
+	var paths []string
+	for kind, asset := range map[AssetKind]AssetConfig{AssetKindDictionary: r.cfg.Dictionary, AssetKindWOTD: r.cfg.WOTD} {
+		if asset.DownloadURL != "" || (kind == AssetKindDictionary && r.cfg.CompiledDictionary != "") {
+			continue
+		}
+		path := filepath.Join(r.cfg.AssetsDirectory, kind.Filename())
+		paths = append(paths, path, path+SignatureSuffix)
+	}
+	if r.cfg.CompiledDictionary != "" {
+		paths = append(paths, r.cfg.CompiledDictionary)
+	}
+	slices.Sort(paths)
+
+	stats := make([]assetFileStat, len(paths))
+	for i, path := range paths {
+		if fi, err := os.Stat(path); err == nil {
+			stats[i] = assetFileStat{size: fi.Size(), modTime: fi.ModTime().UnixNano()}
+		}
+	}
+	return stats
+}
@@ -0,0 +1,89 @@
+package dictionary_test
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/raf555/kbbi-api/internal/dictionary"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReloader_Reload(t *testing.T) {
+	key := bytes.Repeat([]byte{1}, 32)
+
+	writeAsset := func(t *testing.T, dir string, kind dictionary.AssetKind, content string) {
+		t.Helper()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, kind.Filename()), sealTestAsset(t, key, kind, content), 0o600))
+	}
+
+	tcs := []struct {
+		name string
+		dict string
+		wotd string
+
+		expectedEdition string
+		expectedErr     string
+	}{
+		{
+			name:            "new edition",
+			dict:            `{"stats":{"edition":"VII"},"lemmas":[{"lemma":"anak"},{"lemma":"apel"},{"lemma":"lari"}]}`,
+			wotd:            `[3]`,
+			expectedEdition: "VII",
+		},
+		{
+			name:            "corrupted dictionary",
+			dict:            `{"stats":{"edition":"VII"},"lemmas":[{"lemma":1}]}`,
+			wotd:            `[1]`,
+			expectedEdition: "VI",
+			expectedErr:     "lemma 0",
+		},
+		{
+			name:            "wotd out of range",
+			dict:            `{"stats":{"edition":"VII"},"lemmas":[{"lemma":"anak"}]}`,
+			wotd:            `[2]`,
+			expectedEdition: "VI",
+			expectedErr:     "unexpected wotd lemma index",
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeAsset(t, dir, dictionary.AssetKindDictionary, `{"stats":{"edition":"VI"},"lemmas":[{"lemma":"anak"},{"lemma":"apel"}]}`)
+			writeAsset(t, dir, dictionary.AssetKindWOTD, `[2]`)
+
+			cfg := dictionary.Configuration{AssetsEncryptionKey: key, AssetsDirectory: dir}
+			reloader, err := dictionary.NewReloader(t.Context(), cfg, slog.New(slog.DiscardHandler))
+			require.NoError(t, err)
+
+			old := reloader.Current()
+
+			writeAsset(t, dir, dictionary.AssetKindDictionary, tc.dict)
+			writeAsset(t, dir, dictionary.AssetKindWOTD, tc.wotd)
+
+			res, err := reloader.Reload(t.Context(), "test")
+			assert.Equal(t, tc.expectedEdition, reloader.Current().Stats().Edition)
+			if tc.expectedErr != "" {
+				assert.ErrorContains(t, err, tc.expectedErr)
+				assert.Same(t, old, reloader.Current())
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedEdition, res.Stats.Edition)
+			assert.NotEmpty(t, res.ContentSHA256)
+
+			// the old snapshot is still usable by the calls which hold it.
+			lemma, err := old.Lemma("apel", 0)
+			require.NoError(t, err)
+			assert.Equal(t, "apel", lemma.Lemma)
+
+			lemma, err = reloader.Current().LemmaOfTheDay()
+			require.NoError(t, err)
+			assert.Equal(t, "lari", lemma.Lemma)
+		})
+	}
+}
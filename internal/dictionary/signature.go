@@ -0,0 +1,216 @@
+package dictionary
+
+import (
+	"bufio"
+	"bytes"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/sha512"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+)
+
+// The embedded signature is prepended to the signed asset:
+//
+//	magic (4 bytes) | signature length (4 bytes, big-endian) | signature (JSON) | asset
+//
+// The detached signature is the same JSON in the file next to the asset, see [SignatureSuffix].
+const (
+	signatureMagic      = "KSIG"
+	signaturePrefixSize = len(signatureMagic) + 4
+	// maxSignatureSize guards against reading a corrupted signature length.
+	maxSignatureSize = 4 << 10
+
+	// SignatureSuffix is appended to the path or URL of the asset for its detached signature, e.g. dict.db.sig.
+	SignatureSuffix = ".sig"
+
+	// SignatureAlgorithmEd25519ph is Ed25519 over the SHA-512 of the asset, which is written by [SignAsset].
+	SignatureAlgorithmEd25519ph = "ed25519ph"
+)
+
+var ErrInvalidSignature = errors.New("dictionary: invalid asset signature")
+
+// Signature is the Ed25519 signature of the asset, which covers the whole encrypted asset.
+type Signature struct {
+	// KeyID identifies the public key which verifies the signature. It is empty if the key has no ID.
+	KeyID     string `json:"keyId,omitempty"`
+	Algorithm string `json:"algorithm"`
+	Signature []byte `json:"signature"`
+}
+
+// PublicKeys is the Ed25519 public keys which verify the asset signatures, parsed like [Keyring].
+type PublicKeys Keyring
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (k *PublicKeys) UnmarshalText(text []byte) error {
+	var keys Keyring
+	if err := keys.UnmarshalText(text); err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		if len(key.Key) != ed25519.PublicKeySize {
+			return fmt.Errorf("keyring: key %q: invalid Ed25519 public key size %d", key.ID, len(key.Key))
+		}
+	}
+
+	*k = PublicKeys(keys)
+	return nil
+}
+
+// SignAsset signs the encrypted asset with the Ed25519 private key, which is either the 32 bytes seed or the 64 bytes key.
+// The asset is signed with Ed25519ph, so the signature can be verified without reading the whole asset into memory.
+func SignAsset(asset []byte, key Key) (Signature, error) {
+	var privateKey ed25519.PrivateKey
+	switch len(key.Key) {
+	case ed25519.SeedSize:
+		privateKey = ed25519.NewKeyFromSeed(key.Key)
+	case ed25519.PrivateKeySize:
+		privateKey = ed25519.PrivateKey(key.Key)
+	default:
+		return Signature{}, fmt.Errorf("invalid Ed25519 private key size %d", len(key.Key))
+	}
+
+	digest := sha512.Sum512(asset)
+	sig, err := privateKey.Sign(nil, digest[:], &ed25519.Options{Hash: crypto.SHA512})
+	if err != nil {
+		return Signature{}, fmt.Errorf("privateKey.Sign: %w", err)
+	}
+
+	return Signature{
+		KeyID:     key.ID,
+		Algorithm: SignatureAlgorithmEd25519ph,
+		Signature: sig,
+	}, nil
+}
+
+// EmbedSignature prepends the signature to the asset.
+func EmbedSignature(asset []byte, sig Signature) ([]byte, error) {
+	sigJSON, err := json.Marshal(sig)
+	if err != nil {
+		return nil, fmt.Errorf("json.Marshal: %w", err)
+	}
+
+	signed := make([]byte, 0, signaturePrefixSize+len(sigJSON)+len(asset))
+	signed = append(signed, signatureMagic...)
+	signed = binary.BigEndian.AppendUint32(signed, uint32(len(sigJSON)))
+	signed = append(signed, sigJSON...)
+	return append(signed, asset...), nil
+}
+
+// SplitSignature splits the embedded signature from the asset. sig is nil if the asset has no embedded signature.
+// The signature is not verified, see [VerifyAsset].
+func SplitSignature(data []byte) (asset []byte, sig *Signature, err error) {
+	sig, size, err := readEmbeddedSignature(bufio.NewReader(bytes.NewReader(data)))
+	if err != nil {
+		return nil, nil, err
+	}
+	return data[size:], sig, nil
+}
+
+// readEmbeddedSignature reads the embedded signature, and returns it along with the number of the bytes read.
+// sig is nil if the asset has no embedded signature, in which case nothing is read.
+func readEmbeddedSignature(r *bufio.Reader) (sig *Signature, n int, err error) {
+	prefix, err := r.Peek(signaturePrefixSize)
+	if err != nil || !bytes.HasPrefix(prefix, []byte(signatureMagic)) {
+		return nil, 0, nil
+	}
+
+	size := binary.BigEndian.Uint32(prefix[len(signatureMagic):])
+	if size > maxSignatureSize {
+		return nil, 0, fmt.Errorf("%w: invalid embedded signature size %d", ErrInvalidSignature, size)
+	}
+
+	b := make([]byte, signaturePrefixSize+int(size))
+	if _, err := io.ReadFull(r, b); err != nil {
+		return nil, 0, fmt.Errorf("%w: invalid embedded signature size %d: %w", ErrInvalidSignature, size, err)
+	}
+
+	sig = &Signature{}
+	if err := json.Unmarshal(b[signaturePrefixSize:], sig); err != nil {
+		return nil, 0, fmt.Errorf("%w: embedded signature: %w", ErrInvalidSignature, err)
+	}
+
+	return sig, len(b), nil
+}
+
+// VerifyAsset verifies the signature of the asset with the public keys. The signature is either embedded in the asset,
+// or detached if it is not nil. It returns the asset without the embedded signature, and the key which verifies it.
+func VerifyAsset(data, detached []byte, keys PublicKeys) ([]byte, Key, error) {
+	n, key, err := verifyStream(bytes.NewReader(data), func() ([]byte, error) {
+		if detached == nil {
+			return nil, errors.New("the asset is not signed")
+		}
+		return detached, nil
+	}, keys)
+	if err != nil {
+		return nil, Key{}, err
+	}
+
+	return data[n:], key, nil
+}
+
+// verifyStream verifies the signature of the asset read from r, which is embedded or given by detached.
+// It returns the size of the embedded signature, which is where the asset starts, and the key which verifies it.
+// The asset is only hashed as it is read.
+func verifyStream(r io.Reader, detached func() ([]byte, error), keys PublicKeys) (int, Key, error) {
+	br := bufio.NewReader(r)
+	sig, n, err := readEmbeddedSignature(br)
+	if err != nil {
+		return 0, Key{}, err
+	}
+
+	if sig == nil {
+		b, err := detached()
+		if err != nil {
+			return 0, Key{}, fmt.Errorf("%w: %w", ErrInvalidSignature, err)
+		}
+
+		sig = &Signature{}
+		if err := json.Unmarshal(b, sig); err != nil {
+			return 0, Key{}, fmt.Errorf("%w: detached signature: %w", ErrInvalidSignature, err)
+		}
+	}
+
+	if sig.Algorithm != SignatureAlgorithmEd25519ph {
+		return 0, Key{}, fmt.Errorf("%w: unsupported algorithm %q", ErrInvalidSignature, sig.Algorithm)
+	}
+
+	h := sha512.New()
+	if _, err := io.Copy(h, br); err != nil {
+		return 0, Key{}, fmt.Errorf("read asset: %w", err)
+	}
+	digest, opts := h.Sum(nil), &ed25519.Options{Hash: crypto.SHA512}
+
+	candidates, err := Keyring(keys).candidates(sig.KeyID)
+	if err != nil {
+		return 0, Key{}, fmt.Errorf("%w: %w", ErrInvalidSignature, err)
+	}
+
+	for _, key := range candidates {
+		if ed25519.VerifyWithOptions(ed25519.PublicKey(key.Key), digest, sig.Signature, opts) == nil {
+			return n, key, nil
+		}
+	}
+
+	if sig.KeyID != "" {
+		return 0, Key{}, fmt.Errorf("%w: signature of key %q doesn't match", ErrInvalidSignature, sig.KeyID)
+	}
+	return 0, Key{}, fmt.Errorf("%w: signature doesn't match any of the keys", ErrInvalidSignature)
+}
+
+// signatureURLOf is the URL of the detached signature of the asset URL, the query is kept as is.
+func signatureURLOf(assetURL string) string {
+	u, err := url.Parse(assetURL)
+	if err != nil {
+		return assetURL + SignatureSuffix
+	}
+
+	u.Path += SignatureSuffix
+	u.RawPath = ""
+	return u.String()
+}
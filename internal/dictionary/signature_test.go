@@ -0,0 +1,155 @@
+package dictionary_test
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/raf555/kbbi-api/internal/dictionary"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyAsset(t *testing.T) {
+	seed, otherSeed := bytes.Repeat([]byte{1}, ed25519.SeedSize), bytes.Repeat([]byte{2}, ed25519.SeedSize)
+	publicKey := ed25519.NewKeyFromSeed(seed).Public().(ed25519.PublicKey)
+	keys := dictionary.PublicKeys{{ID: "sig1", Key: publicKey}}
+
+	asset := []byte("encrypted asset")
+	sign := func(t *testing.T, seed []byte, id string) dictionary.Signature {
+		sig, err := dictionary.SignAsset(asset, dictionary.Key{ID: id, Key: seed})
+		require.NoError(t, err)
+		return sig
+	}
+	embed := func(t *testing.T, data []byte, sig dictionary.Signature) []byte {
+		signed, err := dictionary.EmbedSignature(data, sig)
+		require.NoError(t, err)
+		return signed
+	}
+	detach := func(t *testing.T, sig dictionary.Signature) []byte {
+		b, err := json.Marshal(sig)
+		require.NoError(t, err)
+		return b
+	}
+
+	unsupported := sign(t, seed, "sig1")
+	unsupported.Algorithm = "rsa"
+
+	tcs := []struct {
+		name     string
+		data     []byte
+		detached []byte
+
+		expectedErr string
+	}{
+		{
+			name: "embedded",
+			data: embed(t, asset, sign(t, seed, "sig1")),
+		},
+		{
+			name:     "detached",
+			data:     asset,
+			detached: detach(t, sign(t, seed, "sig1")),
+		},
+		{
+			name:     "without key ID",
+			data:     asset,
+			detached: detach(t, sign(t, seed, "")),
+		},
+		{
+			name:        "tampered",
+			data:        embed(t, []byte("encrypted assez"), sign(t, seed, "sig1")),
+			expectedErr: `signature of key "sig1" doesn't match`,
+		},
+		{
+			name:        "other key",
+			data:        asset,
+			detached:    detach(t, sign(t, otherSeed, "")),
+			expectedErr: "signature doesn't match any of the keys",
+		},
+		{
+			name:        "unknown key ID",
+			data:        asset,
+			detached:    detach(t, sign(t, seed, "sig2")),
+			expectedErr: `key "sig2" is not in the keyring`,
+		},
+		{
+			name:        "unsupported algorithm",
+			data:        asset,
+			detached:    detach(t, unsupported),
+			expectedErr: `unsupported algorithm "rsa"`,
+		},
+		{
+			name:        "not signed",
+			data:        asset,
+			expectedErr: "the asset is not signed",
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			verified, key, err := dictionary.VerifyAsset(tc.data, tc.detached, keys)
+			if tc.expectedErr != "" {
+				assert.ErrorIs(t, err, dictionary.ErrInvalidSignature)
+				assert.ErrorContains(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, asset, verified)
+			assert.Equal(t, "sig1", key.ID)
+		})
+	}
+}
+
+func TestReadAsset_Verify(t *testing.T) {
+	key := dictionary.Keyring{{Key: bytes.Repeat([]byte{1}, 32)}}
+	seed := bytes.Repeat([]byte{2}, ed25519.SeedSize)
+	publicKeys := dictionary.PublicKeys{{ID: "sig1", Key: ed25519.NewKeyFromSeed(seed).Public().(ed25519.PublicKey)}}
+
+	var buf bytes.Buffer
+	_, err := dictionary.WriteAsset(&buf, []int{1, 2}, key[0].Key, dictionary.EnvelopeHeader{ContentType: dictionary.AssetKindWOTD.ContentType()})
+	require.NoError(t, err)
+	asset := buf.Bytes()
+
+	sig, err := dictionary.SignAsset(asset, dictionary.Key{ID: "sig1", Key: seed})
+	require.NoError(t, err)
+	sigJSON, err := json.Marshal(sig)
+	require.NoError(t, err)
+
+	t.Run("file", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "wotd.db"), asset, 0o600))
+
+		var indexes []int
+		err := dictionary.ReadAsset("wotd.db", dir, key, nil).Verify(publicKeys, "").To(&indexes)
+		assert.ErrorIs(t, err, dictionary.ErrInvalidSignature)
+
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "wotd.db"+dictionary.SignatureSuffix), sigJSON, 0o600))
+		require.NoError(t, dictionary.ReadAsset("wotd.db", dir, key, nil).Verify(publicKeys, "").To(&indexes))
+		assert.Equal(t, []int{1, 2}, indexes)
+	})
+
+	t.Run("URL", func(t *testing.T) {
+		mux := http.NewServeMux()
+		mux.HandleFunc("/wotd.db", func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write(asset)
+		})
+		mux.HandleFunc("/wotd.db.sig", func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write(sigJSON)
+		})
+		srv := httptest.NewServer(mux)
+		defer srv.Close()
+
+		var indexes []int
+		require.NoError(t, dictionary.ReadAssetFromURL(srv.URL+"/wotd.db?v=1", key, nil).Verify(publicKeys, "").To(&indexes))
+		assert.Equal(t, []int{1, 2}, indexes)
+
+		err := dictionary.ReadAssetFromURL(srv.URL+"/wotd.db", key, nil).Verify(publicKeys, srv.URL+"/missing.sig").To(&indexes)
+		assert.ErrorContains(t, err, "unexpected status code 404")
+	})
+}
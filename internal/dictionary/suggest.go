@@ -0,0 +1,140 @@
+package dictionary
+
+import (
+	"cmp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+const (
+	// suggestionMaxDistance is the maximum edit distance for a lemma to be suggested.
+	suggestionMaxDistance = 2
+	// suggestionWindow is the number of lemmas before and after the lemma position in the sorted lemmas
+	// to be compared by edit distance. It keeps the suggestion cheap since it doesn't need to scan the whole dictionary.
+	suggestionWindow = 100
+	// suggestionMinPrefixLength is the minimum length of prefix used to look for the neighbour lemmas.
+	suggestionMinPrefixLength = 3
+)
+
+// Suggest returns at most limit lemmas which are similar to lemma, ordered by relevance.
+// It is meant to be used when lemma is not found in the dictionary.
+//
+// The suggestions are taken from the following in order:
+//  1. Base word candidates of lemma after its affixes and clitics are stripped. E.g. bukunya -> buku
+//  2. Lemmas near lemma (in the sorted order) with the smallest edit distance. E.g. apell -> apel
+//  3. Lemmas with the longest common normalized prefix as lemma. E.g. apelxyz -> apel, apelan
+func (d *Dictionary) Suggest(lemma string, limit int) []string {
+	normalized := strings.ToLower(Normalize(lemma, true))
+	if normalized == "" || limit <= 0 {
+		return nil
+	}
+
+	var (
+		suggestions []string
+		seen        = map[int]struct{}{}
+	)
+
+	add := func(idx int) {
+		if _, ok := seen[idx]; ok || len(suggestions) >= limit {
+			return
+		}
+		seen[idx] = struct{}{}
+		suggestions = append(suggestions, d.index.lemmaName(idx))
+	}
+
+	for _, candidate := range stemCandidates(strings.ToLower(lemma)) {
+		if idx, ok := d.lookupInverseIndex(candidate); ok {
+			add(idx)
+		}
+	}
+
+	for _, idx := range d.nearestByEditDistance(normalized) {
+		add(idx)
+	}
+
+	for prefixLength := len(normalized); prefixLength >= suggestionMinPrefixLength && len(suggestions) < limit; prefixLength-- {
+		leftIdx, rightIdx := prefixRange(d.index.lemmaCount(), normalized[:prefixLength], d.index.normalizedForm)
+		for idx := leftIdx; idx < min(rightIdx, leftIdx+limit); idx++ {
+			add(idx)
+		}
+	}
+
+	return suggestions
+}
+
+// stemCandidates returns base word candidates of word without its clitics and affixes.
+func stemCandidates(word string) []string {
+	candidates := StripAffixes(word)
+	for _, split := range SplitClitics(word) {
+		candidates = append(candidates, split.Base)
+		candidates = append(candidates, StripAffixes(split.Base)...)
+	}
+	return candidates
+}
+
+// nearestByEditDistance returns lemma indexes around the position of normalized in the sorted lemmas
+// which edit distance is at most suggestionMaxDistance, ordered by the distance.
+func (d *Dictionary) nearestByEditDistance(normalized string) []int {
+	pos := sort.Search(d.index.lemmaCount(), func(i int) bool {
+		return d.index.normalizedForm(i) >= normalized
+	})
+
+	type candidate struct {
+		idx, distance int
+	}
+
+	var candidates []candidate
+	for idx := max(0, pos-suggestionWindow); idx < min(d.index.lemmaCount(), pos+suggestionWindow); idx++ {
+		distance := editDistance(normalized, strings.ToLower(d.index.normalizedForm(idx)), suggestionMaxDistance)
+		if distance <= suggestionMaxDistance {
+			candidates = append(candidates, candidate{idx, distance})
+		}
+	}
+
+	slices.SortStableFunc(candidates, func(a, b candidate) int {
+		return cmp.Compare(a.distance, b.distance)
+	})
+
+	idxs := make([]int, 0, len(candidates))
+	for _, c := range candidates {
+		idxs = append(idxs, c.idx)
+	}
+	return idxs
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+// If the distance is greater than maxDistance, it returns maxDistance+1 as early as possible.
+func editDistance(a, b string, maxDistance int) int {
+	if diff := len(a) - len(b); diff > maxDistance || -diff > maxDistance {
+		return maxDistance + 1
+	}
+
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		rowMin := curr[0]
+
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			rowMin = min(rowMin, curr[j])
+		}
+
+		if rowMin > maxDistance {
+			return maxDistance + 1
+		}
+
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
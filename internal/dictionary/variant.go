@@ -0,0 +1,67 @@
+package dictionary
+
+import (
+	"strings"
+)
+
+type VariantKind string
+
+const (
+	// VariantKindEntry is a variant from [kbbi.Entry.EntryVariants]. E.g. `terselip ke luar` of `terselip`.
+	VariantKindEntry VariantKind = "entryVariant"
+	// VariantKindWord is a variant from [kbbi.Entry.WordVariants]. E.g. `udeh` of `ude`.
+	VariantKindWord VariantKind = "wordVariant"
+)
+
+// VariantMatch describes the variant of an entry which the requested lemma is matched to.
+type VariantMatch struct {
+	// Form is the variant as written in the dictionary. E.g. `terselip ke luar`.
+	Form string `json:"form" xml:"form"`
+	// Kind is where the variant comes from.
+	Kind VariantKind `json:"kind" xml:"kind"`
+	// Entry is the entry which owns the variant. E.g. `ter.se.lip`.
+	Entry string `json:"entry" xml:"entry"`
+}
+
+type variantIndex struct {
+	idx      int // index in lemmas.
+	entryIdx int // index in the lemma's entries.
+	kind     VariantKind
+	form     string
+}
+
+type wrappedVariant struct {
+	*variantIndex
+
+	NormalizedForm string
+}
+
+// variantKey returns the key of variant in the variant index.
+// Variant is matched case insensitively, without diacritics, and with collapsed whitespaces
+// since most of them are multi-word expressions.
+func variantKey(variant string) string {
+	return strings.ToLower(strings.Join(strings.Fields(Normalize(variant, false)), " "))
+}
+
+func (d *Dictionary) lookupVariant(lemma string) (LookupResult, bool, error) {
+	index, ok := d.index.variant(variantKey(lemma))
+	if !ok {
+		return LookupResult{}, false, nil
+	}
+
+	lemmaData, err := d.lemmaEntry(index.idx, index.entryIdx)
+	if err != nil {
+		return LookupResult{}, false, err
+	}
+	entry := lemmaData.Entries[0]
+
+	return LookupResult{
+		Lemma: lemmaData,
+		Match: MatchVariant,
+		Variant: &VariantMatch{
+			Form:  index.form,
+			Kind:  index.kind,
+			Entry: entry.Entry,
+		},
+	}, true, nil
+}
@@ -1,6 +1,7 @@
 package dictionary
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"math/rand/v2"
@@ -10,23 +11,23 @@ import (
 type WOTD struct {
 	lemmaIndexes []int
 	epoch        int64
+	source       AssetSource
 }
 
-func NewWOTD(env Configuration, logger *slog.Logger) (*WOTD, error) {
+func loadWOTD(ctx context.Context, env Configuration, dl *downloader, logger *slog.Logger) (*WOTD, error) {
 	var lemmaIndexes []int
 
-	var reader *reader
 	if url := env.WOTD.DownloadURL; url != "" {
 		logger.Info("reading WOTD asset from URL", slog.String("url", url))
-		reader = ReadAssetFromURL(url, env.AssetsEncryptionKey, env.AssetsEncryptionIV)
-	} else {
-		reader = ReadAsset("wotd.db", env.AssetsDirectory, env.AssetsEncryptionKey, env.AssetsEncryptionIV)
 	}
+	reader := env.assetReader(ctx, AssetKindWOTD, env.WOTD, dl)
 
 	if err := reader.To(&lemmaIndexes); err != nil {
 		return nil, fmt.Errorf("ReadAsset: %w", err)
 	}
 
+	reader.logEnvelope(logger)
+
 	loc, err := time.LoadLocation("Asia/Jakarta")
 	if err != nil {
 		return nil, fmt.Errorf("time.LoadLocation: %w", err)
@@ -35,6 +36,7 @@ func NewWOTD(env Configuration, logger *slog.Logger) (*WOTD, error) {
 	repo := &WOTD{
 		lemmaIndexes: lemmaIndexes,
 		epoch:        time.Date(2022, time.October, 30, 0, 0, 0, 0, loc).UnixMilli(),
+		source:       reader.assetSource,
 	}
 
 	return repo, nil
@@ -51,3 +53,8 @@ func (w *WOTD) TodayLemmaIndex() int {
 
 	return w.lemmaIndexes[j]
 }
+
+// LemmaIndexes returns the lemma indexes of the word of the day, starting from 1.
+func (w *WOTD) LemmaIndexes() []int {
+	return w.lemmaIndexes
+}
@@ -12,6 +12,12 @@ var _ encoding.TextUnmarshaler = (*HexString)(nil)
 
 // UnmarshalText implements encoding.TextUnmarshaler.
 func (h *HexString) UnmarshalText(text []byte) error {
+	// the empty value is kept nil, so it is not considered present by the validator, e.g. on required_without.
+	if len(text) == 0 {
+		*h = nil
+		return nil
+	}
+
 	b, err := hex.DecodeString(string(text))
 	if err != nil {
 		return fmt.Errorf("hex.DecodeString: %w", err)
@@ -11,9 +11,12 @@ var Module = fx.Module(
 	"home",
 
 	fx.Provide(
-		func(dict *dictionary.Dictionary) home.AssetStatsFetcher {
-			return dict
+		func(snapshots dictionary.SnapshotRepo) home.AssetStatsSnapshot {
+			return func() home.AssetStatsFetcher {
+				return snapshots.Current()
+			}
 		},
+		fx.Private,
 	),
 
 	httpfx.HandlerProvider(
@@ -8,17 +8,16 @@ import (
 )
 
 type HTTPHandler struct {
-	statsFetcher AssetStatsFetcher
-	baseResponse *HomeResponse
+	statsSnapshot AssetStatsSnapshot
+	baseResponse  HomeResponse
 }
 
-func NewHTTPHandler(statsFetcher AssetStatsFetcher) *HTTPHandler {
+func NewHTTPHandler(statsSnapshot AssetStatsSnapshot) *HTTPHandler {
 	return &HTTPHandler{
-		statsFetcher: statsFetcher,
+		statsSnapshot: statsSnapshot,
 
-		baseResponse: &HomeResponse{
+		baseResponse: HomeResponse{
 			Message:       "Welcome to the (unofficial) KBBI API",
-			Stats:         statsFetcher.Stats(),
 			Documentation: "https://kbbi.raf555.dev/swagger/index.html",
 			Issues:        "https://github.com/raf555/kbbi-api/issues",
 		},
@@ -36,7 +35,12 @@ func (h *HTTPHandler) MustRegisterRoutes(g *gin.Engine) {
 }
 
 func (h *HTTPHandler) Home(ctx context.Context) (*HomeResponse, error) {
-	return h.baseResponse, nil
+	// the stats are fetched on every request, since the dictionary may be reloaded.
+	statsFetcher := h.statsSnapshot()
+	res := h.baseResponse
+	res.Stats = statsFetcher.Stats()
+	res.Sources = statsFetcher.Sources()
+	return &res, nil
 }
 
 func (h *HTTPHandler) Health(ctx context.Context) (*HealthResponse, error) {
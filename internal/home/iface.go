@@ -4,4 +4,8 @@ import "github.com/raf555/kbbi-api/internal/dictionary"
 
 type AssetStatsFetcher interface {
 	Stats() dictionary.Stats
+	Sources() dictionary.AssetSources
 }
+
+// AssetStatsSnapshot returns the AssetStatsFetcher of the current dictionary snapshot.
+type AssetStatsSnapshot func() AssetStatsFetcher
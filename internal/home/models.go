@@ -3,12 +3,14 @@ package home
 import "github.com/raf555/kbbi-api/internal/dictionary"
 
 type HomeResponse struct {
-	Message       string           `json:"message"`
-	Stats         dictionary.Stats `json:"stats"`
-	Documentation string           `json:"documentation"`
-	Issues        string           `json:"issues"`
+	Message string           `json:"message" xml:"message"`
+	Stats   dictionary.Stats `json:"stats" xml:"stats"`
+	// Sources are where the assets are read from, e.g. the cache if the download URLs are unreachable.
+	Sources       dictionary.AssetSources `json:"sources" xml:"sources"`
+	Documentation string                  `json:"documentation" xml:"documentation"`
+	Issues        string                  `json:"issues" xml:"issues"`
 }
 
 type HealthResponse struct {
-	Message string `json:"message"`
+	Message string `json:"message" xml:"message"`
 }
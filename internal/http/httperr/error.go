@@ -1,14 +1,18 @@
 package httperr
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 )
 
 type httpError struct {
-	inner error
-	code  int
-	msg   string
+	inner       error
+	code        int
+	msg         string
+	errCode     string
+	suggestions []string
 }
 
 func (e *httpError) Error() string {
@@ -31,22 +35,49 @@ func Newf(code int, message string, args ...any) error {
 	return Wrapf(nil, code, message, args...)
 }
 
+// Wrap wraps err with an HTTP status code and message.
+// The message will be shown in the HTTP response while err is kept internally for logging.
+//
+// If any error in err's chain has an ErrorCode() string method, the code will be used as the error code of the response.
 func Wrap(err error, code int, message string) error {
 	return &httpError{
-		inner: err,
-		code:  code,
-		msg:   message,
+		inner:   err,
+		code:    code,
+		msg:     message,
+		errCode: errorCodeOf(err),
 	}
 }
 
 // Wrapf wraps err with an HTTP status code and formats the message with args.
 // The formatted message will be shown in the HTTP response while err is kept internally for logging
 func Wrapf(err error, code int, message string, args ...any) error {
-	return &httpError{
-		inner: err,
-		code:  code,
-		msg:   fmt.Sprintf(message, args...),
+	return Wrap(err, code, fmt.Sprintf(message, args...))
+}
+
+// WithCode overrides the error code of err to be shown in the HTTP response.
+// err must be created by this package, otherwise err is returned as is.
+func WithCode(err error, errCode string) error {
+	hErr, ok := err.(*httpError)
+	if !ok {
+		return err
 	}
+
+	withCode := *hErr
+	withCode.errCode = errCode
+	return &withCode
+}
+
+// WithSuggestions attaches suggestions to err to be shown in the HTTP response, e.g. "did you mean" suggestions.
+// err must be created by this package, otherwise err is returned as is.
+func WithSuggestions(err error, suggestions []string) error {
+	hErr, ok := err.(*httpError)
+	if !ok {
+		return err
+	}
+
+	withSuggestions := *hErr
+	withSuggestions.suggestions = suggestions
+	return &withSuggestions
 }
 
 func (h *httpError) HTTPStatusCode() int {
@@ -57,6 +88,14 @@ func (h *httpError) HTTPResponseMessage() string {
 	return h.msg
 }
 
+func (h *httpError) HTTPErrorCode() string {
+	return h.errCode
+}
+
+func (h *httpError) HTTPResponseSuggestions() []string {
+	return h.suggestions
+}
+
 // HTTPStatusCode returns associated status code from the err.
 // If err is nil, it will return [http.StatusOK].
 // If err implements HTTPStatusCoder, it will return associated status code.
@@ -81,3 +120,46 @@ func HTTPResponseMessage(err error) (string, bool) {
 
 	return "", false
 }
+
+func HTTPResponseSuggestions(err error) []string {
+	if suggester, ok := err.(interface{ HTTPResponseSuggestions() []string }); ok {
+		return suggester.HTTPResponseSuggestions()
+	}
+
+	return nil
+}
+
+// HTTPErrorCode returns the machine-readable error code associated with err.
+// If err does not have any error code, the code is derived from its status code. E.g. 404 becomes NOT_FOUND.
+func HTTPErrorCode(err error) string {
+	if coder, ok := err.(interface{ HTTPErrorCode() string }); ok {
+		if errCode := coder.HTTPErrorCode(); errCode != "" {
+			return errCode
+		}
+	}
+
+	return StatusErrorCode(HTTPStatusCode(err))
+}
+
+// StatusErrorCode returns the error code derived from the HTTP status code.
+// E.g. 404 becomes NOT_FOUND and 500 becomes INTERNAL_SERVER_ERROR.
+func StatusErrorCode(code int) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case 'a' <= r && r <= 'z':
+			return r - 'a' + 'A'
+		case 'A' <= r && r <= 'Z':
+			return r
+		default:
+			return '_'
+		}
+	}, http.StatusText(code))
+}
+
+func errorCodeOf(err error) string {
+	var coder interface{ ErrorCode() string }
+	if errors.As(err, &coder) {
+		return coder.ErrorCode()
+	}
+	return ""
+}
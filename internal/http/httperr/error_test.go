@@ -0,0 +1,112 @@
+package httperr_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/raf555/kbbi-api/internal/dictionary"
+	"github.com/raf555/kbbi-api/internal/http/httperr"
+	"github.com/raf555/kbbi-api/pkg/kbbi"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPErrorCode(t *testing.T) {
+	tcs := []struct {
+		name string
+		err  error
+
+		expectedStatus int
+		expectedCode   kbbi.ErrorCode
+	}{
+		{
+			name:           "sentinel error",
+			err:            httperr.Wrap(dictionary.ErrLemmaNotFound, http.StatusNotFound, "lemma not found"),
+			expectedStatus: http.StatusNotFound,
+			expectedCode:   kbbi.ErrorCodeLemmaNotFound,
+		},
+		{
+			name:           "wrapped sentinel error",
+			err:            httperr.Wrap(fmt.Errorf("dict.Lemma: %w", dictionary.ErrLemmaTooLong), http.StatusRequestURITooLong, "lemma is too long"),
+			expectedStatus: http.StatusRequestURITooLong,
+			expectedCode:   kbbi.ErrorCodeLemmaTooLong,
+		},
+		{
+			name:           "sentinel error of another status",
+			err:            httperr.Wrap(dictionary.ErrReloadInProgress, http.StatusConflict, "reloading"),
+			expectedStatus: http.StatusConflict,
+			expectedCode:   kbbi.ErrorCodeReloadInProgress,
+		},
+		{
+			name:           "code overrides sentinel error",
+			err:            httperr.WithCode(httperr.Wrap(dictionary.ErrLemmaNotFound, http.StatusBadRequest, "invalid"), string(kbbi.ErrorCodeValidationFailed)),
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   kbbi.ErrorCodeValidationFailed,
+		},
+		{
+			name:           "code of error without sentinel",
+			err:            httperr.WithCode(httperr.New(http.StatusBadRequest, "malformed"), string(kbbi.ErrorCodeInvalidRequest)),
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   kbbi.ErrorCodeInvalidRequest,
+		},
+		{
+			name:           "code of error from other package",
+			err:            httperr.WithCode(errors.New("boom"), string(kbbi.ErrorCodeInvalidRequest)),
+			expectedStatus: http.StatusInternalServerError,
+			expectedCode:   kbbi.ErrorCodeInternalServerError,
+		},
+		{
+			name:           "bad request",
+			err:            httperr.New(http.StatusBadRequest, "bad"),
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   kbbi.ErrorCodeBadRequest,
+		},
+		{
+			name:           "not found",
+			err:            httperr.Wrap(errors.New("no route"), http.StatusNotFound, "not found"),
+			expectedStatus: http.StatusNotFound,
+			expectedCode:   kbbi.ErrorCodeNotFound,
+		},
+		{
+			name:           "request URI too long",
+			err:            httperr.New(http.StatusRequestURITooLong, "too long"),
+			expectedStatus: http.StatusRequestURITooLong,
+			expectedCode:   kbbi.ErrorCodeRequestURITooLong,
+		},
+		{
+			name:           "error from other package",
+			err:            errors.New("boom"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedCode:   kbbi.ErrorCodeInternalServerError,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedStatus, httperr.HTTPStatusCode(tc.err))
+			assert.Equal(t, string(tc.expectedCode), httperr.HTTPErrorCode(tc.err))
+		})
+	}
+}
+
+func TestStatusErrorCode(t *testing.T) {
+	tcs := []struct {
+		status   int
+		expected kbbi.ErrorCode
+	}{
+		{status: http.StatusBadRequest, expected: kbbi.ErrorCodeBadRequest},
+		{status: http.StatusUnauthorized, expected: kbbi.ErrorCodeUnauthorized},
+		{status: http.StatusNotFound, expected: kbbi.ErrorCodeNotFound},
+		{status: http.StatusMethodNotAllowed, expected: kbbi.ErrorCodeMethodNotAllowed},
+		{status: http.StatusNotAcceptable, expected: kbbi.ErrorCodeNotAcceptable},
+		{status: http.StatusRequestURITooLong, expected: kbbi.ErrorCodeRequestURITooLong},
+		{status: http.StatusInternalServerError, expected: kbbi.ErrorCodeInternalServerError},
+	}
+
+	for _, tc := range tcs {
+		t.Run(http.StatusText(tc.status), func(t *testing.T) {
+			assert.Equal(t, string(tc.expected), httperr.StatusErrorCode(tc.status))
+		})
+	}
+}
@@ -6,6 +6,30 @@ import (
 
 	"github.com/go-playground/validator/v10"
 	"github.com/raf555/kbbi-api/internal/http/httperr"
+	"github.com/raf555/kbbi-api/pkg/kbbi"
+)
+
+var (
+	msgBindJSONFailed = Messages{
+		LocaleEnglish:    "failed to bind json body from request",
+		LocaleIndonesian: "gagal membaca body json dari permintaan",
+	}
+	msgBindHeaderFailed = Messages{
+		LocaleEnglish:    "failed to bind header from request",
+		LocaleIndonesian: "gagal membaca header dari permintaan",
+	}
+	msgBindQueryFailed = Messages{
+		LocaleEnglish:    "failed to bind query from request",
+		LocaleIndonesian: "gagal membaca query dari permintaan",
+	}
+	msgBindURIFailed = Messages{
+		LocaleEnglish:    "failed to bind uri params from request",
+		LocaleIndonesian: "gagal membaca parameter uri dari permintaan",
+	}
+	msgValidateFailed = Messages{
+		LocaleEnglish:    "failed to validate request",
+		LocaleIndonesian: "gagal memvalidasi permintaan",
+	}
 )
 
 type RequestBinder[req any] = func(GinMinimalContext) (*req, error)
@@ -24,17 +48,35 @@ func DefaultRequestBinder[reqT any](ctx GinMinimalContext) (*reqT, error) {
 	return &req, nil
 }
 
+// JSONRequestBinder will try to bind from header, query, uri, and JSON body into reqT.
+func JSONRequestBinder[reqT any](ctx GinMinimalContext) (*reqT, error) {
+	var req reqT
+	if err := commonBinder(ctx, &req); err != nil {
+		return nil, err
+	}
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		return nil, httperr.WithCode(httperr.Wrap(err, http.StatusBadRequest, msgBindJSONFailed.In(ctx)), string(kbbi.ErrorCodeInvalidRequest))
+	}
+
+	if err := validateStruct(ctx, &req); err != nil {
+		return nil, err
+	}
+
+	return &req, nil
+}
+
 func commonBinder[reqT any](ctx GinMinimalContext, req *reqT) error {
 	if err := ctx.ShouldBindHeader(req); err != nil {
-		return httperr.Wrap(err, http.StatusBadRequest, "failed to bind header from request")
+		return httperr.WithCode(httperr.Wrap(err, http.StatusBadRequest, msgBindHeaderFailed.In(ctx)), string(kbbi.ErrorCodeInvalidRequest))
 	}
 
 	if err := ctx.ShouldBindQuery(req); err != nil {
-		return httperr.Wrap(err, http.StatusBadRequest, "failed to bind query from request")
+		return httperr.WithCode(httperr.Wrap(err, http.StatusBadRequest, msgBindQueryFailed.In(ctx)), string(kbbi.ErrorCodeInvalidRequest))
 	}
 
 	if err := ctx.ShouldBindUri(req); err != nil {
-		return httperr.Wrap(err, http.StatusBadRequest, "failed to bind uri params from request")
+		return httperr.WithCode(httperr.Wrap(err, http.StatusBadRequest, msgBindURIFailed.In(ctx)), string(kbbi.ErrorCodeInvalidRequest))
 	}
 
 	return nil
@@ -44,9 +86,9 @@ func validateStruct[reqT any](ctx context.Context, req *reqT) error {
 	if err := validate.StructCtx(ctx, req); err != nil {
 		errs, ok := err.(validator.ValidationErrors)
 		if !ok {
-			return httperr.Wrap(err, http.StatusBadRequest, "failed to validate request")
+			return httperr.WithCode(httperr.Wrap(err, http.StatusBadRequest, msgValidateFailed.In(ctx)), string(kbbi.ErrorCodeValidationFailed))
 		}
-		return httperr.Wrap(err, http.StatusBadRequest, errs[0].Translate(validateTranslator))
+		return httperr.WithCode(httperr.Wrap(err, http.StatusBadRequest, errs[0].Translate(validateTranslator(ctx))), string(kbbi.ErrorCodeValidationFailed))
 	}
 	return nil
 }
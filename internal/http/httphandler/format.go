@@ -0,0 +1,236 @@
+package httphandler
+
+import (
+	"io"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin/render"
+	"github.com/raf555/kbbi-api/internal/plaintext"
+	"github.com/ugorji/go/codec"
+)
+
+// Format is the serialization format of the HTTP response body.
+type Format string
+
+const (
+	FormatJSON    Format = "json"
+	FormatXML     Format = "xml"
+	FormatYAML    Format = "yaml"
+	FormatMsgPack Format = "msgpack"
+	FormatCBOR    Format = "cbor"
+	// FormatText is a human-readable plain text, only available for responses which implement [TextRenderer].
+	// Other responses are sent in JSON instead.
+	FormatText Format = "text"
+)
+
+// formats is ordered by preference, used when the client accepts multiple formats with the same quality.
+var formats = []Format{FormatJSON, FormatXML, FormatYAML, FormatMsgPack, FormatCBOR, FormatText}
+
+// formatMediaTypes holds the media types of each format. The first one is the main media type.
+var formatMediaTypes = map[Format][]string{
+	FormatJSON:    {"application/json", "application/problem+json"},
+	FormatXML:     {"application/xml", "text/xml"},
+	FormatYAML:    {"application/yaml", "application/x-yaml", "text/yaml"},
+	FormatMsgPack: {"application/msgpack", "application/x-msgpack", "application/vnd.msgpack"},
+	FormatCBOR:    {"application/cbor"},
+	FormatText:    {"text/plain"},
+}
+
+// NegotiateFormat returns the response format preferred by the client.
+// ok will be false if none of the supported formats is acceptable.
+//
+// The `format` query parameter takes precedence over the Accept header.
+// JSON is used if the client doesn't specify any preference.
+func NegotiateFormat(format, accept string) (Format, bool) {
+	if format != "" {
+		if !slices.Contains(formats, Format(format)) {
+			return "", false
+		}
+		return Format(format), true
+	}
+
+	if strings.TrimSpace(accept) == "" {
+		return FormatJSON, true
+	}
+
+	var (
+		best        Format
+		bestQuality float64
+	)
+
+	for _, accepted := range parseAccept(accept) {
+		// browsers accept html along with xml with higher quality than */*.
+		// Keep them on the default format instead.
+		if accepted.mediaType == "text/html" {
+			return FormatJSON, true
+		}
+
+		if accepted.quality <= bestQuality {
+			continue
+		}
+
+		if format, ok := formatOfMediaType(accepted.mediaType); ok {
+			best, bestQuality = format, accepted.quality
+		}
+	}
+
+	return best, best != ""
+}
+
+// mediaRange is a media range of the Accept header, e.g. `text/*;q=0.5`.
+type mediaRange struct {
+	mediaType string
+	quality   float64
+}
+
+// parseAccept parses the media ranges of the Accept header in order, the invalid ones are skipped.
+func parseAccept(accept string) []mediaRange {
+	var ranges []mediaRange
+	for _, accepted := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(accepted))
+		if err != nil {
+			continue
+		}
+
+		quality := 1.0
+		if q, ok := params["q"]; ok {
+			if quality, err = strconv.ParseFloat(q, 64); err != nil {
+				continue
+			}
+		}
+
+		ranges = append(ranges, mediaRange{mediaType: mediaType, quality: quality})
+	}
+	return ranges
+}
+
+func formatOfMediaType(mediaType string) (Format, bool) {
+	if mediaType == "*/*" {
+		return FormatJSON, true
+	}
+
+	// the media ranges such as `text/*` are only matched with the main media type,
+	// so `text/*` is served as plain text instead of `text/xml`.
+	if typ, ok := strings.CutSuffix(mediaType, "/*"); ok {
+		for _, format := range formats {
+			if strings.HasPrefix(formatMediaTypes[format][0], typ+"/") {
+				return format, true
+			}
+		}
+		return "", false
+	}
+
+	for _, format := range formats {
+		if slices.Contains(formatMediaTypes[format], mediaType) {
+			return format, true
+		}
+	}
+
+	return "", false
+}
+
+// negotiateSerializer returns the serializer of the format preferred by the client.
+// jsonSerializer is used for [FormatJSON].
+//
+// Terminal clients (curl and wget) which accept any format are served with colored [FormatText].
+// The text is also colored if it is requested by the `format` query parameter.
+func negotiateSerializer(ctx *ginCtx, jsonSerializer Serializer) (Format, Serializer, bool) {
+	ctx.Writer.Header().Add("Vary", "Accept")
+
+	formatQuery, accept := ctx.Query("format"), ctx.GetHeader("Accept")
+
+	format, ok := NegotiateFormat(formatQuery, accept)
+	if !ok {
+		return "", nil, false
+	}
+
+	colored := formatQuery == string(FormatText)
+	if formatQuery == "" && acceptsAnyFormat(accept) {
+		// the format is only picked from the user agent if neither the query nor the Accept header picks it.
+		ctx.Writer.Header().Add("Vary", "User-Agent")
+		if isTerminalClient(ctx.GetHeader("User-Agent")) {
+			format, colored = FormatText, true
+		}
+	}
+
+	switch format {
+	case FormatXML:
+		return format, ctx.XML, true
+	case FormatYAML:
+		return format, ctx.YAML, true
+	case FormatMsgPack:
+		return format, func(code int, obj any) { ctx.Render(code, render.MsgPack{Data: obj}) }, true
+	case FormatCBOR:
+		return format, func(code int, obj any) { ctx.Render(code, cborRender{data: obj}) }, true
+	case FormatText:
+		width, _ := strconv.Atoi(ctx.Query("width"))
+		opts := plaintext.Options{Width: width, Color: colored}
+
+		return format, func(code int, obj any) {
+			renderer, ok := obj.(TextRenderer)
+			if !ok {
+				jsonSerializer(code, obj)
+				return
+			}
+			ctx.Render(code, textRender{renderer: renderer, opts: opts})
+		}, true
+	default:
+		return format, jsonSerializer, true
+	}
+}
+
+func acceptsAnyFormat(accept string) bool {
+	accept = strings.TrimSpace(accept)
+	return accept == "" || accept == "*/*"
+}
+
+func isTerminalClient(userAgent string) bool {
+	userAgent = strings.ToLower(userAgent)
+	return strings.HasPrefix(userAgent, "curl/") || strings.HasPrefix(userAgent, "wget/")
+}
+
+var cborContentType = []string{"application/cbor"}
+
+type cborRender struct {
+	data any
+}
+
+func (r cborRender) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+
+	var ch codec.CborHandle
+	return codec.NewEncoder(w, &ch).Encode(r.data)
+}
+
+func (r cborRender) WriteContentType(w http.ResponseWriter) {
+	if header := w.Header(); len(header["Content-Type"]) == 0 {
+		header["Content-Type"] = cborContentType
+	}
+}
+
+// TextRenderer is implemented by responses which can be sent in [FormatText].
+type TextRenderer interface {
+	RenderText(w io.Writer, opts plaintext.Options) error
+}
+
+var textContentType = []string{"text/plain; charset=utf-8"}
+
+type textRender struct {
+	renderer TextRenderer
+	opts     plaintext.Options
+}
+
+func (r textRender) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+	return r.renderer.RenderText(w, r.opts)
+}
+
+func (r textRender) WriteContentType(w http.ResponseWriter) {
+	if header := w.Header(); len(header["Content-Type"]) == 0 {
+		header["Content-Type"] = textContentType
+	}
+}
@@ -0,0 +1,82 @@
+package httphandler_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/raf555/kbbi-api/internal/http/httphandler"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNegotiateFormat(t *testing.T) {
+	tcs := []struct {
+		name     string
+		format   string
+		accept   string
+		expected httphandler.Format
+		ok       bool
+	}{
+		{name: "default", expected: httphandler.FormatJSON, ok: true},
+		{name: "any", accept: "*/*", expected: httphandler.FormatJSON, ok: true},
+		{name: "xml", accept: "application/xml", expected: httphandler.FormatXML, ok: true},
+		{name: "yaml alias", accept: "application/x-yaml", expected: httphandler.FormatYAML, ok: true},
+		{name: "highest quality wins", accept: "application/json;q=0.5, application/cbor", expected: httphandler.FormatCBOR, ok: true},
+		{name: "skip unsupported", accept: "text/csv, application/msgpack;q=0.1", expected: httphandler.FormatMsgPack, ok: true},
+		{name: "problem json", accept: "application/problem+json", expected: httphandler.FormatJSON, ok: true},
+		{name: "browser", accept: "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", expected: httphandler.FormatJSON, ok: true},
+		{name: "format overrides accept", format: "yaml", accept: "application/xml", expected: httphandler.FormatYAML, ok: true},
+		{name: "text", accept: "text/plain", expected: httphandler.FormatText, ok: true},
+		{name: "text range", accept: "text/*", expected: httphandler.FormatText, ok: true},
+		{name: "application range", accept: "application/*", expected: httphandler.FormatJSON, ok: true},
+		{name: "range with lower quality", accept: "text/*;q=0.5, application/cbor", expected: httphandler.FormatCBOR, ok: true},
+		{name: "unsupported accept", accept: "text/csv", ok: false},
+		{name: "unsupported range", accept: "image/*", ok: false},
+		{name: "unsupported format", format: "toml", ok: false},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			format, ok := httphandler.NegotiateFormat(tc.format, tc.accept)
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.expected, format)
+		})
+	}
+}
+
+func TestNegotiateFormat_Vary(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.GET("/", httphandler.MakeSimpleHandler(func(context.Context) (*struct{}, error) {
+		return &struct{}{}, nil
+	}))
+
+	tcs := []struct {
+		name      string
+		query     string
+		accept    string
+		userAgent string
+		expected  []string
+	}{
+		{name: "default", expected: []string{"Accept", "User-Agent"}},
+		{name: "any", accept: "*/*", userAgent: "Mozilla/5.0", expected: []string{"Accept", "User-Agent"}},
+		{name: "terminal client", accept: "*/*", userAgent: "curl/8.5.0", expected: []string{"Accept", "User-Agent"}},
+		{name: "accept", accept: "application/xml", userAgent: "curl/8.5.0", expected: []string{"Accept"}},
+		{name: "format", query: "?format=json", userAgent: "curl/8.5.0", expected: []string{"Accept"}},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/"+tc.query, nil)
+			req.Header.Set("Accept", tc.accept)
+			req.Header.Set("User-Agent", tc.userAgent)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expected, rec.Header().Values("Vary"))
+		})
+	}
+}
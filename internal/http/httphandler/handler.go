@@ -9,8 +9,14 @@ import (
 	"github.com/raf555/kbbi-api/internal/http/httperr"
 	"github.com/raf555/kbbi-api/internal/http/httpres"
 	"github.com/raf555/kbbi-api/internal/logger"
+	"github.com/raf555/kbbi-api/pkg/kbbi"
 )
 
+var msgNotAcceptable = Messages{
+	LocaleEnglish:    "none of the accepted formats is supported, supported formats are json, xml, yaml, msgpack, cbor, and text",
+	LocaleIndonesian: "tidak ada format yang didukung, format yang didukung adalah json, xml, yaml, msgpack, cbor, dan text",
+}
+
 // Handler is a simple HTTP request handler which accepts request and response.
 type Handler[req, res any] = func(context.Context, *req) (*res, error)
 
@@ -22,6 +28,9 @@ type Handler[req, res any] = func(context.Context, *req) (*res, error)
 //
 // Both implementation of handler is encouraged to use errors from package [httperr] to wrap the error to make the best result.
 // Otherwise, this handler will always return 5xx error.
+//
+// The response is serialized in the format negotiated by [NegotiateFormat], or 406 if none of the formats is acceptable.
+// Errors are sent as [httpres.Error] by default, or as [httpres.Problem] if the client accepts application/problem+json.
 func MakeHandler[reqT, resT any](
 	handler Handler[reqT, resT],
 	requestBinder RequestBinder[reqT],
@@ -55,20 +64,21 @@ func MakeSimpleHandler[res any](handler SimpleHandler[res]) gin.HandlerFunc {
 func sendResponse[resT any](ctx *ginCtx, res *resT, err error, opts ...handlerOption) {
 	options := resolveOptions(ctx, opts...)
 
+	format, serializer, ok := negotiateSerializer(ctx, options.serializer)
+	if !ok {
+		// the client doesn't accept any supported format, so the error is sent in JSON anyway.
+		format, serializer = FormatJSON, options.serializer
+		res, err = nil, httperr.WithCode(
+			httperr.New(http.StatusNotAcceptable, msgNotAcceptable.In(ctx)),
+			string(kbbi.ErrorCodeNotAcceptable),
+		)
+	}
+
 	statusCode := httperr.HTTPStatusCode(err)
-	statusCodeMessage := http.StatusText(statusCode)
 
 	if err != nil {
 		logger.FromContext(ctx).ErrorContext(ctx, "HTTP error occurred", logger.Error(err), slog.Int("code", statusCode))
-
-		innerErrMsg := statusCodeMessage
-
-		errMsg, ok := httperr.HTTPResponseMessage(err)
-		if ok {
-			innerErrMsg = errMsg
-		}
-
-		options.serializer(statusCode, &httpres.Error{Message: innerErrMsg})
+		sendError(ctx, format, serializer, err)
 		return
 	}
 
@@ -83,7 +93,43 @@ func sendResponse[resT any](ctx *ginCtx, res *resT, err error, opts ...handlerOp
 		}
 	}
 
-	options.serializer(statusCode, res)
+	serializer(statusCode, res)
+}
+
+// SendError sends err in the format negotiated with the client, the same way as the errors of [MakeHandler].
+// It is meant for the responses outside of the handlers, e.g. unknown routes and panics. err is not logged.
+func SendError(gCtx *gin.Context, err error) {
+	ctx := &ginCtx{gCtx}
+
+	format, serializer, ok := negotiateSerializer(ctx, ctx.JSON)
+	if !ok {
+		format, serializer = FormatJSON, ctx.JSON
+	}
+
+	sendError(ctx, format, serializer, err)
+}
+
+// sendError sends err as [httpres.Problem] if the client accepts it, or as [httpres.Error] otherwise.
+func sendError(ctx *ginCtx, format Format, serializer Serializer, err error) {
+	statusCode := httperr.HTTPStatusCode(err)
+
+	message, ok := httperr.HTTPResponseMessage(err)
+	if !ok {
+		message = StatusText(ctx, statusCode)
+	}
+
+	if format == FormatJSON && acceptsProblem(ctx) {
+		// the serializer only sets the content type if it's not set yet.
+		ctx.Header("Content-Type", httpres.ProblemContentType)
+		serializer(statusCode, newProblem(ctx, err, statusCode, message))
+		return
+	}
+
+	serializer(statusCode, &httpres.Error{
+		Code:        httperr.HTTPErrorCode(err),
+		Message:     message,
+		Suggestions: httperr.HTTPResponseSuggestions(err),
+	})
 }
 
 type (
@@ -0,0 +1,108 @@
+package httphandler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/text/language"
+)
+
+// Locale is the language used for the messages in the HTTP response.
+type Locale string
+
+const (
+	LocaleEnglish    Locale = "en"
+	LocaleIndonesian Locale = "id"
+)
+
+// DefaultLocale is used when the client doesn't specify any supported locale.
+const DefaultLocale = LocaleEnglish
+
+var (
+	// supportedLocales is ordered the same as localeMatcher tags.
+	supportedLocales = []Locale{LocaleEnglish, LocaleIndonesian}
+	localeMatcher    = language.NewMatcher([]language.Tag{language.English, language.Indonesian})
+)
+
+// NegotiateLocale returns the supported locale preferred by the client.
+//
+// The `lang` query parameter takes precedence over the Accept-Language header.
+func NegotiateLocale(lang, acceptLanguage string) Locale {
+	for _, locale := range supportedLocales {
+		if Locale(lang) == locale {
+			return locale
+		}
+	}
+
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return DefaultLocale
+	}
+
+	_, idx, confidence := localeMatcher.Match(tags...)
+	if confidence == language.No {
+		return DefaultLocale
+	}
+
+	return supportedLocales[idx]
+}
+
+// LocaleMiddleware negotiates the locale of the request and stores it in the request context.
+// The locale can be retrieved by [LocaleFromContext].
+func LocaleMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		locale := NegotiateLocale(ctx.Query("lang"), ctx.GetHeader("Accept-Language"))
+
+		ctx.Request = ctx.Request.WithContext(WithLocale(ctx.Request.Context(), locale))
+		ctx.Header("Content-Language", string(locale))
+		// other middlewares may vary the response as well.
+		ctx.Writer.Header().Add("Vary", "Accept-Language")
+
+		ctx.Next()
+	}
+}
+
+type localeCtxKey struct{}
+
+func WithLocale(ctx context.Context, locale Locale) context.Context {
+	return context.WithValue(ctx, localeCtxKey{}, locale)
+}
+
+// LocaleFromContext returns the locale stored in ctx, or [DefaultLocale] if there is none.
+func LocaleFromContext(ctx context.Context) Locale {
+	if locale, ok := ctx.Value(localeCtxKey{}).(Locale); ok {
+		return locale
+	}
+	return DefaultLocale
+}
+
+// Messages holds the same message in different locales.
+type Messages map[Locale]string
+
+// In returns the message in the locale of ctx, falling back to the [DefaultLocale] message.
+func (m Messages) In(ctx context.Context) string {
+	if msg, ok := m[LocaleFromContext(ctx)]; ok {
+		return msg
+	}
+	return m[DefaultLocale]
+}
+
+var indonesianStatusTexts = map[int]string{
+	http.StatusBadRequest:          "Permintaan Tidak Valid",
+	http.StatusNotFound:            "Tidak Ditemukan",
+	http.StatusMethodNotAllowed:    "Metode Tidak Diizinkan",
+	http.StatusNotAcceptable:       "Tidak Dapat Diterima",
+	http.StatusRequestURITooLong:   "URI Permintaan Terlalu Panjang",
+	http.StatusInternalServerError: "Kesalahan Server Internal",
+}
+
+// StatusText is the same as [http.StatusText], but uses the locale of ctx if available.
+func StatusText(ctx context.Context, code int) string {
+	if LocaleFromContext(ctx) == LocaleIndonesian {
+		if text, ok := indonesianStatusTexts[code]; ok {
+			return text
+		}
+	}
+	return http.StatusText(code)
+}
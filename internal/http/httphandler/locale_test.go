@@ -0,0 +1,52 @@
+package httphandler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/raf555/kbbi-api/internal/http/httphandler"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNegotiateLocale(t *testing.T) {
+	tcs := []struct {
+		name           string
+		lang           string
+		acceptLanguage string
+		expected       httphandler.Locale
+	}{
+		{name: "default", expected: httphandler.LocaleEnglish},
+		{name: "accept language", acceptLanguage: "id-ID,id;q=0.9,en;q=0.8", expected: httphandler.LocaleIndonesian},
+		{name: "accept language with quality", acceptLanguage: "en;q=0.5,id;q=0.9", expected: httphandler.LocaleIndonesian},
+		{name: "unsupported accept language", acceptLanguage: "fr-FR", expected: httphandler.LocaleEnglish},
+		{name: "invalid accept language", acceptLanguage: ";;;", expected: httphandler.LocaleEnglish},
+		{name: "lang overrides accept language", lang: "en", acceptLanguage: "id", expected: httphandler.LocaleEnglish},
+		{name: "unsupported lang is ignored", lang: "fr", acceptLanguage: "id", expected: httphandler.LocaleIndonesian},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, httphandler.NegotiateLocale(tc.lang, tc.acceptLanguage))
+		})
+	}
+}
+
+func TestLocaleMiddleware_Vary(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(func(ctx *gin.Context) {
+		ctx.Writer.Header().Add("Vary", "Origin")
+	})
+	router.Use(httphandler.LocaleMiddleware())
+	router.GET("/", func(ctx *gin.Context) {
+		ctx.Status(http.StatusNoContent)
+	})
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, []string{"Origin", "Accept-Language"}, rec.Header().Values("Vary"))
+}
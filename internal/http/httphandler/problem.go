@@ -0,0 +1,63 @@
+package httphandler
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/raf555/kbbi-api/internal/http/httperr"
+	"github.com/raf555/kbbi-api/internal/http/httpres"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+const problemTypePrefix = "urn:kbbi-api:problem:"
+
+// acceptsProblem reports whether the client prefers problem details over the legacy error response.
+// The legacy error response is used unless application/problem+json is explicitly accepted
+// with higher quality than the other media ranges of [FormatJSON], e.g. application/json or */*.
+func acceptsProblem(ctx *ginCtx) bool {
+	var problemQuality, jsonQuality float64
+	for _, accepted := range parseAccept(ctx.GetHeader("Accept")) {
+		if accepted.mediaType == httpres.ProblemContentType {
+			problemQuality = max(problemQuality, accepted.quality)
+			continue
+		}
+
+		if format, ok := formatOfMediaType(accepted.mediaType); ok && format == FormatJSON {
+			jsonQuality = max(jsonQuality, accepted.quality)
+		}
+	}
+
+	return problemQuality > jsonQuality
+}
+
+func newProblem(ctx *ginCtx, err error, statusCode int, message string) *httpres.Problem {
+	errCode := httperr.HTTPErrorCode(err)
+
+	problem := &httpres.Problem{
+		Type:        problemTypePrefix + strings.ReplaceAll(strings.ToLower(errCode), "_", "-"),
+		Title:       StatusText(ctx, statusCode),
+		Status:      statusCode,
+		Detail:      message,
+		Instance:    ctx.Request().URL.Path,
+		Code:        errCode,
+		Suggestions: httperr.HTTPResponseSuggestions(err),
+	}
+
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		for _, fieldErr := range validationErrs {
+			problem.Errors = append(problem.Errors, httpres.FieldError{
+				Field:   fieldErr.Field(),
+				Rule:    fieldErr.Tag(),
+				Message: fieldErr.Translate(validateTranslator(ctx)),
+			})
+		}
+	}
+
+	if spanCtx := oteltrace.SpanContextFromContext(ctx.Request().Context()); spanCtx.HasTraceID() {
+		problem.TraceID = spanCtx.TraceID().String()
+	}
+
+	return problem
+}
@@ -0,0 +1,149 @@
+package httphandler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/raf555/kbbi-api/internal/http/httperr"
+	"github.com/raf555/kbbi-api/internal/http/httphandler"
+	"github.com/raf555/kbbi-api/internal/http/httpres"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProblemNegotiation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(httphandler.LocaleMiddleware())
+	router.GET("/lemma", httphandler.MakeSimpleHandler(func(context.Context) (*struct{}, error) {
+		return nil, httperr.WithSuggestions(httperr.New(http.StatusNotFound, "lemma not found"), []string{"apel"})
+	}))
+	router.NoRoute(func(ctx *gin.Context) {
+		httphandler.SendError(ctx, httperr.New(http.StatusNotFound, ""))
+	})
+
+	tcs := []struct {
+		name   string
+		path   string
+		accept string
+
+		expectedContentType string
+		expectedProblem     *httpres.Problem
+		expectedError       *httpres.Error
+	}{
+		{
+			name:                "default",
+			path:                "/lemma",
+			expectedContentType: "application/json; charset=utf-8",
+			expectedError:       &httpres.Error{Code: "NOT_FOUND", Message: "lemma not found", Suggestions: []string{"apel"}},
+		},
+		{
+			name:                "json",
+			path:                "/lemma",
+			accept:              "application/json",
+			expectedContentType: "application/json; charset=utf-8",
+			expectedError:       &httpres.Error{Code: "NOT_FOUND", Message: "lemma not found", Suggestions: []string{"apel"}},
+		},
+		{
+			name:                "problem",
+			path:                "/lemma",
+			accept:              "application/problem+json",
+			expectedContentType: httpres.ProblemContentType,
+			expectedProblem: &httpres.Problem{
+				Type:        "urn:kbbi-api:problem:not-found",
+				Title:       "Not Found",
+				Status:      http.StatusNotFound,
+				Detail:      "lemma not found",
+				Instance:    "/lemma",
+				Code:        "NOT_FOUND",
+				Suggestions: []string{"apel"},
+			},
+		},
+		{
+			name:                "json preferred over problem",
+			path:                "/lemma",
+			accept:              "application/json, application/problem+json;q=0.5",
+			expectedContentType: "application/json; charset=utf-8",
+			expectedError:       &httpres.Error{Code: "NOT_FOUND", Message: "lemma not found", Suggestions: []string{"apel"}},
+		},
+		{
+			name:                "problem with lower quality",
+			path:                "/lemma",
+			accept:              "application/problem+json;q=0.1, application/json",
+			expectedContentType: "application/json; charset=utf-8",
+			expectedError:       &httpres.Error{Code: "NOT_FOUND", Message: "lemma not found", Suggestions: []string{"apel"}},
+		},
+		{
+			name:                "problem preferred over any",
+			path:                "/lemma",
+			accept:              "application/problem+json, */*;q=0.8",
+			expectedContentType: httpres.ProblemContentType,
+			expectedProblem: &httpres.Problem{
+				Type:        "urn:kbbi-api:problem:not-found",
+				Title:       "Not Found",
+				Status:      http.StatusNotFound,
+				Detail:      "lemma not found",
+				Instance:    "/lemma",
+				Code:        "NOT_FOUND",
+				Suggestions: []string{"apel"},
+			},
+		},
+		{
+			name:                "unknown route",
+			path:                "/unknown",
+			expectedContentType: "application/json; charset=utf-8",
+			expectedError:       &httpres.Error{Code: "NOT_FOUND", Message: "Not Found"},
+		},
+		{
+			name:                "unknown route with problem",
+			path:                "/unknown",
+			accept:              "application/problem+json",
+			expectedContentType: httpres.ProblemContentType,
+			expectedProblem: &httpres.Problem{
+				Type:     "urn:kbbi-api:problem:not-found",
+				Title:    "Not Found",
+				Status:   http.StatusNotFound,
+				Detail:   "Not Found",
+				Instance: "/unknown",
+				Code:     "NOT_FOUND",
+			},
+		},
+		{
+			name:                "unknown route with unsupported format",
+			path:                "/unknown",
+			accept:              "text/csv",
+			expectedContentType: "application/json; charset=utf-8",
+			expectedError:       &httpres.Error{Code: "NOT_FOUND", Message: "Not Found"},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			if tc.accept != "" {
+				req.Header.Set("Accept", tc.accept)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusNotFound, rec.Code)
+			assert.Equal(t, tc.expectedContentType, rec.Header().Get("Content-Type"))
+
+			if tc.expectedProblem != nil {
+				var problem httpres.Problem
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &problem))
+				assert.Equal(t, *tc.expectedProblem, problem)
+				return
+			}
+
+			var res httpres.Error
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+			assert.Equal(t, *tc.expectedError, res)
+		})
+	}
+}
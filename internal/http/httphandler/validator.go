@@ -1,31 +1,44 @@
 package httphandler
 
 import (
+	"context"
 	"errors"
 
 	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/id"
 	ut "github.com/go-playground/universal-translator"
 	"github.com/go-playground/validator/v10"
 	en_translations "github.com/go-playground/validator/v10/translations/en"
+	id_translations "github.com/go-playground/validator/v10/translations/id"
 )
 
 var (
-	validate           = validator.New()
-	validateTranslator = getValidatorTranslator()
+	validate            = validator.New()
+	validateTranslators = getValidatorTranslators()
 )
 
 func init() {
-	_ = en_translations.RegisterDefaultTranslations(validate, validateTranslator)
+	_ = en_translations.RegisterDefaultTranslations(validate, validateTranslators[LocaleEnglish])
+	_ = id_translations.RegisterDefaultTranslations(validate, validateTranslators[LocaleIndonesian])
 }
 
-func getValidatorTranslator() ut.Translator {
+func getValidatorTranslators() map[Locale]ut.Translator {
 	en := en.New()
-	uni := ut.New(en, en)
+	uni := ut.New(en, en, id.New())
 
-	validateTranslator, ok := uni.GetTranslator("en")
-	if !ok {
-		panic(errors.New("httphandler: validator translator not found"))
+	translators := make(map[Locale]ut.Translator, len(supportedLocales))
+	for _, locale := range supportedLocales {
+		translator, ok := uni.GetTranslator(string(locale))
+		if !ok {
+			panic(errors.New("httphandler: validator translator not found: " + string(locale)))
+		}
+		translators[locale] = translator
 	}
 
-	return validateTranslator
+	return translators
+}
+
+// validateTranslator returns the validator translator of the locale in ctx.
+func validateTranslator(ctx context.Context) ut.Translator {
+	return validateTranslators[LocaleFromContext(ctx)]
 }
@@ -1,5 +1,21 @@
 package httpres
 
+import (
+	"io"
+
+	"github.com/raf555/kbbi-api/internal/plaintext"
+)
+
 type Error struct {
-	Message string `json:"message"`
+	// Code is a stable machine-readable code of the error. See [kbbi.ErrorCode] for the list of codes.
+	Code string `json:"code" xml:"code" example:"LEMMA_NOT_FOUND" enums:"LEMMA_NOT_FOUND,ENTRY_NOT_FOUND,LEMMA_TOO_LONG,EMPTY_LEMMA,INVALID_ENTRY_NUMBER,VALIDATION_FAILED,INVALID_REQUEST,UNAUTHORIZED,RELOAD_IN_PROGRESS,RELOAD_FAILED,BAD_REQUEST,NOT_FOUND,METHOD_NOT_ALLOWED,NOT_ACCEPTABLE,REQUEST_URI_TOO_LONG,INTERNAL_SERVER_ERROR"`
+
+	Message string `json:"message" xml:"message"`
+
+	// Suggestions is only present on some errors, e.g. similar lemmas when a lemma is not found.
+	Suggestions []string `json:"suggestions,omitempty" xml:"suggestions>suggestion,omitempty"`
+}
+
+func (e *Error) RenderText(w io.Writer, opts plaintext.Options) error {
+	return plaintext.RenderError(w, e.Message, e.Suggestions, opts)
 }
@@ -0,0 +1,31 @@
+package httpres
+
+// ProblemContentType is the media type of [Problem] as defined in RFC 9457.
+const ProblemContentType = "application/problem+json"
+
+// Problem is the problem details of an error as defined in RFC 9457.
+// It is only sent when the client accepts [ProblemContentType], otherwise [Error] is sent.
+type Problem struct {
+	Type     string `json:"type" xml:"type" example:"urn:kbbi-api:problem:lemma-not-found"`
+	Title    string `json:"title" xml:"title" example:"Not Found"`
+	Status   int    `json:"status" xml:"status" example:"404"`
+	Detail   string `json:"detail" xml:"detail" example:"lemma not found"`
+	Instance string `json:"instance" xml:"instance" example:"/api/v1/entry/apell"`
+
+	// extension members.
+
+	// Code is the same as [Error.Code].
+	Code string `json:"code" xml:"code" example:"LEMMA_NOT_FOUND" enums:"LEMMA_NOT_FOUND,ENTRY_NOT_FOUND,LEMMA_TOO_LONG,EMPTY_LEMMA,INVALID_ENTRY_NUMBER,VALIDATION_FAILED,INVALID_REQUEST,UNAUTHORIZED,RELOAD_IN_PROGRESS,RELOAD_FAILED,BAD_REQUEST,NOT_FOUND,METHOD_NOT_ALLOWED,NOT_ACCEPTABLE,REQUEST_URI_TOO_LONG,INTERNAL_SERVER_ERROR"`
+	// Suggestions is the same as [Error.Suggestions].
+	Suggestions []string `json:"suggestions,omitempty" xml:"suggestions>suggestion,omitempty"`
+	// Errors is only present on validation errors, one for each invalid field.
+	Errors []FieldError `json:"errors,omitempty" xml:"errors>error,omitempty"`
+	// TraceID is the trace ID of the request if it is traced.
+	TraceID string `json:"traceId,omitempty" xml:"traceId,omitempty" example:"4bf92f3577b34da6a3ce929d0e0e4736"`
+}
+
+type FieldError struct {
+	Field   string `json:"field" xml:"field" example:"OnNonStandard"`
+	Rule    string `json:"rule" xml:"rule" example:"oneof"`
+	Message string `json:"message" xml:"message" example:"OnNonStandard must be one of [redirect annotate strict]"`
+}
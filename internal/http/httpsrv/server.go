@@ -6,7 +6,8 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/raf555/kbbi-api/internal/config"
-	"github.com/raf555/kbbi-api/internal/http/httpres"
+	"github.com/raf555/kbbi-api/internal/http/httperr"
+	"github.com/raf555/kbbi-api/internal/http/httphandler"
 	sloggin "github.com/samber/slog-gin"
 	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 	"go.uber.org/fx"
@@ -68,17 +69,19 @@ func registerMiddlewares(cfg config.ServerConfig, router *gin.Engine, logger *sl
 		WithUserAgent: true,
 	}))
 
+	router.Use(httphandler.LocaleMiddleware())
+
 	router.Use(gin.CustomRecovery(func(ctx *gin.Context, err any) {
 		logger.ErrorContext(ctx, "Panic occurred", slog.Any("panic", err))
 
-		ctx.JSON(http.StatusInternalServerError, httpres.Error{Message: http.StatusText(http.StatusInternalServerError)})
+		httphandler.SendError(ctx, httperr.New(http.StatusInternalServerError, ""))
 	}))
 
 	router.NoMethod(func(ctx *gin.Context) {
-		ctx.JSON(http.StatusMethodNotAllowed, httpres.Error{Message: http.StatusText(http.StatusMethodNotAllowed)})
+		httphandler.SendError(ctx, httperr.New(http.StatusMethodNotAllowed, ""))
 	})
 
 	router.NoRoute(func(ctx *gin.Context) {
-		ctx.JSON(http.StatusNotFound, httpres.Error{Message: http.StatusText(http.StatusNotFound)})
+		httphandler.SendError(ctx, httperr.New(http.StatusNotFound, ""))
 	})
 }
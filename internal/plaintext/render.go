@@ -0,0 +1,164 @@
+package plaintext
+
+import (
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/raf555/kbbi-api/pkg/kbbi"
+)
+
+// RenderLemma renders the lemma similar to the printed KBBI.
+//
+// Each entry is shown with its syllables and homonym number (e.g. a·pel¹), followed by its numbered definitions.
+// Labels are shown with their abbreviations (e.g. n, v, cak) and examples follow their definition after a colon.
+func RenderLemma(w io.Writer, lemma kbbi.Lemma, opts Options) error {
+	pw := newWriter(opts)
+
+	for i, entry := range lemma.Entries {
+		if i > 0 {
+			pw.newline()
+		}
+		renderEntry(pw, entry)
+	}
+
+	return write(w, pw)
+}
+
+func renderEntry(pw *writer, entry kbbi.Entry) {
+	heading := []segment{styled(styleBold, headword(entry.Entry))}
+	if entry.Pronunciation != "" {
+		heading = append(heading, styled(styleDim, "/"+entry.Pronunciation+"/"))
+	}
+	pw.paragraph(plain(""), 0, heading...)
+
+	for i, def := range entry.Definitions {
+		prefix := "  "
+		if len(entry.Definitions) > 1 {
+			prefix += strconv.Itoa(i+1) + " "
+		}
+		pw.paragraph(styled(styleBold, prefix), len([]rune(prefix)), definition(def)...)
+	}
+
+	relatedWords := []struct {
+		title string
+		words []string
+	}{
+		{"Varian", entry.EntryVariants},
+		{"Bentuk tidak baku", entry.NonStandardWords},
+		{"Kata dasar", nonEmpty(entry.BaseWord)},
+		{"Kata turunan", entry.DerivedWords},
+		{"Gabungan kata", entry.CompoundWords},
+		{"Peribahasa", entry.Proverbs},
+		{"Kiasan", entry.Metaphors},
+	}
+
+	for _, related := range relatedWords {
+		if len(related.words) == 0 {
+			continue
+		}
+
+		prefix := "  " + related.title + ":"
+		pw.paragraph(styled(styleBold, prefix), 4, styled(styleReference, strings.Join(related.words, "; ")))
+	}
+}
+
+func definition(def kbbi.EntryDefinition) []segment {
+	var segments []segment
+	for _, label := range def.Labels {
+		segments = append(segments, styled(styleLabel, label.Code))
+	}
+
+	text := def.Definition
+	if len(def.UsageExamples) > 0 {
+		text += ":"
+	}
+	segments = append(segments, plain(text))
+
+	if def.ReferencedLemma != "" {
+		segments = append(segments, plain("→"), styled(styleReference, def.ReferencedLemma))
+	}
+
+	for i, example := range def.UsageExamples {
+		if i < len(def.UsageExamples)-1 {
+			example += ";"
+		}
+		segments = append(segments, styled(styleItalic, example))
+	}
+
+	return segments
+}
+
+// RenderNote renders an annotation of the rendered result, e.g. the standard form of the requested lemma.
+func RenderNote(w io.Writer, note string, opts Options) error {
+	pw := newWriter(opts)
+	pw.paragraph(styled(styleDim, "※"), 2, styled(styleDim, note))
+	return write(w, pw)
+}
+
+// RenderList renders each item in its own line.
+func RenderList(w io.Writer, items []string, opts Options) error {
+	pw := newWriter(opts)
+	for _, item := range items {
+		pw.paragraph(plain(""), 2, plain(item))
+	}
+	return write(w, pw)
+}
+
+// RenderError renders the error message along with its suggestions if any.
+func RenderError(w io.Writer, message string, suggestions []string, opts Options) error {
+	pw := newWriter(opts)
+	pw.paragraph(styled(styleLabel, "galat:"), 2, plain(message))
+	if len(suggestions) > 0 {
+		pw.paragraph(styled(styleBold, "Mungkin yang Anda maksud:"), 2, styled(styleReference, strings.Join(suggestions, "; ")))
+	}
+	return write(w, pw)
+}
+
+func write(w io.Writer, pw *writer) error {
+	_, err := io.WriteString(w, pw.String())
+	return err
+}
+
+var superscriptDigits = []rune("⁰¹²³⁴⁵⁶⁷⁸⁹")
+
+// headword returns the syllabified entry with its homonym number as superscript. E.g. `a.pel (1)` becomes `a·pel¹`.
+func headword(entry string) string {
+	entry, entryNo := splitHomonym(entry)
+
+	entry = strings.ReplaceAll(entry, ".", "·")
+	if entryNo == 0 {
+		return entry
+	}
+
+	var sb strings.Builder
+	sb.WriteString(entry)
+	for _, digit := range strconv.Itoa(entryNo) {
+		sb.WriteRune(superscriptDigits[digit-'0'])
+	}
+	return sb.String()
+}
+
+// splitHomonym splits the homonym number from the entry. E.g. `a.pel (1)` returns (`a.pel`, 1).
+// It is a simpler version of dictionary.FindEntryNoFromLemma since this package can't depend on dictionary.
+func splitHomonym(entry string) (string, int) {
+	openIdx := strings.LastIndex(entry, " (")
+	if openIdx < 0 || !strings.HasSuffix(entry, ")") {
+		return entry, 0
+	}
+
+	entryNo, err := strconv.Atoi(entry[openIdx+2 : len(entry)-1])
+	if err != nil || entryNo <= 0 {
+		return entry, 0
+	}
+
+	return entry[:openIdx], entryNo
+}
+
+// nonEmpty returns s as a single element slice, or nil if s is empty.
+func nonEmpty(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return []string{s}
+}
@@ -0,0 +1,91 @@
+package plaintext_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/raf555/kbbi-api/internal/plaintext"
+	"github.com/raf555/kbbi-api/pkg/kbbi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderLemma(t *testing.T) {
+	lemma := kbbi.Lemma{
+		Lemma: "apel",
+		Entries: []kbbi.Entry{
+			{
+				Entry:         "a.pel (1)",
+				Pronunciation: "apêl",
+				Definitions: []kbbi.EntryDefinition{
+					{
+						Definition:    "pohon yang buahnya berbentuk bulat dan berkulit licin",
+						Labels:        []kbbi.EntryLabel{{Code: "n", Name: "Nomina", Kind: "Kelas Kata"}},
+						UsageExamples: []string{"apel merah", "apel hijau"},
+					},
+					{
+						Labels:          []kbbi.EntryLabel{{Code: "cak", Name: "cakapan", Kind: "Ragam"}},
+						ReferencedLemma: "epal",
+					},
+				},
+				CompoundWords: []string{"apel malang", "apel tegal"},
+			},
+			{
+				Entry:       "a.pel (2)",
+				Definitions: []kbbi.EntryDefinition{{Definition: "upacara", Labels: []kbbi.EntryLabel{{Code: "n"}}}},
+			},
+		},
+	}
+
+	tcs := []struct {
+		name     string
+		opts     plaintext.Options
+		expected string
+	}{
+		{
+			name: "default width",
+			expected: `a·pel¹ /apêl/
+  1 n pohon yang buahnya berbentuk bulat dan berkulit licin: apel merah; apel
+    hijau
+  2 cak → epal
+  Gabungan kata: apel malang; apel tegal
+
+a·pel²
+  n upacara
+`,
+		},
+		{
+			name: "wrapped",
+			opts: plaintext.Options{Width: 30},
+			expected: `a·pel¹ /apêl/
+  1 n pohon yang buahnya
+    berbentuk bulat dan
+    berkulit licin: apel
+    merah; apel hijau
+  2 cak → epal
+  Gabungan kata: apel malang;
+    apel tegal
+
+a·pel²
+  n upacara
+`,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			var sb strings.Builder
+			require.NoError(t, plaintext.RenderLemma(&sb, lemma, tc.opts))
+			assert.Equal(t, tc.expected, sb.String())
+		})
+	}
+}
+
+func TestRenderLemma_Color(t *testing.T) {
+	var sb strings.Builder
+	require.NoError(t, plaintext.RenderLemma(&sb, kbbi.Lemma{
+		Entries: []kbbi.Entry{{Entry: "apel", Definitions: []kbbi.EntryDefinition{{Definition: "buah", Labels: []kbbi.EntryLabel{{Code: "n"}}}}}},
+	}, plaintext.Options{Color: true}))
+
+	assert.Equal(t, "\x1b[1mapel\x1b[0m\n  \x1b[3;31mn\x1b[0m buah\n", sb.String())
+}
@@ -0,0 +1,44 @@
+package plaintext
+
+import "strings"
+
+// style is an ANSI SGR escape sequence.
+type style string
+
+const (
+	styleNone      style = ""
+	styleBold      style = "\x1b[1m"
+	styleDim       style = "\x1b[2m"
+	styleItalic    style = "\x1b[3m"
+	styleLabel     style = "\x1b[3;31m" // italic red, similar to the labels in the printed KBBI.
+	styleReference style = "\x1b[36m"
+	styleReset     style = "\x1b[0m"
+)
+
+// Options configures the plain text rendering.
+type Options struct {
+	// Width is the maximum line width, 0 means [DefaultWidth].
+	Width int
+	// Color enables the ANSI colors and text styles.
+	Color bool
+}
+
+const (
+	DefaultWidth = 80
+	MinWidth     = 20
+	MaxWidth     = 500
+)
+
+func (o Options) width() int {
+	if o.Width <= 0 {
+		return DefaultWidth
+	}
+	return min(max(o.Width, MinWidth), MaxWidth)
+}
+
+func (o Options) styled(s style, text string) string {
+	if !o.Color || s == styleNone || strings.TrimSpace(text) == "" {
+		return text
+	}
+	return string(s) + text + string(styleReset)
+}
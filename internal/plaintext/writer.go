@@ -0,0 +1,77 @@
+package plaintext
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// segment is a text with the same style, which can be wrapped on its spaces.
+type segment struct {
+	text  string
+	style style
+}
+
+func plain(text string) segment {
+	return segment{text: text}
+}
+
+func styled(s style, text string) segment {
+	return segment{text: text, style: s}
+}
+
+// writer builds the rendered text with the words wrapped to the width of the options.
+type writer struct {
+	sb   strings.Builder
+	opts Options
+}
+
+func newWriter(opts Options) *writer {
+	return &writer{opts: opts}
+}
+
+func (w *writer) String() string {
+	return w.sb.String()
+}
+
+func (w *writer) newline() {
+	w.sb.WriteByte('\n')
+}
+
+// paragraph writes the segments, wrapping the words so that each line fits the width.
+// The first line is started with prefix, and the next lines are indented with indent spaces.
+func (w *writer) paragraph(prefix segment, indent int, segments ...segment) {
+	width := w.opts.width()
+
+	w.sb.WriteString(w.opts.styled(prefix.style, prefix.text))
+	col := utf8.RuneCountInString(prefix.text)
+	lineStart := true
+
+	for _, seg := range segments {
+		for _, word := range strings.Fields(seg.text) {
+			wordLen := utf8.RuneCountInString(word)
+
+			switch {
+			case lineStart && col == 0:
+			case lineStart:
+				// prefix is already written, only separate it with a space if it doesn't end with one.
+				if !strings.HasSuffix(prefix.text, " ") && prefix.text != "" {
+					w.sb.WriteByte(' ')
+					col++
+				}
+			case col+1+wordLen > width:
+				w.newline()
+				w.sb.WriteString(strings.Repeat(" ", indent))
+				col = indent
+			default:
+				w.sb.WriteByte(' ')
+				col++
+			}
+
+			w.sb.WriteString(w.opts.styled(seg.style, word))
+			col += wordLen
+			lineStart = false
+		}
+	}
+
+	w.newline()
+}
@@ -40,15 +40,26 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/httpres.Error"
                         }
+                    },
+                    "default": {
+                        "description": "Sent instead of httpres.Error if the client accepts application/problem+json.",
+                        "schema": {
+                            "$ref": "#/definitions/httpres.Problem"
+                        }
                     }
                 }
             }
         },
         "/api/v1/entry/_search": {
             "get": {
-                "description": "Suggest a list of lemmas based on keyword. Search is done similarly with the application.",
+                "description": "Suggest a list of lemmas based on keyword. Search is done similarly with the application.\nLemmas which have entry variants matching the keyword are also included after the matching lemmas.",
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "text/xml",
+                    "application/yaml",
+                    "application/msgpack",
+                    "application/cbor",
+                    "text/plain"
                 ],
                 "tags": [
                     "entry"
@@ -68,6 +79,20 @@ const docTemplate = `{
                         "name": "limit",
                         "in": "query",
                         "required": true
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "xml",
+                            "yaml",
+                            "msgpack",
+                            "cbor",
+                            "text"
+                        ],
+                        "type": "string",
+                        "description": "Response format (optional). Overrides the Accept header. curl and wget are served with colored text by default.",
+                        "name": "format",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -77,11 +102,23 @@ const docTemplate = `{
                             "$ref": "#/definitions/dictionary.SearchResponse"
                         }
                     },
+                    "406": {
+                        "description": "Not Acceptable",
+                        "schema": {
+                            "$ref": "#/definitions/httpres.Error"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/httpres.Error"
                         }
+                    },
+                    "default": {
+                        "description": "Sent instead of httpres.Error if the client accepts application/problem+json.",
+                        "schema": {
+                            "$ref": "#/definitions/httpres.Problem"
+                        }
                     }
                 }
             }
@@ -111,18 +148,29 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/httpres.Error"
                         }
+                    },
+                    "default": {
+                        "description": "Sent instead of httpres.Error if the client accepts application/problem+json.",
+                        "schema": {
+                            "$ref": "#/definitions/httpres.Problem"
+                        }
                     }
                 }
             }
         },
         "/api/v1/entry/{entry}": {
             "get": {
-                "description": "Show the information of provided lemma",
+                "description": "Show the information of provided lemma.\nIf the lemma is a variant of an entry (e.g. terselip ke luar), only the entry which owns the variant is shown along with the matched variant.\nIf the lemma is a non-standard form of another lemma (e.g. apotik), the standard form is shown in the standardForm field.\nIf the lemma is a reduplicated form (e.g. anak-anak, berlari-lari) which is not in the dictionary, the base lemma is shown along with the reduplication information.\nIf the lemma is not found, the error response contains a list of similar lemmas as suggestions.",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "text/xml",
+                    "application/yaml",
+                    "application/msgpack",
+                    "application/cbor",
+                    "text/plain"
                 ],
                 "tags": [
                     "entry"
@@ -142,13 +190,62 @@ const docTemplate = `{
                         "description": "Lemma's entry number (optional). Start from 1. Will be skipped if there's entry number in the lemma.",
                         "name": "entryNo",
                         "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "en",
+                            "id"
+                        ],
+                        "type": "string",
+                        "description": "Language of the error messages (optional). Overrides the Accept-Language header.",
+                        "name": "lang",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "annotate",
+                            "redirect",
+                            "strict"
+                        ],
+                        "type": "string",
+                        "description": "Behavior if the lemma is a non-standard form (bentuk tidak baku) of another lemma. ` + "`" + `annotate` + "`" + ` resolves to the standard form if the lemma is not found, ` + "`" + `redirect` + "`" + ` redirects to the standard form if the lemma is not found, ` + "`" + `strict` + "`" + ` never resolves to the standard form. Defaults to ` + "`" + `annotate` + "`" + `.",
+                        "name": "onNonStandard",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "xml",
+                            "yaml",
+                            "msgpack",
+                            "cbor",
+                            "text"
+                        ],
+                        "type": "string",
+                        "description": "Response format (optional). Overrides the Accept header. curl and wget are served with colored text by default.",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 500,
+                        "minimum": 20,
+                        "type": "integer",
+                        "description": "Maximum line width of the text format (optional). Defaults to 80.",
+                        "name": "width",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/kbbi.Lemma"
+                            "$ref": "#/definitions/dictionary.EntryResponse"
+                        }
+                    },
+                    "302": {
+                        "description": "Found",
+                        "schema": {
+                            "$ref": "#/definitions/dictionary.EntryResponse"
                         }
                     },
                     "400": {
@@ -163,6 +260,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/httpres.Error"
                         }
                     },
+                    "406": {
+                        "description": "Not Acceptable",
+                        "schema": {
+                            "$ref": "#/definitions/httpres.Error"
+                        }
+                    },
                     "414": {
                         "description": "Request URI Too Long",
                         "schema": {
@@ -174,12 +277,163 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/httpres.Error"
                         }
+                    },
+                    "default": {
+                        "description": "Sent instead of httpres.Error if the client accepts application/problem+json.",
+                        "schema": {
+                            "$ref": "#/definitions/httpres.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/text/_lemmatize": {
+            "post": {
+                "description": "Tokenize Indonesian text and resolve each token into its lemma.\nThe lemma is resolved by exact and normalized lookup, reduplication (e.g. anak-anak, sayur-mayur), affix stripping, and clitic splitting (e.g. bukunya).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/yaml",
+                    "application/msgpack",
+                    "application/cbor",
+                    "text/plain"
+                ],
+                "tags": [
+                    "text"
+                ],
+                "summary": "Lemmatize Text",
+                "parameters": [
+                    {
+                        "description": "Text to be lemmatized.",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/text.LemmatizeRequest"
+                        }
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "xml",
+                            "yaml",
+                            "msgpack",
+                            "cbor",
+                            "text"
+                        ],
+                        "type": "string",
+                        "description": "Response format (optional). Overrides the Accept header. curl and wget are served with colored text by default.",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/text.LemmatizeResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpres.Error"
+                        }
+                    },
+                    "406": {
+                        "description": "Not Acceptable",
+                        "schema": {
+                            "$ref": "#/definitions/httpres.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpres.Error"
+                        }
+                    },
+                    "default": {
+                        "description": "Sent instead of httpres.Error if the client accepts application/problem+json.",
+                        "schema": {
+                            "$ref": "#/definitions/httpres.Problem"
+                        }
                     }
                 }
             }
         }
     },
     "definitions": {
+        "dictionary.EntryResponse": {
+            "type": "object",
+            "properties": {
+                "entries": {
+                    "description": "Entries holds all entries information for this lemma.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/kbbi.Entry"
+                    }
+                },
+                "lemma": {
+                    "description": "Lemma is a single dictionary entry. E.g. ` + "`" + `apel` + "`" + `.",
+                    "type": "string"
+                },
+                "reduplication": {
+                    "description": "Reduplication is only present if the requested lemma is resolved from its reduplicated form.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/dictionary.Reduplication"
+                        }
+                    ]
+                },
+                "standardForm": {
+                    "description": "StandardForm is only present if the requested lemma is a non-standard form of another lemma.",
+                    "type": "string"
+                },
+                "variant": {
+                    "description": "Variant is only present if the requested lemma is resolved from one of its entry variants.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/dictionary.VariantMatch"
+                        }
+                    ]
+                }
+            }
+        },
+        "dictionary.Reduplication": {
+            "type": "object",
+            "properties": {
+                "baseWord": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "type": "string"
+                },
+                "suffix": {
+                    "type": "string"
+                },
+                "type": {
+                    "$ref": "#/definitions/dictionary.ReduplicationType"
+                }
+            }
+        },
+        "dictionary.ReduplicationType": {
+            "type": "string",
+            "enum": [
+                "full",
+                "partial",
+                "affixed",
+                "soundChange"
+            ],
+            "x-enum-varnames": [
+                "ReduplicationFull",
+                "ReduplicationPartial",
+                "ReduplicationAffixed",
+                "ReduplicationSoundChange"
+            ]
+        },
         "dictionary.SearchResponse": {
             "type": "object",
             "properties": {
@@ -191,11 +445,212 @@ const docTemplate = `{
                 }
             }
         },
+        "dictionary.VariantKind": {
+            "type": "string",
+            "enum": [
+                "entryVariant",
+                "wordVariant"
+            ],
+            "x-enum-varnames": [
+                "VariantKindEntry",
+                "VariantKindWord"
+            ]
+        },
+        "dictionary.VariantMatch": {
+            "type": "object",
+            "properties": {
+                "entry": {
+                    "description": "Entry is the entry which owns the variant. E.g. ` + "`" + `ter.se.lip` + "`" + `.",
+                    "type": "string"
+                },
+                "form": {
+                    "description": "Form is the variant as written in the dictionary. E.g. ` + "`" + `terselip ke luar` + "`" + `.",
+                    "type": "string"
+                },
+                "kind": {
+                    "description": "Kind is where the variant comes from.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/dictionary.VariantKind"
+                        }
+                    ]
+                }
+            }
+        },
+        "github_com_raf555_kbbi-api_internal_text.Token": {
+            "type": "object",
+            "properties": {
+                "clitics": {
+                    "description": "Clitics holds the clitics split from the token (if any). E.g. ` + "`" + `ku-` + "`" + `, ` + "`" + `-nya` + "`" + `.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "confidence": {
+                    "description": "Confidence is the confidence score of the resolved lemma, ranging from 0 to 1.",
+                    "type": "number"
+                },
+                "end": {
+                    "description": "End is the ending byte offset (exclusive) of the token in the original text.",
+                    "type": "integer"
+                },
+                "lemma": {
+                    "description": "Lemma is the resolved lemma of the token. Empty if the token can't be resolved.",
+                    "type": "string"
+                },
+                "method": {
+                    "description": "Method is the way the lemma is resolved.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/text.Method"
+                        }
+                    ]
+                },
+                "partsOfSpeech": {
+                    "description": "PartsOfSpeech holds the candidate word classes (` + "`" + `Kelas Kata` + "`" + `) of the lemma.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/kbbi.EntryLabel"
+                    }
+                },
+                "reduplication": {
+                    "description": "Reduplication describes the reduplication of the token (if any).",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/dictionary.Reduplication"
+                        }
+                    ]
+                },
+                "start": {
+                    "description": "Start is the starting byte offset of the token in the original text.",
+                    "type": "integer"
+                },
+                "text": {
+                    "description": "Text is the token as written in the original text.",
+                    "type": "string"
+                }
+            }
+        },
         "httpres.Error": {
             "type": "object",
             "properties": {
+                "code": {
+                    "description": "Code is a stable machine-readable code of the error. See [kbbi.ErrorCode] for the list of codes.",
+                    "type": "string",
+                    "enum": [
+                        "LEMMA_NOT_FOUND",
+                        "ENTRY_NOT_FOUND",
+                        "LEMMA_TOO_LONG",
+                        "EMPTY_LEMMA",
+                        "INVALID_ENTRY_NUMBER",
+                        "VALIDATION_FAILED",
+                        "INVALID_REQUEST",
+                        "UNAUTHORIZED",
+                        "RELOAD_IN_PROGRESS",
+                        "RELOAD_FAILED",
+                        "BAD_REQUEST",
+                        "NOT_FOUND",
+                        "METHOD_NOT_ALLOWED",
+                        "NOT_ACCEPTABLE",
+                        "REQUEST_URI_TOO_LONG",
+                        "INTERNAL_SERVER_ERROR"
+                    ],
+                    "example": "LEMMA_NOT_FOUND"
+                },
                 "message": {
                     "type": "string"
+                },
+                "suggestions": {
+                    "description": "Suggestions is only present on some errors, e.g. similar lemmas when a lemma is not found.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "httpres.FieldError": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string",
+                    "example": "OnNonStandard"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "OnNonStandard must be one of [redirect annotate strict]"
+                },
+                "rule": {
+                    "type": "string",
+                    "example": "oneof"
+                }
+            }
+        },
+        "httpres.Problem": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "Code is the same as [Error.Code].",
+                    "type": "string",
+                    "enum": [
+                        "LEMMA_NOT_FOUND",
+                        "ENTRY_NOT_FOUND",
+                        "LEMMA_TOO_LONG",
+                        "EMPTY_LEMMA",
+                        "INVALID_ENTRY_NUMBER",
+                        "VALIDATION_FAILED",
+                        "INVALID_REQUEST",
+                        "UNAUTHORIZED",
+                        "RELOAD_IN_PROGRESS",
+                        "RELOAD_FAILED",
+                        "BAD_REQUEST",
+                        "NOT_FOUND",
+                        "METHOD_NOT_ALLOWED",
+                        "NOT_ACCEPTABLE",
+                        "REQUEST_URI_TOO_LONG",
+                        "INTERNAL_SERVER_ERROR"
+                    ],
+                    "example": "LEMMA_NOT_FOUND"
+                },
+                "detail": {
+                    "type": "string",
+                    "example": "lemma not found"
+                },
+                "errors": {
+                    "description": "Errors is only present on validation errors, one for each invalid field.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/httpres.FieldError"
+                    }
+                },
+                "instance": {
+                    "type": "string",
+                    "example": "/api/v1/entry/apell"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 404
+                },
+                "suggestions": {
+                    "description": "Suggestions is the same as [Error.Suggestions].",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Not Found"
+                },
+                "traceId": {
+                    "description": "TraceID is the trace ID of the request if it is traced.",
+                    "type": "string",
+                    "example": "4bf92f3577b34da6a3ce929d0e0e4736"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "urn:kbbi-api:problem:lemma-not-found"
                 }
             }
         },
@@ -331,6 +786,50 @@ const docTemplate = `{
                     "type": "string"
                 }
             }
+        },
+        "text.LemmatizeRequest": {
+            "type": "object",
+            "required": [
+                "text"
+            ],
+            "properties": {
+                "text": {
+                    "type": "string",
+                    "maxLength": 10000
+                }
+            }
+        },
+        "text.LemmatizeResponse": {
+            "type": "object",
+            "properties": {
+                "tokens": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_raf555_kbbi-api_internal_text.Token"
+                    }
+                }
+            }
+        },
+        "text.Method": {
+            "type": "string",
+            "enum": [
+                "exact",
+                "normalized",
+                "variant",
+                "nonStandard",
+                "reduplication",
+                "affix",
+                "unknown"
+            ],
+            "x-enum-varnames": [
+                "MethodExact",
+                "MethodNormalized",
+                "MethodVariant",
+                "MethodNonStandard",
+                "MethodReduplication",
+                "MethodAffix",
+                "MethodUnknown"
+            ]
         }
     }
 }`
@@ -0,0 +1,52 @@
+package text
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"github.com/raf555/kbbi-api/internal/http/httphandler"
+)
+
+type HTTPHandler struct {
+	finderSnapshot LemmaFinderSnapshot
+}
+
+func NewHTTPHandler(finderSnapshot LemmaFinderSnapshot) *HTTPHandler {
+	return &HTTPHandler{
+		finderSnapshot: finderSnapshot,
+	}
+}
+
+func (h *HTTPHandler) MustRegisterRoutes(g *gin.Engine) {
+	textGroupV1 := g.Group("/api/v1/text")
+
+	textGroupV1.POST("/_lemmatize",
+		httphandler.MakeHandler(
+			h.Lemmatize,
+			httphandler.JSONRequestBinder,
+			httphandler.WithPureJSONSerializer(),
+		),
+	)
+}
+
+// Lemmatize godoc
+// @Summary      Lemmatize Text
+// @Description  Tokenize Indonesian text and resolve each token into its lemma.
+// @Description  The lemma is resolved by exact and normalized lookup, reduplication (e.g. anak-anak, sayur-mayur), affix stripping, and clitic splitting (e.g. bukunya).
+// @Tags         text
+// @Accept       json
+// @Produce      json,xml,application/yaml,application/msgpack,application/cbor,plain
+// @Param        request  body      LemmatizeRequest  true  "Text to be lemmatized."
+// @Param        format   query     string  false "Response format (optional). Overrides the Accept header. curl and wget are served with colored text by default." Enums(json, xml, yaml, msgpack, cbor, text)
+// @Success      200      {object}  LemmatizeResponse
+// @Failure      400      {object}  httpres.Error
+// @Failure      406      {object}  httpres.Error
+// @Failure      500      {object}  httpres.Error
+// @Failure      default  {object}  httpres.Problem "Sent instead of httpres.Error if the client accepts application/problem+json."
+// @Router       /api/v1/text/_lemmatize [post]
+func (h *HTTPHandler) Lemmatize(ctx context.Context, req *LemmatizeRequest) (*LemmatizeResponse, error) {
+	// the whole text is lemmatized with the same snapshot, even if the dictionary is reloaded in the middle of it.
+	return &LemmatizeResponse{
+		Tokens: NewLemmatizer(h.finderSnapshot()).Lemmatize(req.Text),
+	}, nil
+}
@@ -0,0 +1,10 @@
+package text
+
+import "github.com/raf555/kbbi-api/internal/dictionary"
+
+type LemmaFinder interface {
+	Lookup(lemma string, entryNo int) (dictionary.LookupResult, error)
+}
+
+// LemmaFinderSnapshot returns the LemmaFinder of the current dictionary snapshot.
+type LemmaFinderSnapshot func() LemmaFinder
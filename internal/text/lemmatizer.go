@@ -0,0 +1,174 @@
+package text
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/raf555/kbbi-api/internal/dictionary"
+	"github.com/raf555/kbbi-api/pkg/kbbi"
+	"github.com/samber/lo"
+)
+
+const labelKindWordClass = "Kelas Kata"
+
+// confidence of each resolution method.
+// the more the token is transformed, the less confident the result is.
+const (
+	confidenceExact         = 1.0
+	confidenceNormalized    = 0.95
+	confidenceVariant       = 0.9
+	confidenceNonStandard   = 0.9
+	confidenceReduplication = 0.85
+	confidenceAffix         = 0.6
+
+	// cliticPenalty is multiplied to the confidence if clitics are split from the token.
+	cliticPenalty = 0.9
+	// affixedReduplicationPenalty is multiplied to the confidence if the reduplication base word needs to be stripped.
+	affixedReduplicationPenalty = 0.8
+)
+
+type Lemmatizer struct {
+	dict LemmaFinder
+}
+
+func NewLemmatizer(dict LemmaFinder) *Lemmatizer {
+	return &Lemmatizer{
+		dict: dict,
+	}
+}
+
+type resolution struct {
+	lemma         kbbi.Lemma
+	method        Method
+	clitics       []string
+	reduplication *dictionary.Reduplication
+	confidence    float64
+}
+
+// Lemmatize tokenizes text and resolves each token into its lemma.
+//
+// The lemma is resolved in the following order:
+//  1. exact (or normalized) lookup, including entry variants, non-standard forms, and reduplication, e.g. anak-anak -> anak
+//  2. affix stripping, e.g. berlari -> lari
+//
+// If none of them is found, the token is split from its clitics (e.g. bukunya -> buku) and resolved again.
+func (l *Lemmatizer) Lemmatize(text string) []Token {
+	spans := tokenize(text)
+	tokens := make([]Token, 0, len(spans))
+
+	for _, span := range spans {
+		token := Token{
+			Text:          text[span.start:span.end],
+			Start:         span.start,
+			End:           span.end,
+			Method:        MethodUnknown,
+			Clitics:       []string{},
+			PartsOfSpeech: []kbbi.EntryLabel{},
+		}
+
+		if res, ok := l.resolve(token.Text); ok {
+			token.Lemma = res.lemma.Lemma
+			token.Method = res.method
+			token.Reduplication = res.reduplication
+			token.PartsOfSpeech = partsOfSpeech(res.lemma)
+			token.Confidence = res.confidence
+			if res.clitics != nil {
+				token.Clitics = res.clitics
+			}
+		}
+
+		tokens = append(tokens, token)
+	}
+
+	return tokens
+}
+
+func (l *Lemmatizer) resolve(word string) (resolution, bool) {
+	if res, ok := l.resolveWord(word); ok {
+		return res, true
+	}
+
+	for _, split := range dictionary.SplitClitics(word) {
+		if res, ok := l.resolveWord(split.Base); ok {
+			res.clitics = split.Clitics
+			res.confidence *= cliticPenalty
+			return res, true
+		}
+	}
+
+	return resolution{}, false
+}
+
+func (l *Lemmatizer) resolveWord(word string) (resolution, bool) {
+	if res, ok := l.find(word); ok {
+		switch res.Match {
+		case dictionary.MatchVariant:
+			return resolution{lemma: res.Lemma, method: MethodVariant, confidence: confidenceVariant}, true
+		case dictionary.MatchNonStandard:
+			return resolution{lemma: res.Lemma, method: MethodNonStandard, confidence: confidenceNonStandard}, true
+		case dictionary.MatchReduplication:
+			confidence := confidenceReduplication
+			if isAffixedBase(word, res.Reduplication.BaseWord) {
+				confidence *= affixedReduplicationPenalty
+			}
+			return resolution{lemma: res.Lemma, method: MethodReduplication, reduplication: res.Reduplication, confidence: confidence}, true
+		case dictionary.MatchNormalized:
+			return resolution{lemma: res.Lemma, method: MethodNormalized, confidence: confidenceNormalized}, true
+		default:
+			return resolution{lemma: res.Lemma, method: MethodExact, confidence: confidenceExact}, true
+		}
+	}
+
+	for _, stripped := range dictionary.StripAffixes(word) {
+		if res, ok := l.find(stripped); ok && (res.Match == dictionary.MatchExact || res.Match == dictionary.MatchNormalized) {
+			return resolution{lemma: res.Lemma, method: MethodAffix, confidence: confidenceAffix}, true
+		}
+	}
+
+	return resolution{}, false
+}
+
+// isAffixedBase reports whether base is found by stripping the affixes of the reduplication base word of word,
+// e.g. pepohonan -> pohonan -> pohon, rather than being the base word itself.
+func isAffixedBase(word, base string) bool {
+	redup, ok := dictionary.ParseReduplication(word)
+	if !ok {
+		return false
+	}
+
+	return !slices.ContainsFunc(redup.BaseCandidates(), func(candidate string) bool {
+		return strings.EqualFold(candidate, base)
+	})
+}
+
+// find looks up word in the dictionary, and retries with its lowercased form if not found.
+func (l *Lemmatizer) find(word string) (dictionary.LookupResult, bool) {
+	if res, err := l.dict.Lookup(word, 0); err == nil {
+		return res, true
+	}
+
+	if lowered := strings.ToLower(word); lowered != word {
+		if res, err := l.dict.Lookup(lowered, 0); err == nil {
+			return res, true
+		}
+	}
+
+	return dictionary.LookupResult{}, false
+}
+
+// partsOfSpeech returns unique word class labels of all definitions in lemma.
+func partsOfSpeech(lemma kbbi.Lemma) []kbbi.EntryLabel {
+	labels := []kbbi.EntryLabel{}
+
+	for _, entry := range lemma.Entries {
+		for _, def := range entry.Definitions {
+			for _, label := range def.Labels {
+				if label.Kind == labelKindWordClass {
+					labels = append(labels, label)
+				}
+			}
+		}
+	}
+
+	return lo.UniqBy(labels, func(label kbbi.EntryLabel) string { return label.Code })
+}
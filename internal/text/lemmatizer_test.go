@@ -0,0 +1,87 @@
+package text_test
+
+import (
+	"testing"
+
+	"github.com/raf555/kbbi-api/internal/dictionary"
+	"github.com/raf555/kbbi-api/internal/text"
+	"github.com/raf555/kbbi-api/pkg/kbbi"
+	"github.com/stretchr/testify/assert"
+)
+
+func newDictionary(lemmas map[string]string) *dictionary.Dictionary {
+	var data dictionary.AssetData
+	for lemma, wordClass := range lemmas {
+		data.Lemmas = append(data.Lemmas, kbbi.Lemma{
+			Lemma: lemma,
+			Entries: []kbbi.Entry{
+				{
+					Entry: lemma,
+					Definitions: []kbbi.EntryDefinition{
+						{Labels: []kbbi.EntryLabel{{Code: wordClass, Kind: "Kelas Kata"}, {Code: "cak", Kind: "Ragam"}}},
+					},
+				},
+			},
+		})
+	}
+	return dictionary.NewDictionaryFromAssetData(data, nil)
+}
+
+func TestLemmatizer_Lemmatize(t *testing.T) {
+	dict := newDictionary(map[string]string{
+		"anak":  "n",
+		"lari":  "v",
+		"buku":  "n",
+		"sayur": "n",
+		"baca":  "v",
+		"ini":   "pron",
+		"pohon": "n",
+	})
+
+	tokens := text.NewLemmatizer(dict).Lemmatize("Anak-anak berlari, bukunya... sayur-mayur kubaca ini xyz pepohonan")
+
+	type result struct {
+		text       string
+		start, end int
+		lemma      string
+		method     text.Method
+		clitics    []string
+		redupType  dictionary.ReduplicationType
+		wordClass  []string
+		confidence float64
+	}
+
+	expected := []result{
+		{text: "Anak-anak", start: 0, end: 9, lemma: "anak", method: text.MethodReduplication, clitics: []string{}, redupType: dictionary.ReduplicationFull, wordClass: []string{"n"}, confidence: 0.85},
+		{text: "berlari", start: 10, end: 17, lemma: "lari", method: text.MethodAffix, clitics: []string{}, wordClass: []string{"v"}, confidence: 0.6},
+		{text: "bukunya", start: 19, end: 26, lemma: "buku", method: text.MethodExact, clitics: []string{"-nya"}, wordClass: []string{"n"}, confidence: 0.9},
+		{text: "sayur-mayur", start: 30, end: 41, lemma: "sayur", method: text.MethodReduplication, clitics: []string{}, redupType: dictionary.ReduplicationSoundChange, wordClass: []string{"n"}, confidence: 0.85},
+		{text: "kubaca", start: 42, end: 48, lemma: "baca", method: text.MethodExact, clitics: []string{"ku-"}, wordClass: []string{"v"}, confidence: 0.9},
+		{text: "ini", start: 49, end: 52, lemma: "ini", method: text.MethodExact, clitics: []string{}, wordClass: []string{"pron"}, confidence: 1},
+		{text: "xyz", start: 53, end: 56, lemma: "", method: text.MethodUnknown, clitics: []string{}, wordClass: []string{}, confidence: 0},
+		{text: "pepohonan", start: 57, end: 66, lemma: "pohon", method: text.MethodReduplication, clitics: []string{}, redupType: dictionary.ReduplicationPartial, wordClass: []string{"n"}, confidence: 0.85 * 0.8},
+	}
+
+	actual := make([]result, 0, len(tokens))
+	for _, token := range tokens {
+		res := result{
+			text:       token.Text,
+			start:      token.Start,
+			end:        token.End,
+			lemma:      token.Lemma,
+			method:     token.Method,
+			clitics:    token.Clitics,
+			wordClass:  []string{},
+			confidence: token.Confidence,
+		}
+		if token.Reduplication != nil {
+			res.redupType = token.Reduplication.Type
+		}
+		for _, label := range token.PartsOfSpeech {
+			res.wordClass = append(res.wordClass, label.Code)
+		}
+		actual = append(actual, res)
+	}
+
+	assert.Equal(t, expected, actual)
+}
@@ -0,0 +1,71 @@
+package text
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/raf555/kbbi-api/internal/dictionary"
+	"github.com/raf555/kbbi-api/internal/plaintext"
+	"github.com/raf555/kbbi-api/pkg/kbbi"
+)
+
+type Method string
+
+const (
+	// MethodExact means the token is found as is in the dictionary.
+	MethodExact Method = "exact"
+	// MethodNormalized means the token is found after being normalized, e.g. diacritics removal.
+	MethodNormalized Method = "normalized"
+	// MethodVariant means the token is found as a variant of an entry, e.g. `udeh` of `ude`.
+	MethodVariant Method = "variant"
+	// MethodNonStandard means the token is found as a non-standard form of a lemma, e.g. `apotik` of `apotek`.
+	MethodNonStandard Method = "nonStandard"
+	// MethodReduplication means the token is resolved from its reduplicated form.
+	MethodReduplication Method = "reduplication"
+	// MethodAffix means the token is resolved after its affixes are stripped.
+	MethodAffix Method = "affix"
+	// MethodUnknown means the token can't be resolved into any lemma.
+	MethodUnknown Method = "unknown"
+)
+
+type Token struct {
+	// Text is the token as written in the original text.
+	Text string `json:"text" xml:"text"`
+	// Start is the starting byte offset of the token in the original text.
+	Start int `json:"start" xml:"start"`
+	// End is the ending byte offset (exclusive) of the token in the original text.
+	End int `json:"end" xml:"end"`
+
+	// Lemma is the resolved lemma of the token. Empty if the token can't be resolved.
+	Lemma string `json:"lemma" xml:"lemma"`
+	// Method is the way the lemma is resolved.
+	Method Method `json:"method" xml:"method"`
+	// Clitics holds the clitics split from the token (if any). E.g. `ku-`, `-nya`.
+	Clitics []string `json:"clitics" xml:"clitics>clitic"`
+	// Reduplication describes the reduplication of the token (if any).
+	Reduplication *dictionary.Reduplication `json:"reduplication" xml:"reduplication"`
+	// PartsOfSpeech holds the candidate word classes (`Kelas Kata`) of the lemma.
+	PartsOfSpeech []kbbi.EntryLabel `json:"partsOfSpeech" xml:"partsOfSpeech>label"`
+	// Confidence is the confidence score of the resolved lemma, ranging from 0 to 1.
+	Confidence float64 `json:"confidence" xml:"confidence"`
+}
+
+type LemmatizeRequest struct {
+	Text string `json:"text" validate:"required,max=10000"`
+}
+
+type LemmatizeResponse struct {
+	Tokens []Token `json:"tokens" xml:"tokens>token"`
+}
+
+func (r *LemmatizeResponse) RenderText(w io.Writer, opts plaintext.Options) error {
+	lines := make([]string, 0, len(r.Tokens))
+	for _, token := range r.Tokens {
+		lemma := token.Lemma
+		if lemma == "" {
+			lemma = "?"
+		}
+		lines = append(lines, fmt.Sprintf("%s → %s (%s)", token.Text, lemma, token.Method))
+	}
+	return plaintext.RenderList(w, lines, opts)
+}
@@ -0,0 +1,25 @@
+package textfx
+
+import (
+	"github.com/raf555/kbbi-api/internal/dictionary"
+	httpfx "github.com/raf555/kbbi-api/internal/http/fx"
+	"github.com/raf555/kbbi-api/internal/text"
+	"go.uber.org/fx"
+)
+
+var Module = fx.Module(
+	"text",
+
+	fx.Provide(
+		func(snapshots dictionary.SnapshotRepo) text.LemmaFinderSnapshot {
+			return func() text.LemmaFinder {
+				return snapshots.Current()
+			}
+		},
+		fx.Private,
+	),
+
+	httpfx.HandlerProvider(
+		text.NewHTTPHandler,
+	),
+)
@@ -0,0 +1,58 @@
+package text
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// span is a word position in text, in byte offsets. End is exclusive.
+type span struct {
+	start, end int
+}
+
+// tokenize splits text into words.
+//
+// A word is a sequence of letters and digits. Hyphens and apostrophes are kept
+// if they are surrounded by letters or digits, so reduplication (e.g. `anak-anak`)
+// and loanwords (e.g. `Jum'at`) are kept as a single word.
+func tokenize(text string) []span {
+	var (
+		spans []span
+		start = -1
+	)
+
+	for i, r := range text {
+		if isWordRune(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+
+		if start >= 0 && isWordJoiner(r) {
+			next, _ := utf8.DecodeRuneInString(text[i+utf8.RuneLen(r):])
+			if isWordRune(next) {
+				continue
+			}
+		}
+
+		if start >= 0 {
+			spans = append(spans, span{start, i})
+			start = -1
+		}
+	}
+
+	if start >= 0 {
+		spans = append(spans, span{start, len(text)})
+	}
+
+	return spans
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r)
+}
+
+func isWordJoiner(r rune) bool {
+	return r == '-' || r == '\'' || r == '’'
+}
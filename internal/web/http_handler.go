@@ -0,0 +1,90 @@
+package web
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/raf555/kbbi-api/internal/dictionary"
+	"github.com/raf555/kbbi-api/internal/logger"
+)
+
+// maxSuggestions is the maximum number of suggested lemmas shown when a lemma is not found.
+const maxSuggestions = 10
+
+type HTTPHandler struct {
+	finderSnapshot LemmaFinderSnapshot
+}
+
+func NewHTTPHandler(finderSnapshot LemmaFinderSnapshot) *HTTPHandler {
+	return &HTTPHandler{
+		finderSnapshot: finderSnapshot,
+	}
+}
+
+func (h *HTTPHandler) MustRegisterRoutes(g *gin.Engine) {
+	group := g.Group("/kata")
+
+	group.StaticFS("/_static", http.FS(staticFiles()))
+	group.GET("", h.Index)
+	group.GET("/:lemma", h.Entry)
+}
+
+// Index shows the search page, or redirects to the entry page if the `q` query is given.
+func (h *HTTPHandler) Index(ctx *gin.Context) {
+	if query := strings.TrimSpace(ctx.Query("q")); query != "" {
+		ctx.Redirect(http.StatusFound, lemmaURL(query))
+		return
+	}
+
+	render(ctx, http.StatusOK, "index", indexPage{})
+}
+
+// Entry shows the entry page of the lemma.
+func (h *HTTPHandler) Entry(ctx *gin.Context) {
+	lemma := ctx.Param("lemma")
+
+	if lowered := strings.ToLower(lemma); lowered != lemma {
+		ctx.Redirect(http.StatusMovedPermanently, "/kata/"+url.PathEscape(lowered))
+		return
+	}
+
+	entryNo := 0
+	if newLemma, no, ok := dictionary.FindEntryNoFromLemma(lemma); ok {
+		lemma, entryNo = newLemma, no
+	}
+
+	// the suggestions come from the same snapshot as the lookup.
+	finder := h.finderSnapshot()
+
+	result, err := finder.Lookup(lemma, entryNo)
+	switch {
+	case err == nil:
+		render(ctx, http.StatusOK, "entry", entryPage{LookupResult: result, Query: lemma})
+	case errors.Is(err, dictionary.ErrLemmaNotFound),
+		errors.Is(err, dictionary.ErrEntryNotFound),
+		errors.Is(err, dictionary.ErrLemmaTooLong),
+		errors.Is(err, dictionary.ErrUnexpectedEmptyLemma):
+		render(ctx, http.StatusNotFound, "notFound", notFoundPage{
+			Query:       lemma,
+			Suggestions: finder.Suggest(lemma, maxSuggestions),
+		})
+	default:
+		logger.FromContext(ctx).ErrorContext(ctx, "Failed to lookup lemma", logger.Error(err))
+		render(ctx, http.StatusInternalServerError, "error", errorPage{Message: "Gagal memuat kata."})
+	}
+}
+
+func render(ctx *gin.Context, code int, page string, data any) {
+	var buf bytes.Buffer
+	if err := pages[page].Execute(&buf, data); err != nil {
+		logger.FromContext(ctx).ErrorContext(ctx, "Failed to render page", logger.Error(err))
+		ctx.String(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+
+	ctx.Data(code, "text/html; charset=utf-8", buf.Bytes())
+}
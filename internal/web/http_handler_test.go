@@ -0,0 +1,110 @@
+package web_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/raf555/kbbi-api/internal/dictionary"
+	"github.com/raf555/kbbi-api/internal/web"
+	"github.com/raf555/kbbi-api/pkg/kbbi"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestRouter() *gin.Engine {
+	dict := dictionary.NewDictionaryFromAssetData(dictionary.AssetData{
+		Lemmas: []kbbi.Lemma{
+			{
+				Lemma: "apel",
+				Entries: []kbbi.Entry{
+					{
+						Entry: "a.pel (1)",
+						Definitions: []kbbi.EntryDefinition{
+							{
+								Definition:    "pohon yang buahnya berbentuk bulat",
+								Labels:        []kbbi.EntryLabel{{Code: "n", Name: "Nomina", Kind: "Kelas Kata"}},
+								UsageExamples: []string{"apel <merah>"},
+							},
+						},
+						CompoundWords: []string{"apel malang"},
+						Proverbs:      []string{"bagai apel dibelah dua"},
+					},
+				},
+			},
+			{
+				Lemma: "apotek",
+				Entries: []kbbi.Entry{
+					{
+						Entry:            "apo.tek",
+						NonStandardWords: []string{"apotik"},
+						Definitions:      []kbbi.EntryDefinition{{Definition: "toko obat"}},
+					},
+				},
+			},
+		},
+	}, nil)
+
+	gin.SetMode(gin.TestMode)
+	g := gin.New()
+	web.NewHTTPHandler(func() web.LemmaFinder { return dict }).MustRegisterRoutes(g)
+	return g
+}
+
+func TestHTTPHandler_Entry(t *testing.T) {
+	tcs := []struct {
+		path string
+
+		expectedCode     int
+		expectedContains []string
+		expectedLocation string
+	}{
+		{
+			path:         "/kata/apel",
+			expectedCode: http.StatusOK,
+			expectedContains: []string{
+				"<title>apel - KBBI</title>",
+				"a·pel<sup>1</sup>",
+				`<abbr class="label" title="Nomina (Kelas Kata)">n</abbr>`,
+				"apel &lt;merah&gt;",
+				`<a href="/kata/apel%20malang">apel malang</a>`,
+				"<li>bagai apel dibelah dua</li>",
+			},
+		},
+		{
+			path:             "/kata/apotik",
+			expectedCode:     http.StatusOK,
+			expectedContains: []string{`Bentuk tidak baku dari <a href="/kata/apotek">apotek</a>`},
+		},
+		{
+			path:             "/kata/apell",
+			expectedCode:     http.StatusNotFound,
+			expectedContains: []string{"Kata tidak ditemukan.", `<a href="/kata/apel">apel</a>`},
+		},
+		{
+			path:             "/kata/Apel",
+			expectedCode:     http.StatusMovedPermanently,
+			expectedLocation: "/kata/apel",
+		},
+		{
+			path:             "/kata?q=apel",
+			expectedCode:     http.StatusFound,
+			expectedLocation: "/kata/apel",
+		},
+	}
+
+	router := newTestRouter()
+
+	for _, tc := range tcs {
+		t.Run(tc.path, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tc.path, nil))
+
+			assert.Equal(t, tc.expectedCode, rec.Code)
+			assert.Equal(t, tc.expectedLocation, rec.Header().Get("Location"))
+			for _, expected := range tc.expectedContains {
+				assert.Contains(t, rec.Body.String(), expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,11 @@
+package web
+
+import "github.com/raf555/kbbi-api/internal/dictionary"
+
+type LemmaFinder interface {
+	Lookup(lemma string, entryNo int) (dictionary.LookupResult, error)
+	Suggest(lemma string, limit int) []string
+}
+
+// LemmaFinderSnapshot returns the LemmaFinder of the current dictionary snapshot.
+type LemmaFinderSnapshot func() LemmaFinder
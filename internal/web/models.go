@@ -0,0 +1,34 @@
+package web
+
+import "github.com/raf555/kbbi-api/internal/dictionary"
+
+type indexPage struct {
+	Query string
+}
+
+type entryPage struct {
+	dictionary.LookupResult
+
+	// Query is the lemma as requested by the client.
+	Query string
+}
+
+type notFoundPage struct {
+	Query       string
+	Suggestions []string
+}
+
+type errorPage struct {
+	Message string
+}
+
+type wordList struct {
+	Title string
+	Words []string
+	// Linked is true if each word has its own entry page.
+	Linked bool
+}
+
+func newWordList(title string, words []string, linked bool) wordList {
+	return wordList{Title: title, Words: words, Linked: linked}
+}
@@ -0,0 +1,81 @@
+package web
+
+import (
+	"embed"
+	"html/template"
+	"io/fs"
+	"net/url"
+	"strings"
+
+	"github.com/raf555/kbbi-api/internal/dictionary"
+	"github.com/raf555/kbbi-api/pkg/kbbi"
+)
+
+var (
+	//go:embed templates
+	templatesFS embed.FS
+
+	//go:embed static
+	staticFS embed.FS
+)
+
+// pages holds the parsed templates of each page, each of them is rendered within templates/layout.html.
+var pages = map[string]*template.Template{
+	"index":    mustParsePage("index.html"),
+	"entry":    mustParsePage("entry.html"),
+	"notFound": mustParsePage("not_found.html"),
+	"error":    mustParsePage("error.html"),
+}
+
+var templateFuncs = template.FuncMap{
+	"syllables": syllables,
+	"homonym":   homonym,
+	"lemmaURL":  lemmaURL,
+	"labelHint": labelHint,
+	"wordList":  newWordList,
+}
+
+func mustParsePage(name string) *template.Template {
+	return template.Must(
+		template.New("layout.html").Funcs(templateFuncs).ParseFS(templatesFS, "templates/layout.html", "templates/"+name),
+	)
+}
+
+func staticFiles() fs.FS {
+	static, err := fs.Sub(staticFS, "static")
+	if err != nil {
+		panic("web: " + err.Error())
+	}
+	return static
+}
+
+// syllables returns the syllabified entry without its homonym number. E.g. `a.pel (1)` becomes `a·pel`.
+func syllables(entry string) string {
+	if lemma, _, ok := dictionary.FindEntryNoFromLemma(entry); ok {
+		entry = lemma
+	}
+	return strings.ReplaceAll(entry, ".", "·")
+}
+
+// homonym returns the homonym number of the entry, or 0 if it has none. E.g. `a.pel (1)` returns 1.
+func homonym(entry string) int {
+	_, entryNo, _ := dictionary.FindEntryNoFromLemma(entry)
+	return entryNo
+}
+
+// lemmaURL returns the entry page URL of lemma.
+func lemmaURL(lemma string) string {
+	return "/kata/" + url.PathEscape(strings.ToLower(lemma))
+}
+
+// labelHint returns the tooltip of the label, e.g. `Nomina (Kelas Kata)`.
+func labelHint(label kbbi.EntryLabel) string {
+	switch {
+	case label.Name == "":
+		return label.Kind
+	case label.Kind == "":
+		return label.Name
+	default:
+		return label.Name + " (" + label.Kind + ")"
+	}
+}
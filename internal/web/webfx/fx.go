@@ -0,0 +1,25 @@
+package webfx
+
+import (
+	"github.com/raf555/kbbi-api/internal/dictionary"
+	httpfx "github.com/raf555/kbbi-api/internal/http/fx"
+	"github.com/raf555/kbbi-api/internal/web"
+	"go.uber.org/fx"
+)
+
+var Module = fx.Module(
+	"web",
+
+	fx.Provide(
+		func(snapshots dictionary.SnapshotRepo) web.LemmaFinderSnapshot {
+			return func() web.LemmaFinder {
+				return snapshots.Current()
+			}
+		},
+		fx.Private,
+	),
+
+	httpfx.HandlerProvider(
+		web.NewHTTPHandler,
+	),
+)
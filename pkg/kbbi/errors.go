@@ -0,0 +1,43 @@
+package kbbi
+
+// ErrorCode is a stable machine-readable code of the API error response.
+// Clients should match the error by its code instead of its message, since the message may change.
+type ErrorCode string
+
+const (
+	// ErrorCodeLemmaNotFound is returned when the requested lemma is not in the dictionary.
+	ErrorCodeLemmaNotFound ErrorCode = "LEMMA_NOT_FOUND"
+	// ErrorCodeEntryNotFound is returned when the requested entry number is not in the lemma.
+	ErrorCodeEntryNotFound ErrorCode = "ENTRY_NOT_FOUND"
+	// ErrorCodeLemmaTooLong is returned when the requested lemma is longer than any lemma in the dictionary.
+	ErrorCodeLemmaTooLong ErrorCode = "LEMMA_TOO_LONG"
+	// ErrorCodeEmptyLemma is returned when the requested lemma is empty.
+	ErrorCodeEmptyLemma ErrorCode = "EMPTY_LEMMA"
+	// ErrorCodeInvalidEntryNumber is returned when the requested entry number is invalid, e.g. negative.
+	ErrorCodeInvalidEntryNumber ErrorCode = "INVALID_ENTRY_NUMBER"
+	// ErrorCodeValidationFailed is returned when the request does not pass the validation, e.g. limit is too large.
+	ErrorCodeValidationFailed ErrorCode = "VALIDATION_FAILED"
+	// ErrorCodeInvalidRequest is returned when the request can't be parsed, e.g. malformed JSON body.
+	ErrorCodeInvalidRequest ErrorCode = "INVALID_REQUEST"
+	// ErrorCodeUnauthorized is returned when the admin request doesn't have the valid token.
+	ErrorCodeUnauthorized ErrorCode = "UNAUTHORIZED"
+	// ErrorCodeReloadInProgress is returned when the dictionary is requested to reload while it is already reloading.
+	ErrorCodeReloadInProgress ErrorCode = "RELOAD_IN_PROGRESS"
+	// ErrorCodeReloadFailed is returned when the dictionary fails to reload, the current dictionary is kept.
+	ErrorCodeReloadFailed ErrorCode = "RELOAD_FAILED"
+
+	// The codes below are derived from the HTTP status text of the errors without their own code.
+
+	// ErrorCodeBadRequest is returned when the request is invalid without a more specific code.
+	ErrorCodeBadRequest ErrorCode = "BAD_REQUEST"
+	// ErrorCodeNotFound is returned when the requested route does not exist.
+	ErrorCodeNotFound ErrorCode = "NOT_FOUND"
+	// ErrorCodeMethodNotAllowed is returned when the requested route does not support the HTTP method.
+	ErrorCodeMethodNotAllowed ErrorCode = "METHOD_NOT_ALLOWED"
+	// ErrorCodeNotAcceptable is returned when the client does not accept any of the supported response formats.
+	ErrorCodeNotAcceptable ErrorCode = "NOT_ACCEPTABLE"
+	// ErrorCodeRequestURITooLong is returned when the request URI is too long without a more specific code.
+	ErrorCodeRequestURITooLong ErrorCode = "REQUEST_URI_TOO_LONG"
+	// ErrorCodeInternalServerError is returned when something unexpected happens in the server.
+	ErrorCodeInternalServerError ErrorCode = "INTERNAL_SERVER_ERROR"
+)
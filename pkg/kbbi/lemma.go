@@ -8,32 +8,32 @@ type (
 	// E.g. lemma `apel` consists entries of `apel (1)`, `apel (2)`, etc.
 	Lemma struct {
 		// Lemma is a single dictionary entry. E.g. `apel`.
-		Lemma string `json:"lemma"`
+		Lemma string `json:"lemma" xml:"lemma" yaml:"lemma"`
 
 		// Entries holds all entries information for this lemma.
-		Entries []Entry `json:"entries"`
+		Entries []Entry `json:"entries" xml:"entries>entry" yaml:"entries"`
 	}
 
 	// Entry contains all informations related to the entry.
 	// All fields will always be a non-nil value.
 	Entry struct {
 		// Entry is the entry word. E.g. `apel (1)`.
-		Entry string `json:"entry"`
+		Entry string `json:"entry" xml:"entry" yaml:"entry"`
 
 		// BaseWord is the base word for a given entry (if any).
 		// I.e. `kata dasar`.
 		// E.g. `menyukai` has a base word of `suka`.
-		BaseWord string `json:"baseWord"`
+		BaseWord string `json:"baseWord" xml:"baseWord" yaml:"baseWord"`
 
 		// EntryVariants contains variants of how the entry can be referred (if any).
 		// E.g. `terselip` can be alternatively referred as `terselip ke luar`.
 		//
 		// It is possible that the variant does not have any entries in the dictionary.
-		EntryVariants []string `json:"entryVariants"`
+		EntryVariants []string `json:"entryVariants" xml:"entryVariants>variant" yaml:"entryVariants"`
 
 		// Pronunciation describes the way in which a word is prononunced (if any).
 		// E.g. `apel` can be prononunced as apêl.
-		Pronunciation string `json:"pronunciation"`
+		Pronunciation string `json:"pronunciation" xml:"pronunciation" yaml:"pronunciation"`
 
 		// Definitions contains the meaning of the entry.
 		// A single entry can have multiple meanings or definitions.
@@ -43,12 +43,12 @@ type (
 		//
 		// The definitions can be empty depending on the entry.
 		// If it is empty, usually it can be referred from the information of the other fields (e.g. BaseWord).
-		Definitions []EntryDefinition `json:"definitions"`
+		Definitions []EntryDefinition `json:"definitions" xml:"definitions>definition" yaml:"definitions"`
 
 		// NonStandardWords contains the non-standard forms of the entry (if any).
 		// I.e. `bentuk tidak baku`.
 		// E.g. `apotek` has a non-standard form of `apotik`.
-		NonStandardWords []string `json:"nonStandardWords"`
+		NonStandardWords []string `json:"nonStandardWords" xml:"nonStandardWords>word" yaml:"nonStandardWords"`
 
 		// WordVariants contains the alternative words of the entry (if any).
 		// I.e. `varian`.
@@ -56,33 +56,33 @@ type (
 		//
 		// The difference between WordVariants and `EntryVariants` is that
 		// WordVariants guaranteed to have at least 1 entry in the dictionary.
-		WordVariants []string `json:"variants"`
+		WordVariants []string `json:"variants" xml:"variants>variant" yaml:"variants"`
 
 		// CompoundWords contains the compound words of the entry (if any).
 		// I.e. `gabungan kata`.
 		// E.g. `kacang` has a compound word of `kacang atom`.
-		CompoundWords []string `json:"compoundWords"`
+		CompoundWords []string `json:"compoundWords" xml:"compoundWords>word" yaml:"compoundWords"`
 
 		// DerivedWords contains the derived words of the entry (if any).
 		// I.e. `kata turunan`.
 		// E.g. `suka` has a derived word of `menyukai`.
-		DerivedWords []string `json:"derivedWords"`
+		DerivedWords []string `json:"derivedWords" xml:"derivedWords>word" yaml:"derivedWords"`
 
 		// Proverbs contains the proverbs of the entry (if any).
 		// I.e. `peribahasa`.
 		// E.g. `kacang` is used in `kacang lupa akan kulitnya` proverb.
-		Proverbs []string `json:"proverbs"`
+		Proverbs []string `json:"proverbs" xml:"proverbs>proverb" yaml:"proverbs"`
 
 		// Metaphors contains metaphors of this entry (if any).
 		// I.e. `kiasan`.
 		// E.g. `leher` is used in `leher terasa panjang` metaphor.
-		Metaphors []string `json:"metaphors"`
+		Metaphors []string `json:"metaphors" xml:"metaphors>metaphor" yaml:"metaphors"`
 	}
 
 	// EntryDefinition contains the detail of the entry's definition.
 	EntryDefinition struct {
 		// Definition contains the meaning of the entry.
-		Definition string `json:"definition"`
+		Definition string `json:"definition" xml:"definition" yaml:"definition"`
 
 		// ReferencedLemma contains referenced lemma in the definition if present.
 		//
@@ -91,31 +91,31 @@ type (
 		//
 		// In other case, the entry is usually a non-standard form of the other lemma.
 		// Usually it has the definition of `bentuk tidak baku dari [lemma]`.
-		ReferencedLemma string `json:"referencedLemma"`
+		ReferencedLemma string `json:"referencedLemma" xml:"referencedLemma" yaml:"referencedLemma"`
 
 		// Labels contains the label of this definition if present.
 		// In the dictionary, they are usually placed at the front of the meaning.
 		// E.g. `su.ka a cak mudah sekali ...; kerap kali ...`
-		Labels []EntryLabel `json:"labels"`
+		Labels []EntryLabel `json:"labels" xml:"labels>label" yaml:"labels"`
 
 		// UsageExamples contains usage example of the entry for this meaning if any.
 		// In the dictionary, they are usually placed at the end of the meaning.
 		// E.g. `su.ka a cak mudah sekali ...; kerap kali ...: memang dia -- lupa; pensil semacam ini -- patah`
-		UsageExamples []string `json:"usageExamples"`
+		UsageExamples []string `json:"usageExamples" xml:"usageExamples>example" yaml:"usageExamples"`
 	}
 
 	// EntryLabel contains the label information of the entry for a definition.
 	EntryLabel struct {
 		// Code is the label short form.
 		// E.g. `n`, `Huk`, `cak`, etc.
-		Code string `json:"code"`
+		Code string `json:"code" xml:"code" yaml:"code"`
 
 		// Name is the label actual name.
 		// E.g. `nomina`, `Hukum`, `cakapan`, etc.
-		Name string `json:"name"`
+		Name string `json:"name" xml:"name" yaml:"name"`
 
 		// Kind is the label kind.
 		// E.g. `Kelas Kata`, `Bidang`, `Ragam`, etc.
-		Kind string `json:"kind"`
+		Kind string `json:"kind" xml:"kind" yaml:"kind"`
 	}
 )